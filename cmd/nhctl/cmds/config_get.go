@@ -80,9 +80,15 @@ func init() {
 		&commonFlags.AppConfig, "app-config", false,
 		"get application config",
 	)
+	configGetCmd.Flags().BoolVar(
+		&configGetResolved, "resolved", false,
+		"get config whose containers are merged with templates they extend",
+	)
 	configCmd.AddCommand(configGetCmd)
 }
 
+var configGetResolved bool
+
 type ConfigForPlugin struct {
 	Services []*profile.ServiceConfigV2 `json:"services" yaml:"services"`
 }
@@ -104,18 +110,27 @@ var configGetCmd = &cobra.Command{
 			log.Logf("init app:%s on namespace: %s, error: %v", commonFlags.AppName, common.NameSpace, err)
 			return
 		}
+		// values of secrets referred in config are masked
+		must(nocalhostApp.MaskConfigSecrets())
 		// get application config
 		if commonFlags.AppConfig {
 
 			applicationConfig := nocalhostApp.GetApplicationConfigV2()
+			if configGetResolved {
+				applicationConfig = applicationConfig.Resolved()
+			}
 			bys, err := yaml.Marshal(applicationConfig)
 			must(errors.Wrap(err, "fail to get application config"))
-			fmt.Println(string(bys))
+			// values of secrets referred in config are masked
+			fmt.Println(log.Mask(string(bys)))
 			return
 		}
 
 		if commonFlags.SvcName == "" {
 			appConfig := nocalhostApp.GetApplicationConfigV2()
+			if configGetResolved {
+				appConfig = appConfig.Resolved()
+			}
 			config := &ConfigForPlugin{}
 			config.Services = make([]*profile.ServiceConfigV2, 0)
 			for _, svcPro := range appConfig.ServiceConfigs {
@@ -123,7 +138,7 @@ var configGetCmd = &cobra.Command{
 			}
 			bys, err := yaml.Marshal(config)
 			must(errors.Wrap(err, "fail to get application config"))
-			fmt.Println(string(bys))
+			fmt.Println(log.Mask(string(bys)))
 
 		} else {
 			nocalhostSvc, err := nocalhostApp.InitAndCheckIfSvcExist(commonFlags.SvcName, common.ServiceType)
@@ -145,6 +160,8 @@ var configGetCmd = &cobra.Command{
 					Type:             nocalhostSvc.Type.String(),
 					ContainerConfigs: []*profile.ContainerConfig{},
 				}
+			} else if configGetResolved {
+				svcConfig = svcConfig.Resolved()
 			}
 
 			if svcProfile != nil {
@@ -192,7 +209,7 @@ var configGetCmd = &cobra.Command{
 
 				fmt.Println(
 					fmt.Sprintf(
-						"%s \n%s", notification, string(bys),
+						"%s \n%s", notification, log.Mask(string(bys)),
 					),
 				)
 			}
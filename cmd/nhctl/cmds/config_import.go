@@ -0,0 +1,67 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package cmds
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"nocalhost/internal/nhctl/app"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"path/filepath"
+)
+
+var importComposeOutput string
+
+func init() {
+	configImportComposeCmd.Flags().StringVarP(
+		&importComposeOutput, "output", "o", "", "file to save the config, print it if not specified",
+	)
+	configCmd.AddCommand(configImportComposeCmd)
+}
+
+var configImportComposeCmd = &cobra.Command{
+	Use:   "import-compose [FILE]",
+	Short: "Convert docker-compose.yml to service configs",
+	Long: `Convert services of docker-compose.yml to service configs, image, command, environment, env_file,
+ports, volumes and depends_on are converted. Named volumes become persistentVolumeDirs, and bind mounts
+of dirs in the project are synced to workDir. Services depend on pods labeled app=<service>`,
+	Example: `nhctl config import-compose docker-compose.yml -o .nocalhost/config.yaml`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		composeFile, err := filepath.Abs(args[0])
+		must(err)
+
+		configDir, err := os.Getwd()
+		must(err)
+		if importComposeOutput != "" {
+			output, err := filepath.Abs(importComposeOutput)
+			must(err)
+			configDir = filepath.Dir(output)
+		}
+
+		svcConfigs, err := app.ConvertDockerComposeFile(composeFile, configDir)
+		must(err)
+		bys, err := yaml.Marshal(svcConfigs)
+		must(err)
+
+		if importComposeOutput == "" {
+			fmt.Print(string(bys))
+			return
+		}
+		must(os.MkdirAll(configDir, 0755))
+		must(ioutil.WriteFile(importComposeOutput, bys, 0644))
+		log.Infof("%d services have been converted to %s", len(svcConfigs), importComposeOutput)
+	},
+}
@@ -0,0 +1,42 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package cmds
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"nocalhost/internal/nhctl/config_validate"
+	"nocalhost/internal/nhctl/profile"
+)
+
+var schemaOfService bool
+
+func init() {
+	configSchemaCmd.Flags().BoolVar(
+		&schemaOfService, "service", false, "print schema of service config instead of application config",
+	)
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print JSON Schema of config",
+	Long: `Print JSON Schema of config, editors can use it to autocomplete and check config files,
+rules depending on the cluster such as storageClass are not included`,
+	Example: `nhctl config schema > nocalhost-config.schema.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var schema map[string]interface{}
+		if schemaOfService {
+			schema = config_validate.GenerateSchema(profile.ServiceConfigV2{}, "Nocalhost service config")
+		} else {
+			schema = config_validate.GenerateSchema(profile.NocalHostAppConfigV2{}, "Nocalhost application config")
+		}
+		bys, err := json.MarshalIndent(schema, "", "  ")
+		must(err)
+		fmt.Println(string(bys))
+	},
+}
@@ -0,0 +1,49 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package cmds
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"nocalhost/internal/nhctl/app"
+	"nocalhost/internal/nhctl/coloredoutput"
+	"nocalhost/internal/nhctl/config_validate"
+	"os"
+)
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [FILE]",
+	Short: "Validate a config file offline",
+	Long: `Validate a config file offline, the file is rendered with its env file before validating,
+so line numbers refer to the rendered config if other files are included.
+Rules depending on the cluster such as storageClass are skipped`,
+	Example: `nhctl config validate .nocalhost/config.yaml`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := app.RenderConfigFile(args[0])
+		must(err)
+		fieldErrors, err := config_validate.ValidateConfigContent([]byte(content))
+		must(err)
+		if len(fieldErrors) == 0 {
+			coloredoutput.Success("%s is valid", args[0])
+			return
+		}
+		for _, fe := range fieldErrors {
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: %s\n", args[0], fe.Line, fe.Column, fe.Error())
+		}
+		os.Exit(1)
+	},
+}
@@ -14,6 +14,9 @@ func init() {
 	rootCmd.AddCommand(debugCmd)
 	debugCmd.AddCommand(dev.DevStartCmd)
 	debugCmd.AddCommand(dev.DevEndCmd)
+	debugCmd.AddCommand(dev.DevSessionCmd)
+	debugCmd.AddCommand(dev.DevInitCompleteCmd)
+	debugCmd.AddCommand(dev.DevEnvExportCmd)
 }
 
 var debugCmd = &cobra.Command{
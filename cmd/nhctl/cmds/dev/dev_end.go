@@ -11,11 +11,8 @@ import (
 	"github.com/spf13/cobra"
 	"nocalhost/cmd/nhctl/cmds/common"
 	"nocalhost/internal/nhctl/coloredoutput"
-	_const "nocalhost/internal/nhctl/const"
 	"nocalhost/internal/nhctl/controller"
 	"nocalhost/internal/nhctl/utils"
-	"nocalhost/pkg/nhctl/log"
-	"strconv"
 )
 
 func init() {
@@ -38,61 +35,27 @@ var DevEndCmd = &cobra.Command{
 		applicationName := args[0]
 		_, nocalhostSvc, err := common.InitAppAndCheckIfSvcExist(applicationName, common.WorkloadName, common.ServiceType)
 		must(err)
-		EndDevMode(nocalhostSvc)
+		must(EndDevMode(nocalhostSvc))
 	},
 }
 
 func EndDevMode(nocalhostSvc *controller.Controller) error {
-	if !nocalhostSvc.IsInReplaceDevMode() && !nocalhostSvc.IsInDuplicateDevMode() {
-		return errors.New(fmt.Sprintf("Service %s is not in DevMode", common.WorkloadName))
-	}
+	return endDevMode(nocalhostSvc, false)
+}
 
-	var needToRecoverHPA bool
-	if !nocalhostSvc.IsInDuplicateDevMode() {
-		needToRecoverHPA = true
+// endDevMode rolls back the workload from annotations, reset is used while rolling back a
+// DevMode not completely started, file sync may not be started yet
+func endDevMode(nocalhostSvc *controller.Controller, reset bool) error {
+	if !nocalhostSvc.IsInDevMode() {
+		return errors.New(fmt.Sprintf("Service %s is not in DevMode", nocalhostSvc.Name))
 	}
 
-	common.Must(nocalhostSvc.DevEnd(false))
+	// hpa suspended by replace DevMode is restored while rolling back
+	if err := nocalhostSvc.DevEnd(reset); err != nil {
+		return err
+	}
 	utils.Should(nocalhostSvc.DecreaseDevModeCount())
 
-	// Recover hpa
-	if needToRecoverHPA {
-		log.Info("Recovering HPA...")
-		hl, err := nocalhostSvc.ListHPA()
-		if err != nil {
-			log.WarnE(err, "Failed to find HPA")
-		}
-		if len(hl) == 0 {
-			log.Info("No HPA found")
-		}
-		for _, h := range hl {
-			if len(h.Annotations) == 0 {
-				continue
-			}
-			if max, ok := h.Annotations[_const.HPAOriginalMaxReplicasKey]; ok {
-				maxInt, err := strconv.ParseInt(max, 0, 0)
-				if err != nil {
-					log.WarnE(err, "")
-					continue
-				}
-				h.Spec.MaxReplicas = int32(maxInt)
-			}
-			if min, ok := h.Annotations[_const.HPAOriginalMinReplicasKey]; ok {
-				minInt, err := strconv.ParseInt(min, 0, 0)
-				if err != nil {
-					log.WarnE(err, "")
-					continue
-				}
-				minInt32 := int32(minInt)
-				h.Spec.MinReplicas = &minInt32
-			}
-			if _, err = nocalhostSvc.Client.UpdateHPA(&h); err != nil {
-				log.WarnE(err, fmt.Sprintf("Failed to update hpa %s", h.Name))
-			} else {
-				log.Infof("HPA %s has been recovered", h.Name)
-			}
-		}
-	}
-	coloredoutput.Success("DevMode has been ended")
+	coloredoutput.Success(fmt.Sprintf("DevMode of %s has been ended", nocalhostSvc.Name))
 	return nil
 }
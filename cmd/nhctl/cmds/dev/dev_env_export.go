@@ -0,0 +1,70 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package dev
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"nocalhost/cmd/nhctl/cmds/common"
+	"nocalhost/internal/nhctl/coloredoutput"
+	"nocalhost/internal/nhctl/controller"
+	"nocalhost/internal/nhctl/profile"
+	"path/filepath"
+)
+
+var envExportOps = &struct {
+	Container  string
+	Output     string
+	VolumesDir string
+	Force      bool
+}{}
+
+func init() {
+	DevEnvExportCmd.Flags().StringVarP(&common.WorkloadName, "deployment", "d", "", "k8s deployment which your developing service exists")
+	DevEnvExportCmd.Flags().StringVarP(&common.ServiceType, "controller-type", "t", "deployment",
+		"kind of k8s controller,such as deployment,statefulSet")
+	DevEnvExportCmd.Flags().StringVarP(&envExportOps.Container, "container", "c", "", "container to export env from")
+	DevEnvExportCmd.Flags().StringVarP(&envExportOps.Output, "output", "o", ".env", "dotenv file env is exported to")
+	DevEnvExportCmd.Flags().StringVar(&envExportOps.VolumesDir, "volumes-dir", "",
+		"directory configmaps and secrets mounted by the container are copied to, volumes are not exported if not specified")
+	DevEnvExportCmd.Flags().BoolVar(&envExportOps.Force, "force", false,
+		"export volumes into --volumes-dir even if it is not empty, files already in it are kept")
+}
+
+var DevEnvExportCmd = &cobra.Command{
+	Use:   "env-export [NAME]",
+	Short: "Export env of a container to a dotenv file",
+	Long: `Export env of a container to a dotenv file, env, valueFrom and envFrom are resolved as kubelet does,
+configmaps and secrets mounted by the container can be copied to a local directory as well`,
+	Example: `nhctl dev env-export [NAME] -d reviews -c reviews -o .env --volumes-dir ./mounts`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		_, nocalhostSvc, err := common.InitAppAndCheckIfSvcExist(args[0], common.WorkloadName, common.ServiceType)
+		must(err)
+
+		volumesDir := envExportOps.VolumesDir
+		if volumesDir != "" {
+			volumesDir, err = filepath.Abs(volumesDir)
+			must(errors.WithStack(err))
+		}
+		envs, mounts, err := nocalhostSvc.ExportContainerEnv(envExportOps.Container, volumesDir, envExportOps.Force)
+		must(err)
+		must(
+			controller.WriteEnvFile(
+				&profile.EnvFile{Path: envExportOps.Output},
+				fmt.Sprintf("# Exported by nhctl from %s %s", nocalhostSvc.Type, nocalhostSvc.Name), envs, mounts,
+			),
+		)
+		coloredoutput.Success("%d env of %s %s have been exported to %s",
+			len(envs), nocalhostSvc.Type, nocalhostSvc.Name, envExportOps.Output)
+	},
+}
@@ -0,0 +1,329 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package dev
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"nocalhost/cmd/nhctl/cmds/common"
+	"nocalhost/internal/nhctl/app"
+	"nocalhost/internal/nhctl/appmeta"
+	"nocalhost/internal/nhctl/coloredoutput"
+	"nocalhost/internal/nhctl/common/base"
+	"nocalhost/internal/nhctl/daemon_client"
+	"nocalhost/internal/nhctl/model"
+	"nocalhost/internal/nhctl/profile"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/pkg/nhctl/log"
+	"path/filepath"
+	"strings"
+)
+
+type DevSessionFlags struct {
+	Name         string
+	Workloads    []string
+	DevModeType  string
+	DevImage     string
+	StorageClass string
+	NoSyncthing  bool
+	Output       string
+}
+
+var devSessionFlags = DevSessionFlags{}
+
+func init() {
+	DevSessionStartCmd.Flags().StringVar(&devSessionFlags.Name, "name", "", "name of the dev session")
+	DevSessionStartCmd.Flags().StringArrayVarP(
+		&devSessionFlags.Workloads, "workload", "w", []string{},
+		"workload to develop, format: [TYPE/]NAME[:CONTAINER][=LOCAL_SYNC_DIR], such as: "+
+			"deployment/reviews:reviews=/home/user/reviews. TYPE defaults to deployment",
+	)
+	DevSessionStartCmd.Flags().StringVarP(
+		&devSessionFlags.DevModeType, "dev-mode", "m", "",
+		"specify which DevMode all the workloads enter, such as: replace,duplicate,ephemeral. Default: replace",
+	)
+	DevSessionStartCmd.Flags().StringVarP(
+		&devSessionFlags.DevImage, "image", "i", "",
+		"image of DevContainers",
+	)
+	DevSessionStartCmd.Flags().StringVar(&devSessionFlags.StorageClass, "storage-class", "", "StorageClass used by PV")
+	DevSessionStartCmd.Flags().BoolVar(
+		&devSessionFlags.NoSyncthing, "without-sync", false,
+		"do not start file-sync while dev session start success",
+	)
+
+	DevSessionEndCmd.Flags().StringVar(&devSessionFlags.Name, "name", "", "name of the dev session")
+
+	DevSessionGetCmd.Flags().StringVar(
+		&devSessionFlags.Name, "name", "", "name of the dev session, list all the sessions if not specified",
+	)
+	DevSessionGetCmd.Flags().StringVarP(&devSessionFlags.Output, "output", "o", "yaml", "json or yaml")
+
+	DevSessionCmd.AddCommand(DevSessionStartCmd)
+	DevSessionCmd.AddCommand(DevSessionEndCmd)
+	DevSessionCmd.AddCommand(DevSessionGetCmd)
+}
+
+var DevSessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Manage dev sessions",
+	Long:  `Manage dev sessions, workloads of a session enter and end DevMode as a unit`,
+}
+
+var DevSessionStartCmd = &cobra.Command{
+	Use:   "start [NAME]",
+	Short: "Start DevMode for multi workloads atomically",
+	Long: `Start DevMode for multi workloads atomically,
+if any of the workloads fails to enter DevMode, all the others will be rolled back`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+		}
+		if devSessionFlags.Name == "" {
+			return errors.New("--name must be specified")
+		}
+		if len(devSessionFlags.Workloads) == 0 {
+			return errors.New("at least one --workload(-w) must be specified")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		must(StartDevSession(args[0], &devSessionFlags))
+	},
+}
+
+var DevSessionEndCmd = &cobra.Command{
+	Use:   "end [NAME]",
+	Short: "End DevMode of all the workloads in a dev session",
+	Long:  `End DevMode of all the workloads in a dev session`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+		}
+		if devSessionFlags.Name == "" {
+			return errors.New("--name must be specified")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		must(EndDevSession(args[0], devSessionFlags.Name))
+	},
+}
+
+var DevSessionGetCmd = &cobra.Command{
+	Use:   "get [NAME]",
+	Short: "Get dev sessions from daemon",
+	Long:  `Get dev sessions from daemon`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		must(common.Prepare())
+		kubeConfigContent, err := ioutil.ReadFile(common.KubeConfig)
+		must(errors.Wrap(err, ""))
+
+		cli, err := daemon_client.GetDaemonClient(utils.IsSudoUser())
+		must(err)
+
+		data, err := cli.SendGetDevSessionCommand(
+			common.NameSpace, args[0], string(kubeConfigContent), devSessionFlags.Name,
+		)
+		must(err)
+
+		var bys []byte
+		switch devSessionFlags.Output {
+		case "json":
+			bys, err = json.Marshal(data)
+		case "yaml":
+			bys, err = yaml.Marshal(data)
+		default:
+			err = errors.New(fmt.Sprintf("Unsupported output format %s", devSessionFlags.Output))
+		}
+		must(err)
+		fmt.Println(string(bys))
+	},
+}
+
+// parseSessionWorkload parses workload with format [TYPE/]NAME[:CONTAINER][=LOCAL_SYNC_DIR]
+func parseSessionWorkload(workload string) (*appmeta.DevSessionWorkload, string, error) {
+	s := workload
+	var localSyncDir string
+	if i := strings.Index(s, "="); i >= 0 {
+		s, localSyncDir = s[:i], s[i+1:]
+	}
+
+	w := &appmeta.DevSessionWorkload{Type: base.Deployment}
+	if i := strings.Index(s, ":"); i >= 0 {
+		s, w.Container = s[:i], s[i+1:]
+	}
+	if i := strings.Index(s, "/"); i >= 0 {
+		s, w.Type = s[i+1:], base.SvcType(strings.ToLower(s[:i]))
+	}
+	w.Name = s
+
+	if w.Name == "" || w.Type == "" {
+		return nil, "", errors.New(fmt.Sprintf("Invalid workload %s", workload))
+	}
+	return w, localSyncDir, nil
+}
+
+// StartDevSession enters DevMode for all the workloads one by one,
+// workloads already entered DevMode will be rolled back if any of them fails
+func StartDevSession(applicationName string, flags *DevSessionFlags) error {
+	dt := profile.DevModeType(flags.DevModeType)
+	if !dt.IsDuplicateDevMode() && !dt.IsReplaceDevMode() && !dt.IsEphemeralDevMode() {
+		return errors.New(fmt.Sprintf("Unsupported DevModeType %s", dt))
+	}
+
+	nocalhostApp, err := common.InitApp(applicationName)
+	if err != nil {
+		return err
+	}
+	if !nocalhostApp.GetAppMeta().IsInstalled() {
+		return errors.New(nocalhostApp.GetAppMeta().NotInstallTips())
+	}
+
+	session := &appmeta.DevSession{
+		Name:        flags.Name,
+		Identifier:  nocalhostApp.Identifier,
+		DevModeType: dt,
+		Workloads:   make([]*appmeta.DevSessionWorkload, 0),
+	}
+	opsList, err := buildDevSessionOps(nocalhostApp, session, flags)
+	if err != nil {
+		return err
+	}
+
+	appMeta := nocalhostApp.GetAppMeta()
+	if err = appMeta.DevSessionStarting(session); err != nil {
+		return err
+	}
+
+	coloredoutput.Hint(fmt.Sprintf("Starting dev session %s...", session.Name))
+	for i, d := range opsList {
+		coloredoutput.Hint(fmt.Sprintf("Starting %s DevMode of %s...", dt.ToString(), session.Workloads[i]))
+		d.NocalhostSvc.DevModeType = dt
+		if err = d.prepareAndEnterDevMode(dt); err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to start DevMode of %s", session.Workloads[i]))
+			log.Infof("Rolling back dev session %s...", session.Name)
+			for _, entered := range opsList[:i] {
+				utils.Should(endDevMode(entered.NocalhostSvc, true))
+			}
+			utils.Should(appMeta.DevSessionEnd(session.Name))
+			return err
+		}
+	}
+
+	if err = appMeta.DevSessionStartComplete(session.Name); err != nil {
+		return err
+	}
+
+	for _, d := range opsList {
+		devPodName, err := d.NocalhostSvc.GetDevModePodName()
+		if err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to get dev pod of %s", d.NocalhostSvc.Name))
+			continue
+		}
+		d.startPortForwardAfterDevStart(devPodName)
+		if !d.NoSyncthing {
+			d.startSyncthing(devPodName, false)
+		}
+	}
+	coloredoutput.Success(fmt.Sprintf("Dev session %s has been started", session.Name))
+	return nil
+}
+
+func buildDevSessionOps(nocalhostApp *app.Application, session *appmeta.DevSession, flags *DevSessionFlags) (
+	[]*DevStartOps, error) {
+
+	opsList := make([]*DevStartOps, 0)
+	for _, s := range flags.Workloads {
+		w, localSyncDir, err := parseSessionWorkload(s)
+		if err != nil {
+			return nil, err
+		}
+
+		nocalhostSvc, err := nocalhostApp.InitAndCheckIfSvcExist(w.Name, string(w.Type))
+		if err != nil {
+			return nil, err
+		}
+		if nocalhostSvc.IsInDevMode() {
+			return nil, errors.New(fmt.Sprintf("%s is already in DevMode", w))
+		}
+
+		ops := &model.DevStartOptions{
+			DevModeType:  string(session.DevModeType),
+			DevImage:     flags.DevImage,
+			Container:    w.Container,
+			StorageClass: flags.StorageClass,
+			NoSyncthing:  flags.NoSyncthing,
+			NoTerminal:   true,
+			LocalSyncDir: []string{},
+		}
+		if localSyncDir != "" {
+			abs, err := filepath.Abs(localSyncDir)
+			if err != nil {
+				return nil, errors.Wrap(err, "")
+			}
+			ops.LocalSyncDir = append(ops.LocalSyncDir, abs)
+		}
+
+		session.Workloads = append(session.Workloads, w)
+		opsList = append(
+			opsList, &DevStartOps{DevStartOptions: ops, NocalhostSvc: nocalhostSvc, NocalhostApp: nocalhostApp},
+		)
+	}
+	return opsList, nil
+}
+
+// EndDevSession ends DevMode of all the workloads in the session as `nhctl dev end` does, the session
+// is removed even if some of them fail, so they can be ended by `nhctl dev end` separately
+func EndDevSession(applicationName, sessionName string) error {
+	nocalhostApp, err := common.InitApp(applicationName)
+	if err != nil {
+		return err
+	}
+
+	appMeta := nocalhostApp.GetAppMeta()
+	session := appMeta.GetDevSession(sessionName)
+	if session == nil {
+		return errors.New(fmt.Sprintf("Dev session %s not found", sessionName))
+	}
+
+	// workloads are removed from the session while their DevMode is ended
+	workloads := make([]*appmeta.DevSessionWorkload, len(session.Workloads))
+	copy(workloads, session.Workloads)
+
+	failed := make([]string, 0)
+	for _, w := range workloads {
+		nocalhostSvc, err := nocalhostApp.InitAndCheckIfSvcExist(w.Name, string(w.Type))
+		if err == nil {
+			err = EndDevMode(nocalhostSvc)
+		}
+		if err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to end DevMode of %s", w))
+			failed = append(failed, w.String())
+		}
+	}
+
+	if err = appMeta.DevSessionEnd(sessionName); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return errors.New(
+			fmt.Sprintf("Failed to end DevMode of %s in dev session %s", strings.Join(failed, ","), sessionName),
+		)
+	}
+	coloredoutput.Success(fmt.Sprintf("Dev session %s has been ended", sessionName))
+	return nil
+}
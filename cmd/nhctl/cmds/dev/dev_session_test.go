@@ -0,0 +1,41 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package dev
+
+import (
+	"nocalhost/internal/nhctl/common/base"
+	"testing"
+)
+
+func TestParseSessionWorkload(t *testing.T) {
+	cases := []struct {
+		workload     string
+		name         string
+		svcType      base.SvcType
+		container    string
+		localSyncDir string
+	}{
+		{"reviews", "reviews", base.Deployment, "", ""},
+		{"statefulset/db:mysql", "db", base.StatefulSet, "mysql", ""},
+		{"deployment/reviews:reviews=/tmp/reviews", "reviews", base.Deployment, "reviews", "/tmp/reviews"},
+		{"ratings=./ratings", "ratings", base.Deployment, "", "./ratings"},
+	}
+	for _, c := range cases {
+		w, dir, err := parseSessionWorkload(c.workload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.Name != c.name || w.Type != c.svcType || w.Container != c.container || dir != c.localSyncDir {
+			t.Fatalf("unexpected result of %s: %v %s", c.workload, w, dir)
+		}
+	}
+
+	for _, s := range []string{"deployment/", ":reviews", "/reviews"} {
+		if _, _, err := parseSessionWorkload(s); err == nil {
+			t.Fatalf("%s should be invalid", s)
+		}
+	}
+}
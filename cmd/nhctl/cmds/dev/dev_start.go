@@ -10,12 +10,11 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"nocalhost/cmd/nhctl/cmds/common"
 	"nocalhost/internal/nhctl/app"
 	"nocalhost/internal/nhctl/coloredoutput"
-	"nocalhost/internal/nhctl/common/base"
-	_const "nocalhost/internal/nhctl/const"
 	"nocalhost/internal/nhctl/controller"
 	"nocalhost/internal/nhctl/dev_dir"
 	"nocalhost/internal/nhctl/model"
@@ -24,9 +23,10 @@ import (
 	"nocalhost/internal/nhctl/profile"
 	"nocalhost/internal/nhctl/syncthing"
 	"nocalhost/internal/nhctl/utils"
+	vpnutil "nocalhost/internal/nhctl/vpn/util"
 	"nocalhost/pkg/nhctl/log"
 	utils2 "nocalhost/pkg/nhctl/utils"
-	"strconv"
+	"sigs.k8s.io/yaml"
 	"strings"
 )
 
@@ -39,10 +39,15 @@ type DevStartOps struct {
 	*model.DevStartOptions
 	NocalhostSvc *controller.Controller
 	NocalhostApp *app.Application
+
+	pfListBeforeDevStart []*profile.DevPortForward
 }
 
 var devStartOps = &model.DevStartOptions{}
 
+// overlay in configProperties.overlays merged over application config while reloading config
+var devStartOverlay string
+
 func init() {
 
 	DevStartCmd.Flags().StringVarP(
@@ -90,12 +95,35 @@ func init() {
 	)
 	DevStartCmd.Flags().StringVarP(
 		&devStartOps.DevModeType, "dev-mode", "m", "",
-		"specify which DevMode you want to enter, such as: replace,duplicate. Default: replace",
+		"specify which DevMode you want to enter, such as: replace,duplicate,ephemeral,local. Default: replace",
+	)
+	// --mode is an alias of --dev-mode
+	DevStartCmd.Flags().SetNormalizeFunc(
+		func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+			if name == "mode" {
+				name = "dev-mode"
+			}
+			return pflag.NormalizedName(name)
+		},
 	)
 	DevStartCmd.Flags().StringToStringVar(
 		&devStartOps.MeshHeader, "header", map[string]string{},
 		"mesh header while use duplicate devMode, traffic which have those headers will route to current workload",
 	)
+	DevStartCmd.Flags().BoolVar(
+		&devStartOps.DryRun, "dry-run", false,
+		"show what DevMode would apply to the workload as a diff against the live object, without changing the cluster",
+	)
+	DevStartCmd.Flags().StringVar(
+		&devStartOverlay, "overlay", "",
+		"overlay defined in configProperties.overlays of config to merge over application config, "+
+			"which is kept while config of the svc is reloaded",
+	)
+	DevStartCmd.Flags().DurationVar(
+		&devStartOps.TTL, "ttl", 0,
+		"end replace DevMode in cluster if neither file sync nor terminal is active for the duration, "+
+			"such as 8h. Default: ttl of the dev space",
+	)
 }
 
 var DevStartCmd = &cobra.Command{
@@ -117,14 +145,21 @@ var DevStartCmd = &cobra.Command{
 func (d *DevStartOps) StartDevMode(applicationName string) error {
 
 	dt := profile.DevModeType(d.DevModeType)
-	if !dt.IsDuplicateDevMode() && !dt.IsReplaceDevMode() {
+	if !dt.IsDuplicateDevMode() && !dt.IsReplaceDevMode() && !dt.IsEphemeralDevMode() && !dt.IsLocalDevMode() {
 		return errors.New(fmt.Sprintf("Unsupported DevModeType %s", dt))
 	}
 
-	if len(d.LocalSyncDir) > 1 {
-		log.Fatal("Can not define multi 'local-sync(-s)'")
-	} else if len(d.LocalSyncDir) == 0 {
-		log.Fatal("'local-sync(-s)' must be specified")
+	// traffic is reversed to local by sudo daemon
+	if dt.IsLocalDevMode() && !d.DryRun && !vpnutil.IsAdmin() && !vpnutil.IsSudoDaemonServing() {
+		return errors.New("Local DevMode needs sudo daemon to reverse traffic, please run `nhctl vpn elevate` first")
+	}
+
+	if !d.DryRun {
+		if len(d.LocalSyncDir) > 1 {
+			log.Fatal("Can not define multi 'local-sync(-s)'")
+		} else if len(d.LocalSyncDir) == 0 {
+			log.Fatal("'local-sync(-s)' must be specified")
+		}
 	}
 
 	nocalhostApp, nocalhostSvc, err := common.InitAppAndCheckIfSvcExist(applicationName, common.WorkloadName, common.ServiceType)
@@ -138,9 +173,18 @@ func (d *DevStartOps) StartDevMode(applicationName string) error {
 		log.Fatal(nocalhostApp.GetAppMeta().NotInstallTips())
 	}
 
+	if d.DryRun {
+		return d.dryRunDevMode(dt)
+	}
+
 	if d.NocalhostSvc.IsInDevMode() {
 		coloredoutput.Hint(fmt.Sprintf("Already in %s DevMode...", d.NocalhostSvc.DevModeType.ToString()))
 
+		// local DevMode has no DevContainer, traffic is still reversed to local by vpn
+		if d.NocalhostSvc.DevModeType.IsLocalDevMode() {
+			return nil
+		}
+
 		podName, err := d.NocalhostSvc.GetDevModePodName()
 		must(err)
 
@@ -181,15 +225,17 @@ func (d *DevStartOps) StartDevMode(applicationName string) error {
 	coloredoutput.Hint(fmt.Sprintf("Starting %s DevMode...", dt.ToString()))
 
 	d.NocalhostSvc.DevModeType = dt
-	d.loadLocalOrCmConfigIfValid()
-	d.stopPreviousSyncthing()
-	d.recordLocalSyncDirToProfile()
-	d.prepareSyncThing()
-	d.stopPreviousPortForward()
-	if err := d.enterDevMode(dt); err != nil {
+	if err := d.prepareAndEnterDevMode(dt); err != nil {
 		log.FatalE(err, "")
 	}
 
+	if dt.IsLocalDevMode() {
+		coloredoutput.Success(
+			fmt.Sprintf("Traffic of %s has been reversed to local, please start your service locally", d.NocalhostSvc.Name),
+		)
+		return nil
+	}
+
 	devPodName, err := d.NocalhostSvc.GetDevModePodName()
 	must(err)
 
@@ -207,36 +253,112 @@ func (d *DevStartOps) StartDevMode(applicationName string) error {
 	return nil
 }
 
-var pfListBeforeDevStart []*profile.DevPortForward
+// dryRunDevMode prints what entering DevMode would apply to the cluster as unified YAML diffs
+func (d *DevStartOps) dryRunDevMode(dt profile.DevModeType) error {
+	if d.NocalhostSvc.IsInDevMode() {
+		return errors.New(
+			fmt.Sprintf("%s is already in %s DevMode", d.NocalhostSvc.Name, d.NocalhostSvc.DevModeType.ToString()),
+		)
+	}
+
+	d.NocalhostSvc.DevModeType = dt
+	result, err := d.NocalhostSvc.DryRunDevModeManifest(d.DevStartOptions, d.TTL)
+	if err != nil {
+		return err
+	}
+
+	// managedFields are maintained by api server, they are noise to the diff
+	result.Live.SetManagedFields(nil)
+	result.DevMode.SetManagedFields(nil)
+
+	name := fmt.Sprintf("%s/%s", strings.ToLower(result.Live.GetKind()), result.Live.GetName())
+	diffs := []string{yamlDiff(name+" (live)", name+" (dev)", result.Live.Object, result.DevMode.Object)}
+	if result.Generated != nil {
+		diffs = append(
+			diffs, yamlDiff(
+				"/dev/null", fmt.Sprintf("deployment/%s (generated)", result.Generated.GetName()),
+				nil, result.Generated.Object,
+			),
+		)
+	}
+	for _, pvc := range result.Pvcs {
+		diffs = append(diffs, yamlDiff("/dev/null", fmt.Sprintf("persistentvolumeclaim/%s", pvc.Name), nil, pvc))
+	}
+
+	for _, diff := range diffs {
+		fmt.Print(diff)
+	}
+	return nil
+}
+
+func yamlDiff(fromName, toName string, from, to interface{}) string {
+	var fromYaml, toYaml []byte
+	if from != nil {
+		fromYaml, _ = yaml.Marshal(from)
+	}
+	if to != nil {
+		toYaml, _ = yaml.Marshal(to)
+	}
+	return utils.UnifiedDiff(fromName, toName, string(fromYaml), string(toYaml))
+}
+
+// prepareAndEnterDevMode do all the things before DevContainer is ready,
+// file sync, port-forward and terminal are not included
+func (d *DevStartOps) prepareAndEnterDevMode(dt profile.DevModeType) error {
+	if err := d.loadLocalOrCmConfigIfValid(); err != nil {
+		return err
+	}
+	if err := d.stopPreviousSyncthing(); err != nil {
+		return err
+	}
+	if err := d.recordLocalSyncDirToProfile(); err != nil {
+		return err
+	}
+	// local DevMode runs the service in the local directory, nothing needs to be synced
+	if !dt.IsLocalDevMode() {
+		if err := d.prepareSyncThing(); err != nil {
+			return err
+		}
+	}
+	d.stopPreviousPortForward()
+	return d.enterDevMode(dt)
+}
 
 func (d *DevStartOps) stopPreviousPortForward() {
 	appProfile, _ := d.NocalhostApp.GetProfile()
-	pfListBeforeDevStart = appProfile.SvcProfileV2(common.WorkloadName, string(d.NocalhostSvc.Type)).DevPortForwardList
-	for _, pf := range pfListBeforeDevStart {
+	d.pfListBeforeDevStart = appProfile.SvcProfileV2(d.NocalhostSvc.Name, string(d.NocalhostSvc.Type)).DevPortForwardList
+	for _, pf := range d.pfListBeforeDevStart {
 		log.Infof("Stopping %d:%d", pf.LocalPort, pf.RemotePort)
 		utils.Should(d.NocalhostSvc.EndDevPortForward(pf.LocalPort, pf.RemotePort))
 	}
 }
 
-func (d *DevStartOps) prepareSyncThing() {
+func (d *DevStartOps) prepareSyncThing() error {
 	dt := profile.DevModeType(d.DevModeType)
-	must(d.NocalhostSvc.CreateSyncThingSecret(d.Container, d.LocalSyncDir, dt.IsDuplicateDevMode()))
+	return d.NocalhostSvc.CreateSyncThingSecret(d.Container, d.LocalSyncDir, dt.IsDuplicateDevMode())
 }
 
-func (d *DevStartOps) recordLocalSyncDirToProfile() {
-	must(
-		d.NocalhostSvc.UpdateSvcProfile(
-			func(svcProfile *profile.SvcProfileV2) error {
-				svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin = d.LocalSyncDir
-				return nil
-			},
-		),
+func (d *DevStartOps) recordLocalSyncDirToProfile() error {
+	return d.NocalhostSvc.UpdateSvcProfile(
+		func(svcProfile *profile.SvcProfileV2) error {
+			svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin = d.LocalSyncDir
+			return nil
+		},
 	)
 }
 
 // when re enter dev mode, nocalhost will check the associate dir
 // nocalhost will load svc config from associate dir if needed
-func (d *DevStartOps) loadLocalOrCmConfigIfValid() {
+func (d *DevStartOps) loadLocalOrCmConfigIfValid() error {
+	// the overlay is recorded, so that config reloaded later is merged with it too
+	if err := d.NocalhostSvc.UpdateSvcProfile(
+		func(svcProfile *profile.SvcProfileV2) error {
+			svcProfile.ConfigOverlay = devStartOverlay
+			return nil
+		},
+	); err != nil {
+		return err
+	}
 
 	svcPack := dev_dir.NewSvcPack(
 		d.NocalhostSvc.NameSpace,
@@ -251,37 +373,43 @@ func (d *DevStartOps) loadLocalOrCmConfigIfValid() {
 	case 0:
 		associatePath := svcPack.GetAssociatePath()
 		if associatePath == "" {
-			must(errors.New("'local-sync(-s)' should specify while svc is not associate with local dir"))
+			return errors.New("'local-sync(-s)' should specify while svc is not associate with local dir")
 		}
 		d.LocalSyncDir = append(d.LocalSyncDir, string(associatePath))
 
-		must(associatePath.Associate(svcPack, common.KubeConfig, true))
+		if err := associatePath.Associate(svcPack, common.KubeConfig, true); err != nil {
+			return err
+		}
 
-		_ = d.NocalhostApp.ReloadSvcCfg(common.WorkloadName, base.SvcType(common.ServiceType), false, false)
+		_ = d.NocalhostApp.ReloadSvcCfg(d.NocalhostSvc.Name, d.NocalhostSvc.Type, false, false)
 	case 1:
 
-		must(dev_dir.DevPath(d.LocalSyncDir[0]).Associate(svcPack, common.KubeConfig, true))
+		if err := dev_dir.DevPath(d.LocalSyncDir[0]).Associate(svcPack, common.KubeConfig, true); err != nil {
+			return err
+		}
 
-		_ = d.NocalhostApp.ReloadSvcCfg(common.WorkloadName, base.SvcType(common.ServiceType), false, false)
+		_ = d.NocalhostApp.ReloadSvcCfg(d.NocalhostSvc.Name, d.NocalhostSvc.Type, false, false)
 	default:
-		log.Fatal(errors.New("Can not define multi 'local-sync(-s)'"))
+		return errors.New("Can not define multi 'local-sync(-s)'")
 	}
+	return nil
 }
 
 // we should clean previous Syncthing
 // prevent previous syncthing hold the db lock
-func (d *DevStartOps) stopPreviousSyncthing() {
+func (d *DevStartOps) stopPreviousSyncthing() error {
 	// Clean up previous syncthing
-	must(
-		d.NocalhostSvc.FindOutSyncthingProcess(
-			func(pid int) error {
-				return syncthing.Stop(pid, true)
-			},
-		),
-	)
+	if err := d.NocalhostSvc.FindOutSyncthingProcess(
+		func(pid int) error {
+			return syncthing.Stop(pid, true)
+		},
+	); err != nil {
+		return err
+	}
 	// kill syncthing process by find find it with terminal
 	str := strings.ReplaceAll(d.NocalhostSvc.GetSyncDir(), nocalhost_path.GetNhctlHomeDir(), "")
 	utils2.KillSyncthingProcess(str)
+	return nil
 }
 
 func (d *DevStartOps) startSyncthing(podName string, resume bool) {
@@ -294,20 +422,12 @@ func (d *DevStartOps) startSyncthing(podName string, resume bool) {
 }
 
 func (d *DevStartOps) enterDevMode(devModeType profile.DevModeType) error {
-	must(
-		d.NocalhostSvc.AppMeta.SvcDevStarting(
-			d.NocalhostSvc.Name, d.NocalhostSvc.Type,
-			d.NocalhostApp.Identifier, devModeType,
-		),
-	)
-	must(
-		d.NocalhostSvc.UpdateSvcProfile(
-			func(v2 *profile.SvcProfileV2) error {
-				v2.OriginDevContainer = d.Container
-				return nil
-			},
-		),
-	)
+	if err := d.NocalhostSvc.AppMeta.SvcDevStarting(
+		d.NocalhostSvc.Name, d.NocalhostSvc.Type,
+		d.NocalhostApp.Identifier, devModeType,
+	); err != nil {
+		return err
+	}
 
 	// prevent dev status modified but not actually enter dev mode
 	var devStartSuccess = false
@@ -321,35 +441,22 @@ func (d *DevStartOps) enterDevMode(devModeType profile.DevModeType) error {
 		}
 	}()
 
-	// Only `replace` DevMode needs to disable hpa
-	// replace and duplicate devMode also needs to disable HPA
-	//if devModeType.IsReplaceDevMode() {
-	log.Info("Disabling hpa...")
-	hl, err := d.NocalhostSvc.ListHPA()
-	if err != nil {
-		log.WarnE(err, "Failed to find hpa")
-	}
-	if len(hl) == 0 {
-		log.Info("No hpa found")
+	if err = d.NocalhostSvc.UpdateSvcProfile(
+		func(v2 *profile.SvcProfileV2) error {
+			v2.OriginDevContainer = d.Container
+			return nil
+		},
+	); err != nil {
+		return err
 	}
-	for _, h := range hl {
-		if len(h.Annotations) == 0 {
-			h.Annotations = make(map[string]string)
-		}
-		h.Annotations[_const.HPAOriginalMaxReplicasKey] = strconv.Itoa(int(h.Spec.MaxReplicas))
-		h.Spec.MaxReplicas = 1
-		if h.Spec.MinReplicas != nil {
-			h.Annotations[_const.HPAOriginalMinReplicasKey] = strconv.Itoa(int(*h.Spec.MinReplicas))
-			var i int32 = 1
-			h.Spec.MinReplicas = &i
-		}
-		if _, err = d.NocalhostSvc.Client.UpdateHPA(&h); err != nil {
-			log.WarnE(err, fmt.Sprintf("Failed to update hpa %s", h.Name))
-		} else {
-			log.Infof("HPA %s has been disabled", h.Name)
+
+	// Only `replace` DevMode needs to suspend hpa, which fights the scale patches,
+	// duplicate and ephemeral DevMode do not scale the workload
+	if devModeType.IsReplaceDevMode() {
+		if err = d.NocalhostSvc.SuspendHPA(); err != nil {
+			log.WarnE(err, "Failed to suspend hpa")
 		}
 	}
-	//}
 
 	//d.NocalhostSvc.DevModeType = devModeType
 	if err = d.NocalhostSvc.BuildPodController().ReplaceImage(context.TODO(), d.DevStartOptions); err != nil {
@@ -362,6 +469,10 @@ func (d *DevStartOps) enterDevMode(devModeType profile.DevModeType) error {
 		return err
 	}
 
+	if devModeType.IsReplaceDevMode() {
+		utils.ShouldI(d.NocalhostSvc.RecordDevModeTTL(d.TTL), "Failed to record ttl of DevMode")
+	}
+
 	if err = d.NocalhostSvc.AppMeta.SvcDevStartComplete(
 		d.NocalhostSvc.Name, d.NocalhostSvc.Type, d.NocalhostSvc.Identifier, devModeType,
 	); err != nil {
@@ -377,7 +488,7 @@ func (d *DevStartOps) enterDevMode(devModeType profile.DevModeType) error {
 }
 
 func (d *DevStartOps) startPortForwardAfterDevStart(devPodName string) {
-	for _, pf := range pfListBeforeDevStart {
+	for _, pf := range d.pfListBeforeDevStart {
 		utils.Should(d.NocalhostSvc.PortForward(devPodName, pf.LocalPort, pf.RemotePort, pf.Role))
 	}
 	must(d.NocalhostSvc.PortForwardAfterDevStart(devPodName, d.Container))
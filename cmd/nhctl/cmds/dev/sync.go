@@ -8,7 +8,6 @@ package dev
 import (
 	"context"
 	"github.com/pkg/errors"
-	"nocalhost/cmd/nhctl/cmds/common"
 	"nocalhost/internal/nhctl/coloredoutput"
 	_const "nocalhost/internal/nhctl/const"
 	"nocalhost/internal/nhctl/daemon_common"
@@ -23,17 +22,22 @@ import (
 )
 
 func (d *DevStartOps) StartSyncthing(podName string, resume bool, stop bool, syncDouble *bool, override bool) {
-	if !d.NocalhostSvc.IsInReplaceDevMode() && !d.NocalhostSvc.IsInDuplicateDevMode() {
-		log.Fatalf("Service \"%s\" is not in developing", common.WorkloadName)
+	if !d.NocalhostSvc.IsInDevMode() {
+		log.Fatalf("Service \"%s\" is not in developing", d.NocalhostSvc.Name)
 	}
 
 	if !d.NocalhostSvc.IsProcessor() {
 		log.Fatalf(
 			"Service \"%s\" is not process by current device (DevMode is start by other device),"+
-				" so can not operate the file sync", common.WorkloadName,
+				" so can not operate the file sync", d.NocalhostSvc.Name,
 		)
 	}
 
+	if d.NocalhostSvc.IsExecSync(d.Container) {
+		d.startExecSync(resume, stop)
+		return
+	}
+
 	// resume port-forward and syncthing
 	if resume || stop {
 		utils.ShouldI(d.NocalhostSvc.StopFileSyncOnly(), "Error occurs when stopping sync process")
@@ -115,6 +119,7 @@ func (d *DevStartOps) StartSyncthing(podName string, resume bool, stop bool, syn
 
 	// starts up a local syncthing
 	utils.ShouldI(newSyncthing.Run(context.TODO()), "Failed to run syncthing")
+	utils.ShouldI(d.NocalhostSvc.ApplySyncFolderIgnores(newSyncthing), "Failed to set ignores of sync folders")
 
 	must(d.NocalhostSvc.SetSyncingStatus(true))
 
@@ -139,3 +144,20 @@ func (d *DevStartOps) StartSyncthing(podName string, resume bool, stop bool, syn
 		}
 	}
 }
+
+// startExecSync starts a background process sending files by tar over the exec api,
+// neither syncthing nor port-forward of it is needed
+func (d *DevStartOps) startExecSync(resume bool, stop bool) {
+	if resume || stop {
+		utils.ShouldI(d.NocalhostSvc.StopFileSyncOnly(), "Error occurs when stopping sync process")
+		if stop {
+			return
+		}
+	} else if d.NocalhostSvc.FindOutExecSyncProcess() != 0 {
+		coloredoutput.Hint("Exec sync has been started")
+		return
+	}
+
+	must(d.NocalhostSvc.StartExecSyncProcess(d.Container, d.NocalhostSvc.Client.KubeConfigFilePath()))
+	must(d.NocalhostSvc.SetSyncingStatus(true))
+}
@@ -92,6 +92,10 @@ func init() {
 		&installFlags.LocalPath, "local-path", "",
 		"local path for application",
 	)
+	installCmd.Flags().StringVar(
+		&installFlags.Overlay, "overlay", "",
+		"overlay defined in configProperties.overlays of config to merge over application config",
+	)
 	rootCmd.AddCommand(installCmd)
 }
 
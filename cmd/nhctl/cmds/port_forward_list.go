@@ -30,6 +30,8 @@ type PortForwardItem struct {
 	DaemonServerPid int    `json:"daemonserverpid" yaml:"daemonserverpid"`
 	Updated         string `json:"updated" yaml:"updated"`
 	Reason          string `json:"reason" yaml:"reason"`
+	PodName         string `json:"podName" yaml:"podName"`
+	Target          string `json:"target,omitempty" yaml:"target,omitempty"`
 }
 
 var portForwardListCmd = &cobra.Command{
@@ -63,6 +65,8 @@ var portForwardListCmd = &cobra.Command{
 					DaemonServerPid: pf.DaemonServerPid,
 					Updated:         pf.Updated,
 					Reason:          pf.Reason,
+					PodName:         pf.PodName,
+					Target:          pf.Target,
 				})
 			}
 		}
@@ -23,7 +23,8 @@ func init() {
 	)
 	portForwardStartCmd.Flags().StringSliceVarP(
 		&portForwardOptions.DevPort, "dev-port", "p", []string{},
-		"port-forward between pod and local, such 8080:8080 or :8080(random localPort)",
+		"port-forward between pod and local, such 8080:8080 or :8080(random localPort), "+
+			"or to a ready pod of service, such as 8080:svc/reviews:http",
 	)
 	//portForwardStartCmd.Flags().BoolVarP(&portForwardOptions.RunAsDaemon,
 	// "daemon", "m", true, "if port-forward run as daemon")
@@ -82,6 +83,19 @@ var portForwardStartCmd = &cobra.Command{
 
 		var localPorts, remotePorts []int
 		for _, port := range portForwardOptions.DevPort {
+			// pod of service target is selected by daemon
+			if utils.IsServiceTarget(port) {
+				localPort, target, err := utils.GetServicePortForwardForString(port)
+				if err != nil {
+					log.WarnE(err, "")
+					continue
+				}
+				if portForwardOptions.Follow {
+					log.Fatal("--follow is not supported while forwarding to a service")
+				}
+				must(nocalhostSvc.PortForwardToService(target, localPort))
+				continue
+			}
 			localPort, remotePort, err := utils.GetPortForwardForString(port)
 			if err != nil {
 				log.WarnE(err, "")
@@ -0,0 +1,46 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package cmds
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"nocalhost/cmd/nhctl/cmds/common"
+	"nocalhost/pkg/nhctl/log"
+)
+
+func init() {
+	pvcRestoreCmd.Flags().StringVar(&pvcFlags.App, "app", "", "Application of the service")
+	pvcRestoreCmd.Flags().StringVar(&pvcFlags.Svc, "controller", "", "Restore PVCs of specified service")
+	pvcRestoreCmd.Flags().StringVarP(
+		&common.ServiceType, "controller-type", "t", "deployment",
+		"kind of k8s controller,such as deployment,statefulSet",
+	)
+	pvcCmd.AddCommand(pvcRestoreCmd)
+}
+
+var pvcRestoreCmd = &cobra.Command{
+	Use:   "restore [SNAPSHOT_NAME]",
+	Short: "Restore PersistVolumeClaims from a snapshot",
+	Long: `Restore PersistVolumeClaims of a service from a snapshot taken by nhctl pvc snapshot,
+PersistVolumeClaims of the service are replaced by new ones populated from the snapshot`,
+	Example: `nhctl pvc restore deps-2021 --app bookinfo --controller reviews`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if pvcFlags.App == "" || pvcFlags.Svc == "" {
+			log.Fatal("--app and --controller must be specified")
+		}
+		_, nocalhostSvc, err := common.InitAppAndCheckIfSvcExist(pvcFlags.App, pvcFlags.Svc, common.ServiceType)
+		must(err)
+		must(nocalhostSvc.RestorePVCs(args[0]))
+		log.Infof("PVCs of %s have been restored from snapshot %s", nocalhostSvc.Name, args[0])
+	},
+}
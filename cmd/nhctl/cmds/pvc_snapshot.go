@@ -0,0 +1,53 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package cmds
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"nocalhost/cmd/nhctl/cmds/common"
+	"nocalhost/pkg/nhctl/log"
+)
+
+var pvcSnapshotClass string
+
+func init() {
+	pvcSnapshotCmd.Flags().StringVar(&pvcFlags.App, "app", "", "Application of the service")
+	pvcSnapshotCmd.Flags().StringVar(&pvcFlags.Svc, "controller", "", "Take snapshots of PVCs of specified service")
+	pvcSnapshotCmd.Flags().StringVar(
+		&pvcSnapshotClass, "snapshot-class", "", "VolumeSnapshotClass to use, default VolumeSnapshotClass if not specified",
+	)
+	pvcSnapshotCmd.Flags().StringVarP(
+		&common.ServiceType, "controller-type", "t", "deployment",
+		"kind of k8s controller,such as deployment,statefulSet",
+	)
+	pvcCmd.AddCommand(pvcSnapshotCmd)
+}
+
+var pvcSnapshotCmd = &cobra.Command{
+	Use:   "snapshot [SNAPSHOT_NAME]",
+	Short: "Take snapshots of PersistVolumeClaims",
+	Long: `Take VolumeSnapshots of PersistVolumeClaims created for persistentVolumeDirs of a service,
+the snapshot can be used to restore them, or to seed new PersistVolumeClaims by volumeSnapshot of dev config.
+VolumeSnapshots are namespaced, they can only be used in the same namespace`,
+	Example: `nhctl pvc snapshot deps-2021 --app bookinfo --controller reviews`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if pvcFlags.App == "" || pvcFlags.Svc == "" {
+			log.Fatal("--app and --controller must be specified")
+		}
+		_, nocalhostSvc, err := common.InitAppAndCheckIfSvcExist(pvcFlags.App, pvcFlags.Svc, common.ServiceType)
+		must(err)
+		snapshots, err := nocalhostSvc.SnapshotPVCs(args[0], pvcSnapshotClass)
+		must(err)
+		log.Infof("Snapshot %s has been taken, %d PVCs included", args[0], len(snapshots))
+	},
+}
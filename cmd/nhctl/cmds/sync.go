@@ -48,6 +48,8 @@ func init() {
 		&fileSyncOps.Override, "overwrite", true,
 		"override the remote changing according to the local sync folder while start up",
 	)
+	fileSyncCmd.Flags().BoolVar(&fileSyncOps.ExecEngine, "exec-engine", false, "run the exec sync engine")
+	_ = fileSyncCmd.Flags().MarkHidden("exec-engine")
 	rootCmd.AddCommand(fileSyncCmd)
 }
 
@@ -68,6 +70,12 @@ var fileSyncCmd = &cobra.Command{
 			common.ServiceType)
 		must(err)
 
+		// background process started for sync type exec
+		if fileSyncOps.ExecEngine {
+			must(nocalhostSvc.RunExecSync(fileSyncOps.Container))
+			return
+		}
+
 		d := dev.DevStartOps{DevStartOptions: &model.DevStartOptions{}, NocalhostApp: nocalhostApp, NocalhostSvc: nocalhostSvc}
 		d.StartSyncthing(
 			"", fileSyncOps.Resume, fileSyncOps.Stop,
@@ -0,0 +1,68 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package cmds
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"nocalhost/cmd/nhctl/cmds/common"
+	"nocalhost/pkg/nhctl/log"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{syncPauseCmd, syncResumeCmd} {
+		cmd.Flags().StringVarP(
+			&common.WorkloadName, "deployment", "d", "",
+			"k8s deployment which your developing service exists",
+		)
+		cmd.Flags().StringVarP(
+			&common.ServiceType, "controller-type", "t", "deployment",
+			"kind of k8s controller,such as deployment,statefulSet",
+		)
+		fileSyncCmd.AddCommand(cmd)
+	}
+}
+
+var syncPauseCmd = &cobra.Command{
+	Use:   "pause [NAME]",
+	Short: "Pause file sync",
+	Long: `Pause file sync of all folders, files are neither scanned nor synced until file sync is resumed.
+It is kept paused while syncthing is restarted`,
+	Example: `nhctl sync pause bookinfo -d productpage`,
+	Args:    requireAppName,
+	Run: func(cmd *cobra.Command, args []string) {
+		pauseSync(args[0], true)
+		log.Infof("File sync of %s is paused", common.WorkloadName)
+	},
+}
+
+var syncResumeCmd = &cobra.Command{
+	Use:     "resume [NAME]",
+	Short:   "Resume file sync paused",
+	Long:    `Resume file sync paused by nhctl sync pause`,
+	Example: `nhctl sync resume bookinfo -d productpage`,
+	Args:    requireAppName,
+	Run: func(cmd *cobra.Command, args []string) {
+		pauseSync(args[0], false)
+		log.Infof("File sync of %s is resumed", common.WorkloadName)
+	},
+}
+
+func requireAppName(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+	}
+	return nil
+}
+
+func pauseSync(appName string, paused bool) {
+	_, nhSvc, err := common.InitAppAndCheckIfSvcExist(appName, common.WorkloadName, common.ServiceType)
+	must(err)
+	if !nhSvc.IsInDevMode() || !nhSvc.IsProcessor() {
+		log.Fatalf("%s is not in DevMode of this device", common.WorkloadName)
+	}
+	must(nhSvc.PauseSync(paused))
+}
@@ -0,0 +1,61 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package cmds
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"nocalhost/cmd/nhctl/cmds/common"
+	k8sutil "nocalhost/pkg/nhctl/k8sutils"
+	"nocalhost/pkg/nhctl/log"
+	"path/filepath"
+)
+
+func init() {
+	fileSyncCmd.AddCommand(syncPullCmd)
+}
+
+var syncPullCmd = &cobra.Command{
+	Use:   "pull REMOTE_PATH [LOCAL_PATH]",
+	Short: "Pull files from DevContainer",
+	Long: `Pull files or dirs from DevContainer to the local, such as generated code and build outputs
+in send-only mode. REMOTE_PATH is relative to the work dir if it is not absolute, LOCAL_PATH defaults
+to the same path in the local sync dir. Files ignored by sync patterns and files unchanged are skipped.
+The service is found by the dir associated with LOCAL_PATH or the current dir`,
+	Example: `nhctl sync pull build/generated
+nhctl sync pull /home/nocalhost-dev/go.sum ./go.sum`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return errors.Errorf("%q requires 1 or 2 arguments\n", cmd.CommandPath())
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		localPath, dir := "", "."
+		if len(args) > 1 {
+			abs, err := filepath.Abs(args[1])
+			must(errors.Wrap(err, ""))
+			localPath, dir = abs, abs
+		}
+		pack, err := associatedSvcPack(dir)
+		must(err)
+
+		kubeconfigBytes, _ := pack.GetKubeConfigBytesAndServer()
+		common.NameSpace = pack.Ns
+		common.KubeConfig = k8sutil.GetOrGenKubeConfigPath(kubeconfigBytes)
+		_, nhSvc, err := common.InitAppAndCheckIfSvcExist(pack.App, pack.Svc, pack.SvcType.String())
+		must(err)
+		if !nhSvc.IsInDevMode() || !nhSvc.IsProcessor() {
+			log.Fatalf("%s is not in DevMode of this device", pack.Svc)
+		}
+		svcProfile, err := nhSvc.GetProfile()
+		must(err)
+
+		pulled, err := nhSvc.PullSyncFiles(svcProfile.OriginDevContainer, args[0], localPath)
+		must(err)
+		log.Infof("%d files pulled from %s", pulled, args[0])
+	},
+}
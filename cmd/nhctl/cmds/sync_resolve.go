@@ -0,0 +1,80 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package cmds
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"nocalhost/cmd/nhctl/cmds/common"
+	"nocalhost/internal/nhctl/dev_dir"
+	"nocalhost/internal/nhctl/syncthing/network/req"
+	k8sutil "nocalhost/pkg/nhctl/k8sutils"
+	"nocalhost/pkg/nhctl/log"
+	"path/filepath"
+)
+
+var resolveKeep string
+
+func init() {
+	syncResolveCmd.Flags().StringVar(
+		&resolveKeep, "keep", "", "version to keep for conflicting files, local or remote",
+	)
+	fileSyncCmd.AddCommand(syncResolveCmd)
+}
+
+var syncResolveCmd = &cobra.Command{
+	Use:   "resolve [PATH]...",
+	Short: "Resolve sync conflicts",
+	Long: `Resolve conflicts of files modified both locally and in the remote, syncthing keeps the
+losing version in a .sync-conflict-* copy. The version specified by --keep is kept, and copies of
+the files are removed. The service is found by the dir associated with the files`,
+	Example: `nhctl sync resolve --keep local src/main.go`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.Errorf("%q requires at least 1 argument\n", cmd.CommandPath())
+		}
+		if resolveKeep != req.ConflictModifiedByLocal && resolveKeep != req.ConflictModifiedByRemote {
+			return errors.New("--keep must be local or remote")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, path := range args {
+			pack, err := associatedSvcPack(path)
+			must(err)
+
+			kubeconfigBytes, _ := pack.GetKubeConfigBytesAndServer()
+			common.NameSpace = pack.Ns
+			common.KubeConfig = k8sutil.GetOrGenKubeConfigPath(kubeconfigBytes)
+			_, nhSvc, err := common.InitAppAndCheckIfSvcExist(pack.App, pack.Svc, pack.SvcType.String())
+			must(err)
+			if !nhSvc.IsInDevMode() || !nhSvc.IsProcessor() {
+				log.Fatalf("%s is not in DevMode of this device", pack.Svc)
+			}
+
+			must(nhSvc.ResolveSyncConflict(path, resolveKeep == req.ConflictModifiedByLocal))
+			log.Infof("Conflict of %s resolved, %s version is kept", path, resolveKeep)
+		}
+	},
+}
+
+// associatedSvcPack returns the service associated with the nearest dir of path
+func associatedSvcPack(path string) (*dev_dir.SvcPack, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if pack, err := dev_dir.DevPath(dir).GetDefaultPack(); err == nil {
+			return pack, nil
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("No service is associated with %s", path))
+}
@@ -14,8 +14,10 @@ import (
 	"nocalhost/cmd/nhctl/cmds/common"
 	"nocalhost/internal/nhctl/app"
 	"nocalhost/internal/nhctl/common/base"
+	"nocalhost/internal/nhctl/controller"
 	"nocalhost/internal/nhctl/syncthing/network/req"
 	"nocalhost/pkg/nhctl/log"
+	"strings"
 	"time"
 )
 
@@ -47,6 +49,14 @@ func init() {
 		&syncStatusOps.Timeout, "timeout", 120,
 		"wait for sync process finished timeout, default is 120 seconds, unit is seconds ",
 	)
+	syncStatusCmd.Flags().BoolVar(
+		&syncStatusOps.Stats, "stats", false,
+		"list transfer statistics of the current sync session, which are collected by daemon",
+	)
+	syncStatusCmd.Flags().BoolVar(
+		&syncStatusOps.Conflicts, "conflicts", false,
+		"list conflict copies in local sync dirs and the remote, which walks all the files synced",
+	)
 	rootCmd.AddCommand(syncStatusCmd)
 }
 
@@ -95,6 +105,16 @@ func SyncStatus(opt *app.SyncStatusOptions, ns, app, svc, svcType, kubeconfig st
 		return req.NotProcessor
 	}
 
+	// files are sent by exec sync process, there is no syncthing to request
+	svcProfile, err := nhSvc.GetProfile()
+	if err == nil && nhSvc.IsExecSync(svcProfile.OriginDevContainer) {
+		return execSyncStatus(opt, nhSvc)
+	}
+	if err == nil && svcProfile.SyncPaused && (opt == nil || (!opt.Override && !opt.WaitForSync && !opt.Watch)) {
+		status := *req.SyncPaused
+		return withSyncStats(opt, nhSvc, &status)
+	}
+
 	// check if syncthing exists
 	//pid, err := nhSvc.GetSyncThingPid()
 	//if err != nil {
@@ -110,7 +130,9 @@ func SyncStatus(opt *app.SyncStatusOptions, ns, app, svc, svcType, kubeconfig st
 
 	if opt != nil {
 		if opt.Override {
-			must(client.FolderOverride())
+			for _, folder := range nhSvc.SyncFolderIDs() {
+				must(client.ForFolder(folder).FolderOverride())
+			}
 			display("Succeed")
 			return nil
 		}
@@ -126,7 +148,79 @@ func SyncStatus(opt *app.SyncStatusOptions, ns, app, svc, svcType, kubeconfig st
 		}
 	}
 
-	return client.GetSyncthingStatus()
+	status := client.GetFoldersSyncthingStatus(nhSvc.SyncFolderIDs())
+	// conflicts are listed while syncthing is running, walking the sync dirs is too heavy for polling
+	if opt != nil && opt.Conflicts && status.Status != req.Disconnected {
+		var err error
+		if status.Conflicts, err = nhSvc.ListSyncConflicts(); err != nil {
+			log.Logf("Failed to list all sync conflicts: %v", err)
+		}
+		if len(status.Conflicts) > 0 {
+			status.Tips = fmt.Sprintf(
+				"%s%d files are modified both locally and in the remote, "+
+					"resolve them by `nhctl sync resolve --keep local|remote <path>`. %s",
+				req.Identifier, len(status.Conflicts), strings.TrimPrefix(status.Tips, req.Identifier),
+			)
+		}
+	}
+	return withSyncStats(opt, nhSvc, status)
+}
+
+// withSyncStats attaches statistics recorded of the current session to status if --stats is specified
+func withSyncStats(opt *app.SyncStatusOptions, nhSvc *controller.Controller, status *req.SyncthingStatus) *req.SyncthingStatus {
+	if opt == nil || !opt.Stats {
+		return status
+	}
+	stats, err := nhSvc.SyncStats()
+	if err != nil {
+		log.Logf("Failed to get sync stats: %v", err)
+		return status
+	}
+	status.Stats = stats
+	return status
+}
+
+// execSyncStatus the exec sync engine sends local files only, so overriding is always done
+func execSyncStatus(opt *app.SyncStatusOptions, nhSvc *controller.Controller) *req.SyncthingStatus {
+	if opt == nil || (!opt.Override && !opt.WaitForSync && !opt.Watch) {
+		return nhSvc.ExecSyncStatus()
+	}
+	if opt.Override {
+		display("Succeed")
+		return nil
+	}
+
+	timeout := time.Second * time.Duration(opt.Timeout)
+	if opt.Watch {
+		timeout = time.Hour * 24
+	}
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	lastMsg := ""
+	for {
+		select {
+		case <-ctx.Done():
+			if opt.WaitForSync {
+				display(
+					req.SyncthingStatus{Status: req.Error, Msg: "wait for sync finished timeout", Tips: "", OutOfSync: ""},
+				)
+			}
+			return nil
+		default:
+			time.Sleep(time.Second * 1)
+			status := nhSvc.ExecSyncStatus()
+			if status.Status != req.Idle || status.Msg == lastMsg {
+				continue
+			}
+			lastMsg = status.Msg
+			if opt.WaitForSync {
+				display(req.SyncthingStatus{Status: req.Idle, Msg: "sync finished", Tips: "", OutOfSync: ""})
+				return nil
+			}
+			displayLn(req.SyncthingStatus{Status: req.Idle, Msg: "sync finished", Tips: "", OutOfSync: ""})
+		}
+	}
 }
 
 func display(v interface{}) {
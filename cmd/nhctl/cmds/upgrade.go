@@ -36,6 +36,10 @@ func init() {
 	upgradeCmd.Flags().StringVar(&installFlags.HelmRepoVersion, "helm-repo-version", "", "chart repository version")
 	upgradeCmd.Flags().StringVar(&installFlags.HelmChartName, "helm-chart-name", "", "chart name")
 	upgradeCmd.Flags().StringVar(&installFlags.LocalPath, "local-path", "", "local path for application")
+	upgradeCmd.Flags().StringVar(
+		&installFlags.Overlay, "overlay", "",
+		"overlay defined in configProperties.overlays of config to merge over application config",
+	)
 	rootCmd.AddCommand(upgradeCmd)
 }
 
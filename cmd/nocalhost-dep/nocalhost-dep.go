@@ -13,10 +13,13 @@ import (
 	"fmt"
 	"github.com/golang/glog"
 	"net/http"
+	"nocalhost/internal/nocalhost-dep/devmode"
 	"nocalhost/internal/nocalhost-dep/webhook"
+	nocalhost "nocalhost/pkg/nocalhost-dep/go-client"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 var GIT_COMMIT_SHA string
@@ -38,6 +41,9 @@ func main() {
 		&parameters.SidecarCfgFile, "sidecarCfgFile", "/etc/webhook/config/sidecarconfig.yaml",
 		"File containing the mutation configuration.",
 	)
+	devModeReapPeriod := flag.Duration(
+		"devModeReapPeriod", time.Minute, "Period to end DevMode whose heartbeat is not received within the ttl.",
+	)
 	flag.Parse()
 
 	glog.Infof("Current Version :[%s]", GIT_COMMIT_SHA)
@@ -52,6 +58,7 @@ func main() {
 		glog.Errorf("Failed to load key pair: %v", err)
 	}
 
+	webhook.InitClients()
 	whsvr := &webhook.WebhookServer{
 		SidecarConfig: sidecarConfig,
 		Server: &http.Server{
@@ -72,12 +79,19 @@ func main() {
 		}
 	}()
 
+	// end expired DevMode in new rountine
+	stopReaper := make(chan struct{})
+	go devmode.NewExpiryReaper(nocalhost.InitClientSet(), nocalhost.InitMetadataClient()).Run(
+		*devModeReapPeriod, stopReaper,
+	)
+
 	// listening OS shutdown singal
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 	<-signalChan
 
 	glog.Infof("Got OS shutdown signal, shutting down webhook server gracefully...")
+	close(stopReaper)
 	whsvr.Server.Shutdown(context.Background())
 }
 
@@ -10,6 +10,8 @@ TOP_PID=$$
 KUBECTL_ARGS=""
 WAIT_TIME="${WAIT_TIME:-2}" # seconds
 DEBUG="${DEBUG:-0}"
+TIMEOUT="${TIMEOUT:-0}" # seconds, 0 means waiting forever
+START_TIME=$(date +%s)
 TREAT_ERRORS_AS_READY=0
 
 usage() {
@@ -19,6 +21,14 @@ This script waits until a job, pod or service enter ready state.
 ${0##*/} job [<job name> | -l<kubectl selector>]
 ${0##*/} pod [<pod name> | -l<kubectl selector>]
 ${0##*/} service [<service name> | -l<kubectl selector>]
+${0##*/} tcp <host:port>
+${0##*/} http <url>
+${0##*/} grpc <host:port>[/<service>]
+${0##*/} dns <host name>
+${0##*/} kafka <broker:port>/<topic>
+${0##*/} endpoints [<namespace>/]<service name>
+
+Set TIMEOUT in seconds to fail with the reason if it is not ready in time.
 
 Examples:
 Wait for all pods with a following label to enter 'Ready' state:
@@ -36,6 +46,9 @@ ${0##*/} job-we develop-volume-s3-krakow-init
 Wait for all selected pods to enter the 'Ready' state:
 ${0##*/} pod -l"release in (develop), chart notin (cross-support-job-3p)"
 
+Wait at most 60 seconds for the gRPC server to report SERVING:
+TIMEOUT=60 ${0##*/} grpc reviews:9080
+
 EOF
 exit 1
 }
@@ -196,6 +209,7 @@ wait_for_resource() {
         print_KUBECTL_ARGS="$KUBECTL_ARGS"
         [ "$print_KUBECTL_ARGS" != "" ] && print_KUBECTL_ARGS=" $print_KUBECTL_ARGS"
         echo "Waiting for $wait_for_resource_type $wait_for_resource_descriptor${print_KUBECTL_ARGS}..."
+        check_timeout "$wait_for_resource_type $wait_for_resource_descriptor" "not ready"
         sleep "$WAIT_TIME"
     done
     ready "$wait_for_resource_type" "$wait_for_resource_descriptor"
@@ -207,25 +221,90 @@ ready() {
     printf "[%s] %s %s%s is ready.\\n" "$(date +'%Y-%m-%d %H:%M:%S')" "$1" "$2" "$print_KUBECTL_ARGS"
 }
 
+# Fails with the reason if it has been waiting longer than TIMEOUT
+check_timeout() {
+    check_timeout_elapsed=$(( $(date +%s) - START_TIME ))
+    if [ "$TIMEOUT" -gt 0 ] && [ "$check_timeout_elapsed" -ge "$TIMEOUT" ]; then
+        printf "[%s] ERROR: %s is not ready after %ss, reason: %s\n" \
+            "$(date +'%Y-%m-%d %H:%M:%S')" "$1" "$TIMEOUT" "$2" >&2
+        exit 1
+    fi
+}
+
+# Probes once, prints the reason and returns non-zero if it is not ready
+probe() {
+    probe_type=$1
+    address=$2
+    case "${probe_type}" in
+        tcp)
+            nc -vz -w 3 "${address%:*}" "${address#*:}" 2>&1
+            ;;
+        http)
+            probe_code=$(curl -sw '%{http_code}' "${address}" -o /dev/null)
+            if [ "${probe_code}" != "200" ]; then
+                echo "HTTP status ${probe_code}"
+                return 1
+            fi
+            ;;
+        grpc)
+            # host:port/service checks the status of the service, or the server if no service
+            probe_addr="${address%%/*}"
+            probe_service=""
+            [ "${probe_addr}" != "${address}" ] && probe_service="${address#*/}"
+            grpc_health_probe -addr="${probe_addr}" -service="${probe_service}" \
+                -connect-timeout 3s -rpc-timeout 3s 2>&1
+            ;;
+        dns)
+            probe_output=$(nslookup "${address}" 2>&1)
+            if [ $? -ne 0 ] || ! printf "%s" "${probe_output}" | sed '1,/^Name:/d' | grep -q 'Address'; then
+                echo "can not resolve ${address}"
+                return 1
+            fi
+            ;;
+        kafka)
+            probe_broker="${address%%/*}"
+            probe_topic="${address#*/}"
+            probe_output=$(kafkacat -b "${probe_broker}" -L -t "${probe_topic}" -m 5 2>&1)
+            if [ $? -ne 0 ]; then
+                printf "%s" "${probe_output}" | tail -n 1
+                return 1
+            fi
+            if ! printf "%s" "${probe_output}" | grep -q "topic \"${probe_topic}\" with [1-9][0-9]* partitions"; then
+                echo "topic ${probe_topic} does not exist on ${probe_broker}"
+                return 1
+            fi
+            ;;
+        endpoints)
+            # namespace/name or name
+            probe_ns=""
+            probe_name="${address#*/}"
+            [ "${probe_name}" != "${address}" ] && probe_ns="--namespace=${address%%/*}"
+            probe_output=$(kubectl get endpoints "${probe_name}" ${probe_ns} $KUBECTL_ARGS \
+                -o jsonpath='{.subsets[*].addresses[*].ip}' 2>&1)
+            if [ $? -ne 0 ]; then
+                echo "${probe_output}"
+                return 1
+            fi
+            if [ -z "${probe_output}" ]; then
+                echo "no ready endpoints of service ${address}"
+                return 1
+            fi
+            ;;
+    esac
+}
+
 readiness_probe() {
     probe_type=$1
     address=$2
-    # tcp
-    if [ "${probe_type}" == "tcp" ] && [ "${address}" != "" ]; then
-        until nc -vz -w 3 "${address%:*}" "${address#*:}"; do
-          echo "Waiting for ${address} ..."
-          sleep 5
-        done
-        ready "${probe_type}" "${address}"
-    fi
-    # http
-    if [ "${probe_type}" == "http" ] && [ "${address}" != "" ]; then
-        until [ "$(curl -sw '%{http_code}' "${address}" -o /dev/null)" -eq 200 ]; do
-          echo "Waiting for ${address} ..."
-          sleep 5
-        done
-        ready "${probe_type}" "${address}"
+    if [ "${address}" = "" ]; then
+        return
     fi
+    until probe_reason=$(probe "${probe_type}" "${address}"); do
+        echo "Waiting for ${probe_type} ${address}: ${probe_reason}"
+        check_timeout "${probe_type} ${address}" "${probe_reason}"
+        sleep 5
+    done
+    ready "${probe_type}" "${address}"
 }
 
 main() {
@@ -243,7 +322,7 @@ main() {
             TREAT_ERRORS_AS_READY=1
             shift
             ;;
-        tcp|http)
+        tcp|http|grpc|dns|kafka|endpoints)
             main_resource=$1
             shift
             ;;
@@ -258,11 +337,14 @@ main() {
 
     KUBECTL_ARGS="${*}"
 
-    if [ "$main_resource" == "tcp" ] || [ "$main_resource" == "http" ]; then
-        readiness_probe "$main_resource" "$main_name"
-    else
-        wait_for_resource "$main_resource" "$main_name"
-    fi
+    case "$main_resource" in
+        pod|service|job)
+            wait_for_resource "$main_resource" "$main_name"
+            ;;
+        *)
+            readiness_probe "$main_resource" "$main_name"
+            ;;
+    esac
 
     exit 0
 }
@@ -50,6 +50,58 @@ type Application struct {
 
 	appMeta *appmeta.ApplicationMeta
 	client  *clientgoutils.ClientGoUtils
+
+	// overlay in configProperties.overlays merged over application config while loading config
+	configOverlay string
+
+	// references to secrets resolved while rendering configs in this process
+	secretRefs *envsubst.SecretRefRecorder
+}
+
+// SetConfigOverlay selects the overlay merged over application config while loading config
+func (a *Application) SetConfigOverlay(overlay string) {
+	a.configOverlay = overlay
+}
+
+// svcConfigOverlay returns the overlay selected in this process,
+// or the one recorded in svc profile by dev start
+func (a *Application) svcConfigOverlay(svcName string, svcType base.SvcType) string {
+	if a.configOverlay != "" {
+		return a.configOverlay
+	}
+	if svcProfile, err := a.GetSvcProfile(svcName, svcType); err == nil {
+		return svcProfile.ConfigOverlay
+	}
+	return ""
+}
+
+// clusterRefResolver resolves references to secrets and configmaps in configs with the client of the app
+func (a *Application) clusterRefResolver() envsubst.ClusterRefResolver {
+	// avoid returning a non-nil interface holding a nil client
+	if a.client == nil {
+		return nil
+	}
+	if a.secretRefs == nil {
+		a.secretRefs = &envsubst.SecretRefRecorder{ClusterRefResolver: a.client}
+	}
+	return a.secretRefs
+}
+
+// resolvedSecretRefs returns references to secrets resolved while rendering configs in this process
+func (a *Application) resolvedSecretRefs() []string {
+	if a.secretRefs == nil {
+		return nil
+	}
+	return a.secretRefs.Refs
+}
+
+// MaskConfigSecrets masks values of secrets referred by the config stored in cluster, the config is
+// rendered by another process, values of secrets are unknown to log.Mask until they are resolved again
+func (a *Application) MaskConfigSecrets() error {
+	if a.client == nil || len(a.appMeta.SecretRefs) == 0 {
+		return nil
+	}
+	return envsubst.MaskSecretRefs(a.client, a.appMeta.SecretRefs)
 }
 
 type SvcDependency struct {
@@ -214,6 +266,12 @@ func (a *Application) ReloadSvcCfg(svcName string, svcType base.SvcType, reloadF
 	return nil
 }
 
+// ReloadSvcCfgFromLocal only loads config under associateDir/.nocalhost/config.yaml,
+// config loaded before is kept if the local config is missing or invalid
+func (a *Application) ReloadSvcCfgFromLocal(svcName string, svcType base.SvcType) bool {
+	return a.loadSvcCfgFromLocalIfValid(svcName, svcType, true)
+}
+
 func (a *Application) loadSvcCfmFromAnnotationIfValid(svcName string, svcType base.SvcType, silence bool) bool {
 	hint := hintFunc(svcName, svcType, silence)
 
@@ -234,7 +292,9 @@ func (a *Application) loadSvcCfmFromAnnotationIfValid(svcName string, svcType ba
 		return false
 	} else {
 		_, // local config should not contain app config
-			svcCfg, err := LoadSvcCfgFromStrIfValid(v, svcName, svcType)
+			svcCfg, err := loadSvcCfgFromStrIfValid(
+			v, svcName, svcType, a.svcConfigOverlay(svcName, svcType), a.clusterRefResolver(),
+		)
 		if err != nil {
 			hint(
 				"Load nocalhost svc config from [Resource:%s, Name:%s] annotation fail, err: %s",
@@ -294,7 +354,9 @@ func (a *Application) loadSvcCfgFromCmIfValid(svcName string, svcType base.SvcTy
 	}
 
 	_, // local config should not contain app config
-		svcCfg, err := LoadSvcCfgFromStrIfValid(cfgStr, svcName, svcType)
+		svcCfg, err := loadSvcCfgFromStrIfValid(
+		cfgStr, svcName, svcType, a.svcConfigOverlay(svcName, svcType), a.clusterRefResolver(),
+	)
 	if err != nil {
 		hint("Load nocalhost svc config from cm fail, err: %s", err.Error())
 		return false
@@ -335,16 +397,22 @@ func (a *Application) loadSvcCfgFromCmIfValid(svcName string, svcType base.SvcTy
 
 // LoadSvcCfgFromStrIfValid
 // CAUTION: appCfg may nil!
+// references to the cluster such as ${secret:ns/name#key} are not resolved
 func LoadSvcCfgFromStrIfValid(config string, svcName string, svcType base.SvcType) (
 	*profile.NocalHostAppConfigV2, *profile.ServiceConfigV2, error) {
+	return loadSvcCfgFromStrIfValid(config, svcName, svcType, "", nil)
+}
+
+func loadSvcCfgFromStrIfValid(config string, svcName string, svcType base.SvcType, overlay string,
+	refs envsubst.ClusterRefResolver) (*profile.NocalHostAppConfigV2, *profile.ServiceConfigV2, error) {
 	var svcCfg *profile.ServiceConfigV2
 	var appCfg *profile.NocalHostAppConfigV2
 	var err error
 	if svcCfg, _ = doLoadProfileFromSvcConfig(
-		envsubst.TextRenderItem(config), svcName, svcType,
+		envsubst.TextRenderItem(config), svcName, svcType, refs,
 	); svcCfg == nil {
 		if appCfg, svcCfg, err = doLoadProfileFromAppConfig(
-			envsubst.TextRenderItem(config), svcName, svcType,
+			envsubst.TextRenderItem(config), svcName, svcType, overlay, refs,
 		); err != nil {
 			return nil, nil, errors.New(fmt.Sprintf("can not load cfg, may has syntax error, Content: %s", config))
 		}
@@ -383,11 +451,13 @@ func (a *Application) loadSvcCfgFromLocalIfValid(svcName string, svcType base.Sv
 
 	var svcCfg *profile.ServiceConfigV2
 	if svcCfg, err = doLoadProfileFromSvcConfig(
-		envsubst.LocalFileRenderItem{FilePathEnhance: configFile}, svcName, svcType,
+		envsubst.LocalFileRenderItem{FilePathEnhance: configFile}, svcName, svcType, a.clusterRefResolver(),
 	); svcCfg == nil {
 		if _, // local config should not contain app config
 			svcCfg, _ = doLoadProfileFromAppConfig(
 			envsubst.LocalFileRenderItem{FilePathEnhance: configFile}, svcName, svcType,
+			a.svcConfigOverlay(svcName, svcType),
+			a.clusterRefResolver(),
 		); svcCfg == nil {
 			if err != nil {
 
@@ -447,10 +517,9 @@ func hintFunc(svcName string, svcType base.SvcType, silence bool) func(string, .
 	}
 }
 
-func doLoadProfileFromSvcConfig(renderItem envsubst.RenderItem, svcName string, svcType base.SvcType) (
-	*profile.ServiceConfigV2, error,
-) {
-	config, err := RenderConfigForSvc(renderItem)
+func doLoadProfileFromSvcConfig(renderItem envsubst.RenderItem, svcName string, svcType base.SvcType,
+	refs envsubst.ClusterRefResolver) (*profile.ServiceConfigV2, error) {
+	config, err := RenderConfigForSvc(renderItem, refs)
 	if err != nil {
 		return nil, err
 	}
@@ -470,10 +539,9 @@ func doLoadProfileFromSvcConfig(renderItem envsubst.RenderItem, svcName string,
 	return nil, errors.New("Local config loaded, but no valid config found")
 }
 
-func doLoadProfileFromAppConfig(configFile envsubst.RenderItem, svcName string, svcType base.SvcType) (
-	*profile.NocalHostAppConfigV2, *profile.ServiceConfigV2, error,
-) {
-	appConfig, err := RenderConfig(configFile)
+func doLoadProfileFromAppConfig(configFile envsubst.RenderItem, svcName string, svcType base.SvcType,
+	overlay string, refs envsubst.ClusterRefResolver) (*profile.NocalHostAppConfigV2, *profile.ServiceConfigV2, error) {
+	appConfig, err := RenderConfig(configFile, overlay, refs)
 	if err != nil {
 		return nil, nil, err
 	}
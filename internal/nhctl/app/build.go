@@ -42,9 +42,10 @@ func BuildApplication(name string, flags *app_flags.InstallFlags, kubeconfig str
 	var err error
 
 	app := &Application{
-		Name:       name,
-		NameSpace:  namespace,
-		KubeConfig: kubeconfig,
+		Name:          name,
+		NameSpace:     namespace,
+		KubeConfig:    kubeconfig,
+		configOverlay: flags.Overlay,
 	}
 
 	// try to create a new application meta
@@ -110,6 +111,7 @@ func BuildApplication(name string, flags *app_flags.InstallFlags, kubeconfig str
 
 	appMeta.Config = config
 	appMeta.Config.Migrated = true
+	appMeta.SecretRefs = app.resolvedSecretRefs()
 	if err := appMeta.Update(); err != nil {
 		return nil, err
 	}
@@ -156,6 +158,21 @@ func (a *Application) loadOrGenerateConfig(
 				},
 			}
 			nocalhostConfig = renderedConfig
+			// fall back to devcontainer.json of VS Code
+			if devContainerConfig := findDevContainerConfig(a.ResourceTmpDir); devContainerConfig != "" {
+				svcConfig, unmapped, err := ConvertDevContainerConfig(devContainerConfig, a.Name)
+				if err != nil {
+					return nil, err
+				}
+				log.Infof("No config found, %s is used", devContainerConfig)
+				if len(unmapped) > 0 {
+					log.Warnf("Fields of %s can not be mapped: %s", devContainerConfig, strings.Join(unmapped, ", "))
+				}
+				renderedConfig.ApplicationConfig.ServiceConfigs = []*profile.ServiceConfigV2{svcConfig}
+			}
+			if a.configOverlay != "" {
+				return nil, errors.New(fmt.Sprintf("Overlay %s not found, no config found", a.configOverlay))
+			}
 		} else {
 			configFilePath = a.getConfigPathInGitResourcesDir(config)
 		}
@@ -164,7 +181,8 @@ func (a *Application) loadOrGenerateConfig(
 	// config.yaml found
 	if configFilePath != "" {
 		if nocalhostConfig, err = RenderConfig(
-			envsubst.LocalFileRenderItem{FilePathEnhance: fp.NewFilePath(configFilePath)},
+			envsubst.LocalFileRenderItem{FilePathEnhance: fp.NewFilePath(configFilePath)}, a.configOverlay,
+			a.clusterRefResolver(),
 		); err != nil {
 			return nil, err
 		}
@@ -173,8 +191,11 @@ func (a *Application) loadOrGenerateConfig(
 	return nocalhostConfig, nil
 }
 
-func RenderConfigForSvc(renderItem envsubst.RenderItem) ([]*profile.ServiceConfigV2, error) {
-	renderedStr, err := envsubst.Render(renderItem, nil)
+// RenderConfigForSvc renders service configs, references to the cluster are resolved with refs if not nil
+func RenderConfigForSvc(renderItem envsubst.RenderItem, refs envsubst.ClusterRefResolver) (
+	[]*profile.ServiceConfigV2, error,
+) {
+	renderedStr, err := envsubst.RenderWithClusterRefs(renderItem, nil, refs)
 	if err != nil {
 		return nil, err
 	}
@@ -191,8 +212,15 @@ func RenderConfigForSvc(renderItem envsubst.RenderItem) ([]*profile.ServiceConfi
 	return renderedConfig, nil
 }
 
-// V2
-func RenderConfig(renderItem envsubst.RenderItem) (*profile.NocalHostAppConfigV2, error) {
+// RenderConfig V2, config is resolved in order:
+// 1. render with envsubst and the env file, resolve references to the cluster with refs if not nil
+// 2. convert v1 to v2 if needed
+// 3. resolve paths of files included
+// 4. merge the overlay over application if specified
+// 5. read envFrom into env, env has a higher priority
+func RenderConfig(renderItem envsubst.RenderItem, overlay string, refs envsubst.ClusterRefResolver) (
+	*profile.NocalHostAppConfigV2, error,
+) {
 	configFileLocation := renderItem.GetLocation()
 
 	var envFile *fp.FilePathEnhance
@@ -228,7 +256,7 @@ func RenderConfig(renderItem envsubst.RenderItem) (*profile.NocalHostAppConfigV2
 			)
 		}
 
-		renderedStr, err = envsubst.Render(renderItem, envFile)
+		renderedStr, err = envsubst.RenderWithClusterRefs(renderItem, envFile, refs)
 		if err != nil {
 			return nil, err
 		}
@@ -253,15 +281,15 @@ func RenderConfig(renderItem envsubst.RenderItem) (*profile.NocalHostAppConfigV2
 				return nil, err
 			}
 
-			if renderedStr, err = envsubst.Render(
+			if renderedStr, err = envsubst.RenderWithClusterRefs(
 				envsubst.LocalFileRenderItem{FilePathEnhance: fp.NewFilePath(v2Path)},
-				envFile,
+				envFile, refs,
 			); err != nil {
 				return nil, err
 			}
 		}
 	} else {
-		renderedStr, err = envsubst.Render(renderItem, envFile)
+		renderedStr, err = envsubst.RenderWithClusterRefs(renderItem, envFile, refs)
 		if err != nil {
 			return nil, err
 		}
@@ -275,6 +303,9 @@ func RenderConfig(renderItem envsubst.RenderItem) (*profile.NocalHostAppConfigV2
 	renderedConfig := &profile.NocalHostAppConfigV2{}
 	if err := parseNocalhostConfigEnvFile(
 		renderedStr, fp.NewFilePath(configFileLocation), func(node *customyaml3.Node) error {
+			if err := applyConfigOverlay(node, overlay); err != nil {
+				return err
+			}
 			_ = node.Decode(renderedConfig)
 
 			parseEnvFromIntoEnv(renderedConfig)
@@ -319,6 +350,20 @@ func RenderConfig(renderItem envsubst.RenderItem) (*profile.NocalHostAppConfigV2
 	return renderedConfig, nil
 }
 
+// RenderConfigFile renders a local config file with the env file configured in it,
+// v1 config is not converted
+func RenderConfigFile(configFile string) (string, error) {
+	file := fp.NewFilePath(configFile)
+	if err := file.CheckExist(); err != nil {
+		return "", err
+	}
+	var envFile *fp.FilePathEnhance
+	if relPath := gettingRenderEnvFile(file.Abs()); relPath != "" {
+		envFile = file.RelOrAbs("../").RelOrAbs(relPath)
+	}
+	return envsubst.Render(envsubst.LocalFileRenderItem{FilePathEnhance: file}, envFile)
+}
+
 func parseEnvFromIntoEnv(config *profile.NocalHostAppConfigV2) {
 	if config != nil {
 
@@ -0,0 +1,333 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package app
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"nocalhost/internal/nhctl/envsubst"
+	"nocalhost/internal/nhctl/fp"
+	profile2 "nocalhost/internal/nhctl/profile"
+	"nocalhost/pkg/nhctl/log"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dockerCompose fields of docker-compose.yml which can be converted to nocalhost config
+type dockerCompose struct {
+	Services map[string]*composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image         string            `yaml:"image"`
+	ContainerName string            `yaml:"container_name"`
+	Command       composeStringList `yaml:"command"`
+	WorkingDir    string            `yaml:"working_dir"`
+	Environment   composeEnv        `yaml:"environment"`
+	EnvFile       composeStringList `yaml:"env_file"`
+	Ports         []composePort     `yaml:"ports"`
+	Volumes       []composeVolume   `yaml:"volumes"`
+	DependsOn     composeDependsOn  `yaml:"depends_on"`
+}
+
+// composeStringList a string or a list of strings
+type composeStringList []string
+
+func (l *composeStringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = []string{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// composeEnv a map or a list of KEY=VALUE
+type composeEnv map[string]string
+
+func (e *composeEnv) UnmarshalYAML(value *yaml.Node) error {
+	env := map[string]string{}
+	if value.Kind == yaml.MappingNode {
+		if err := value.Decode(&env); err != nil {
+			return err
+		}
+	} else {
+		var list []string
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		for _, item := range list {
+			kv := strings.SplitN(item, "=", 2)
+			if len(kv) == 2 {
+				env[kv[0]] = kv[1]
+			} else {
+				env[kv[0]] = ""
+			}
+		}
+	}
+	*e = env
+	return nil
+}
+
+// composeDependsOn a list of services or a map of services to conditions
+type composeDependsOn []string
+
+func (d *composeDependsOn) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		var list []string
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		*d = list
+		return nil
+	}
+	services := make([]string, 0)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		services = append(services, value.Content[i].Value)
+	}
+	*d = services
+	return nil
+}
+
+// composePort short syntax such as 127.0.0.1:8080:80/tcp, or long syntax
+type composePort struct {
+	Target    string `yaml:"target"`
+	Published string `yaml:"published"`
+	Protocol  string `yaml:"protocol"`
+}
+
+func (p *composePort) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		type plain composePort
+		return value.Decode((*plain)(p))
+	}
+	spec := value.Value
+	if i := strings.Index(spec, "/"); i >= 0 {
+		p.Protocol = spec[i+1:]
+		spec = spec[:i]
+	}
+	parts := strings.Split(spec, ":")
+	p.Target = parts[len(parts)-1]
+	if len(parts) > 1 {
+		p.Published = parts[len(parts)-2]
+	}
+	return nil
+}
+
+// composeVolume short syntax such as ./src:/app/src:ro, or long syntax
+type composeVolume struct {
+	Type   string `yaml:"type"`
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+}
+
+func (v *composeVolume) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		type plain composeVolume
+		return value.Decode((*plain)(v))
+	}
+	parts := strings.Split(value.Value, ":")
+	// windows drive such as C:\src
+	if len(parts) > 2 && len(parts[0]) == 1 {
+		parts = append([]string{parts[0] + ":" + parts[1]}, parts[2:]...)
+	}
+	if len(parts) == 1 {
+		v.Type, v.Target = "volume", parts[0]
+		return nil
+	}
+	v.Source, v.Target = parts[0], parts[1]
+	if isComposeBindSource(v.Source) {
+		v.Type = "bind"
+	} else {
+		v.Type = "volume"
+	}
+	return nil
+}
+
+func isComposeBindSource(source string) bool {
+	return strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") ||
+		strings.HasPrefix(source, "~") || filepath.IsAbs(source)
+}
+
+// ConvertDockerComposeFile converts services of docker-compose.yml to service configs,
+// paths of env files are relative to configDir, where the config will be saved
+func ConvertDockerComposeFile(composeFile string, configDir string) ([]*profile2.ServiceConfigV2, error) {
+	bytes, err := ioutil.ReadFile(composeFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+
+	// variables are substituted with .env in the project dir, just like docker-compose
+	projectDir := filepath.Dir(composeFile)
+	rendered, err := envsubst.Render(
+		envsubst.TextRenderItem(bytes), fp.NewFilePath(filepath.Join(projectDir, ".env")),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	compose := &dockerCompose{}
+	if err = yaml.Unmarshal([]byte(rendered), compose); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	if len(compose.Services) == 0 {
+		return nil, errors.New(fmt.Sprintf("No service found in %s", composeFile))
+	}
+	return convertDockerComposeToServiceConfigs(compose, projectDir, configDir), nil
+}
+
+func convertDockerComposeToServiceConfigs(compose *dockerCompose, projectDir, configDir string,
+) []*profile2.ServiceConfigV2 {
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	svcConfigs := make([]*profile2.ServiceConfigV2, 0, len(names))
+	for _, name := range names {
+		svcConfigs = append(svcConfigs, convertComposeService(name, compose.Services[name], projectDir, configDir))
+	}
+	return svcConfigs
+}
+
+func convertComposeService(name string, svc *composeService, projectDir, configDir string,
+) *profile2.ServiceConfigV2 {
+	containerName := svc.ContainerName
+	if containerName == "" {
+		containerName = name
+	}
+	dev := &profile2.ContainerDevConfig{
+		Image:   svc.Image,
+		WorkDir: svc.WorkingDir,
+		Sync:    &profile2.SyncConfig{Type: "send"},
+	}
+
+	if len(svc.Command) > 0 {
+		dev.Command = &profile2.DevCommands{Run: svc.Command}
+	}
+
+	envNames := make([]string, 0, len(svc.Environment))
+	for k := range svc.Environment {
+		envNames = append(envNames, k)
+	}
+	sort.Strings(envNames)
+	for _, k := range envNames {
+		dev.Env = append(dev.Env, &profile2.Env{Name: k, Value: svc.Environment[k]})
+	}
+
+	if len(svc.EnvFile) > 0 {
+		dev.EnvFrom = &profile2.EnvFrom{}
+		for _, f := range svc.EnvFile {
+			envFile := filepath.Join(projectDir, f)
+			if rel, err := filepath.Rel(configDir, envFile); err == nil {
+				envFile = rel
+			}
+			dev.EnvFrom.EnvFile = append(dev.EnvFrom.EnvFile, &profile2.EnvFile{Path: filepath.ToSlash(envFile)})
+		}
+	}
+
+	for _, port := range svc.Ports {
+		if port.Protocol != "" && port.Protocol != "tcp" {
+			log.Warnf("Port %s/%s of %s is skipped, only tcp can be forwarded", port.Target, port.Protocol, name)
+			continue
+		}
+		dev.PortForward = append(dev.PortForward, composePortForwards(port)...)
+	}
+
+	for _, volume := range svc.Volumes {
+		switch volume.Type {
+		case "volume":
+			dev.PersistentVolumeDirs = append(dev.PersistentVolumeDirs, &profile2.PersistentVolumeDir{Path: volume.Target})
+		case "bind":
+			convertComposeBindMount(name, volume, dev)
+		default:
+			log.Warnf("Volume %s of %s is skipped, %s is not supported", volume.Target, name, volume.Type)
+		}
+	}
+
+	svcConfig := &profile2.ServiceConfigV2{
+		Name: name,
+		Type: "deployment",
+		ContainerConfigs: []*profile2.ContainerConfig{
+			{Name: containerName, Dev: dev},
+		},
+	}
+	if len(svc.DependsOn) > 0 {
+		// pods are selected by app label if no label specified
+		svcConfig.DependLabelSelector = &profile2.DependLabelSelector{Pods: svc.DependsOn}
+	}
+	return svcConfig
+}
+
+// composePortForwards converts port such as 8080:80 or 3000-3001:4000-4001 to local:remote
+func composePortForwards(port composePort) []string {
+	published := port.Published
+	if published == "" {
+		published = port.Target
+	}
+	targetFrom, targetTo, err1 := composePortRange(port.Target)
+	publishedFrom, publishedTo, err2 := composePortRange(published)
+	if err1 != nil || err2 != nil || targetTo-targetFrom != publishedTo-publishedFrom {
+		log.Warnf("Port %s:%s is skipped, it is invalid", published, port.Target)
+		return nil
+	}
+	result := make([]string, 0)
+	for i := 0; i <= targetTo-targetFrom; i++ {
+		result = append(result, fmt.Sprintf("%d:%d", publishedFrom+i, targetFrom+i))
+	}
+	return result
+}
+
+func composePortRange(ports string) (int, int, error) {
+	bounds := strings.SplitN(ports, "-", 2)
+	from, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+	if len(bounds) == 1 {
+		return from, from, nil
+	}
+	to, err := strconv.Atoi(bounds[1])
+	return from, to, errors.WithStack(err)
+}
+
+// convertComposeBindMount syncs source code mounted into the container, files are synced to workDir,
+// so the mount target must be in workDir with the same relative path as source in the project dir
+func convertComposeBindMount(name string, volume composeVolume, dev *profile2.ContainerDevConfig) {
+	source := filepath.ToSlash(filepath.Clean(volume.Source))
+	if filepath.IsAbs(volume.Source) || strings.HasPrefix(source, "..") || strings.HasPrefix(source, "~") {
+		log.Warnf("Volume %s of %s is skipped, only dirs in the project can be synced", volume.Source, name)
+		return
+	}
+	target := path.Clean(volume.Target)
+
+	workDir := target
+	if source != "." {
+		if !strings.HasSuffix(target, "/"+source) {
+			log.Warnf("Volume %s:%s of %s is skipped, it can not be synced to workDir", volume.Source, target, name)
+			return
+		}
+		workDir = strings.TrimSuffix(target, "/"+source)
+	}
+	if dev.WorkDir != "" && dev.WorkDir != workDir {
+		log.Warnf("Volume %s:%s of %s is skipped, workDir is %s", volume.Source, target, name, dev.WorkDir)
+		return
+	}
+	dev.WorkDir = workDir
+	if source != "." {
+		dev.Sync.FilePattern = append(dev.Sync.FilePattern, "./"+source)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConvertDockerComposeFile(t *testing.T) {
+	compose := `version: "3.8"
+services:
+  web:
+    image: ${REGISTRY}/web:latest
+    container_name: web-app
+    command: npm run dev
+    environment:
+      - NODE_ENV=development
+      - DEBUG
+    env_file: .env.web
+    ports:
+      - "3000"
+      - "127.0.0.1:8080:80/tcp"
+      - "9000-9001:7000-7001"
+      - "5353:53/udp"
+    volumes:
+      - ./src:/app/src
+      - node_modules:/app/node_modules
+      - /var/run/docker.sock:/var/run/docker.sock
+    depends_on:
+      db:
+        condition: service_healthy
+  db:
+    image: postgres:13
+    command: ["postgres", "-c", "log_statement=all"]
+    environment:
+      POSTGRES_PASSWORD: secret
+    ports:
+      - target: 5432
+        published: 15432
+    volumes:
+      - type: volume
+        source: data
+        target: /var/lib/postgresql/data
+      - .:/workspace
+volumes:
+  node_modules:
+  data:
+`
+	dir, err := ioutil.TempDir("", "compose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	composeFile := filepath.Join(dir, "docker-compose.yml")
+	if err = ioutil.WriteFile(composeFile, []byte(compose), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, ".env"), []byte("REGISTRY=docker.io/demo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	svcConfigs, err := ConvertDockerComposeFile(composeFile, filepath.Join(dir, ".nocalhost"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(svcConfigs) != 2 || svcConfigs[0].Name != "db" || svcConfigs[1].Name != "web" {
+		t.Fatalf("unexpected services %v", svcConfigs)
+	}
+
+	db := svcConfigs[0].ContainerConfigs[0].Dev
+	if !reflect.DeepEqual(db.Command.Run, []string{"postgres", "-c", "log_statement=all"}) {
+		t.Errorf("unexpected command of db %v", db.Command.Run)
+	}
+	if !reflect.DeepEqual(db.PortForward, []string{"15432:5432"}) {
+		t.Errorf("unexpected ports of db %v", db.PortForward)
+	}
+	if len(db.PersistentVolumeDirs) != 1 || db.PersistentVolumeDirs[0].Path != "/var/lib/postgresql/data" {
+		t.Errorf("unexpected persistent volume dirs of db %v", db.PersistentVolumeDirs)
+	}
+	if db.WorkDir != "/workspace" || len(db.Sync.FilePattern) != 0 {
+		t.Errorf("unexpected sync of db %s %v", db.WorkDir, db.Sync.FilePattern)
+	}
+
+	web := svcConfigs[1]
+	if web.ContainerConfigs[0].Name != "web-app" {
+		t.Errorf("unexpected container name %s", web.ContainerConfigs[0].Name)
+	}
+	if !reflect.DeepEqual(web.DependLabelSelector.Pods, []string{"db"}) {
+		t.Errorf("unexpected depends of web %v", web.DependLabelSelector.Pods)
+	}
+	dev := web.ContainerConfigs[0].Dev
+	if dev.Image != "docker.io/demo/web:latest" {
+		t.Errorf("unexpected image of web %s", dev.Image)
+	}
+	if !reflect.DeepEqual(dev.Command.Run, []string{"npm run dev"}) {
+		t.Errorf("unexpected command of web %v", dev.Command.Run)
+	}
+	if len(dev.Env) != 2 || dev.Env[0].Name != "DEBUG" || dev.Env[1].Value != "development" {
+		t.Errorf("unexpected env of web %v", dev.Env)
+	}
+	if len(dev.EnvFrom.EnvFile) != 1 || dev.EnvFrom.EnvFile[0].Path != "../.env.web" {
+		t.Errorf("unexpected env file of web %v", dev.EnvFrom.EnvFile)
+	}
+	if !reflect.DeepEqual(dev.PortForward, []string{"3000:3000", "8080:80", "9000:7000", "9001:7001"}) {
+		t.Errorf("unexpected ports of web %v", dev.PortForward)
+	}
+	if len(dev.PersistentVolumeDirs) != 1 || dev.PersistentVolumeDirs[0].Path != "/app/node_modules" {
+		t.Errorf("unexpected persistent volume dirs of web %v", dev.PersistentVolumeDirs)
+	}
+	if dev.WorkDir != "/app" || !reflect.DeepEqual(dev.Sync.FilePattern, []string{"./src"}) {
+		t.Errorf("unexpected sync of web %s %v", dev.WorkDir, dev.Sync.FilePattern)
+	}
+}
@@ -0,0 +1,295 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"nocalhost/internal/nhctl/profile"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// devContainerConfigPaths where VS Code looks for devcontainer.json, relative to the project dir
+var devContainerConfigPaths = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+var (
+	devContainerVariableRegex = regexp.MustCompile(`\$\{([^}]+)\}`)
+)
+
+// findDevContainerConfig returns path of devcontainer.json in dir, or empty if not found
+func findDevContainerConfig(dir string) string {
+	for _, p := range devContainerConfigPaths {
+		if _, err := os.Stat(filepath.Join(dir, p)); err == nil {
+			return filepath.Join(dir, p)
+		}
+	}
+	return ""
+}
+
+// ConvertDevContainerConfig converts devcontainer.json to a service config, image, forwardPorts,
+// containerEnv, remoteEnv, postCreateCommand, postStartCommand and workspaceFolder are mapped,
+// fields which can not be mapped are returned. The service is named by `service` of
+// compose based dev containers, or defaultSvc which the command targets, `name` is only displayed
+func ConvertDevContainerConfig(file, defaultSvc string) (*profile.ServiceConfigV2, []string, error) {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "")
+	}
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(stripJSONComments(bytes), &fields); err != nil {
+		return nil, nil, errors.Wrap(err, fmt.Sprintf("Failed to parse %s", file))
+	}
+
+	// the project dir contains .devcontainer
+	projectDir := filepath.Dir(file)
+	if filepath.Base(projectDir) == ".devcontainer" {
+		projectDir = filepath.Dir(projectDir)
+	}
+	c := &devContainerConverter{
+		fields:     fields,
+		projectDir: projectDir,
+		dev:        &profile.ContainerDevConfig{},
+		unmapped:   make([]string, 0),
+	}
+
+	svcName := c.stringField("service")
+	if svcName == "" {
+		svcName = defaultSvc
+	}
+	if name := c.stringField("name"); name != "" {
+		log.Infof("Dev container %s is converted to config of service %s", name, svcName)
+	}
+
+	c.dev.WorkDir = c.resolve("workspaceFolder", c.stringField("workspaceFolder"))
+	c.dev.Image = c.resolve("image", c.stringField("image"))
+	c.convertForwardPorts()
+	c.convertEnv()
+	if build := c.commandField("postCreateCommand"); build != nil {
+		c.dev.Command = &profile.DevCommands{Build: build}
+	}
+	if run := c.commandField("postStartCommand"); run != nil {
+		if c.dev.Command == nil {
+			c.dev.Command = &profile.DevCommands{}
+		}
+		c.dev.Command.Run = run
+	}
+
+	for name := range c.fields {
+		if !devContainerMappedFields[name] {
+			c.unmapped = append(c.unmapped, name)
+		}
+	}
+	sort.Strings(c.unmapped)
+
+	return &profile.ServiceConfigV2{
+		Name:             svcName,
+		Type:             "deployment",
+		ContainerConfigs: []*profile.ContainerConfig{{Dev: c.dev}},
+	}, c.unmapped, nil
+}
+
+var devContainerMappedFields = map[string]bool{
+	"$schema":           true,
+	"name":              true,
+	"service":           true,
+	"image":             true,
+	"forwardPorts":      true,
+	"containerEnv":      true,
+	"remoteEnv":         true,
+	"postCreateCommand": true,
+	"postStartCommand":  true,
+	"workspaceFolder":   true,
+}
+
+type devContainerConverter struct {
+	fields     map[string]json.RawMessage
+	projectDir string
+	dev        *profile.ContainerDevConfig
+	unmapped   []string
+}
+
+func (c *devContainerConverter) stringField(name string) string {
+	var s string
+	if raw, ok := c.fields[name]; ok && json.Unmarshal(raw, &s) != nil {
+		c.unmapped = append(c.unmapped, name)
+	}
+	return s
+}
+
+func (c *devContainerConverter) convertForwardPorts() {
+	raw, ok := c.fields["forwardPorts"]
+	if !ok {
+		return
+	}
+	var ports []interface{}
+	if err := json.Unmarshal(raw, &ports); err != nil {
+		c.unmapped = append(c.unmapped, "forwardPorts")
+		return
+	}
+	for _, port := range ports {
+		switch p := port.(type) {
+		case float64:
+			c.dev.PortForward = append(c.dev.PortForward, fmt.Sprintf("%d:%d", int(p), int(p)))
+		case string:
+			// ports of other hosts such as db:5432 can not be forwarded from the dev container
+			hostPort := strings.SplitN(p, ":", 2)
+			if len(hostPort) == 2 && (hostPort[0] == "localhost" || hostPort[0] == "127.0.0.1") {
+				c.dev.PortForward = append(c.dev.PortForward, hostPort[1]+":"+hostPort[1])
+			} else {
+				c.unmapped = append(c.unmapped, fmt.Sprintf("forwardPorts[%s]", p))
+			}
+		}
+	}
+}
+
+// convertEnv merges containerEnv and remoteEnv, remoteEnv has a higher priority
+func (c *devContainerConverter) convertEnv() {
+	env := map[string]string{}
+	for _, field := range []string{"containerEnv", "remoteEnv"} {
+		raw, ok := c.fields[field]
+		if !ok {
+			continue
+		}
+		values := map[string]*string{}
+		if err := json.Unmarshal(raw, &values); err != nil {
+			c.unmapped = append(c.unmapped, field)
+			continue
+		}
+		for k, v := range values {
+			// null unsets the variable
+			if v == nil {
+				delete(env, k)
+				continue
+			}
+			env[k] = c.resolve(fmt.Sprintf("%s.%s", field, k), *v)
+		}
+	}
+
+	names := make([]string, 0, len(env))
+	for k := range env {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		c.dev.Env = append(c.dev.Env, &profile.Env{Name: k, Value: env[k]})
+	}
+}
+
+// commandField converts a command in string or array form, commands run in parallel
+// in object form can not be mapped
+func (c *devContainerConverter) commandField(name string) []string {
+	raw, ok := c.fields[name]
+	if !ok {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []string{c.resolve(name, s)}
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for i := range list {
+			list[i] = c.resolve(name, list[i])
+		}
+		return list
+	}
+	c.unmapped = append(c.unmapped, name)
+	return nil
+}
+
+// resolve substitutes variables of devcontainer.json, ${containerEnv:VAR} can not be resolved
+// before the container starts, so it is kept and reported
+func (c *devContainerConverter) resolve(field, value string) string {
+	return devContainerVariableRegex.ReplaceAllStringFunc(
+		value, func(s string) string {
+			variable := s[2 : len(s)-1]
+			parts := strings.SplitN(variable, ":", 3)
+			switch parts[0] {
+			case "localWorkspaceFolder":
+				return c.projectDir
+			case "localWorkspaceFolderBasename":
+				return filepath.Base(c.projectDir)
+			case "containerWorkspaceFolder":
+				return c.dev.WorkDir
+			case "containerWorkspaceFolderBasename":
+				return filepath.Base(c.dev.WorkDir)
+			case "localEnv", "env":
+				if len(parts) < 2 {
+					break
+				}
+				if v, ok := os.LookupEnv(parts[1]); ok {
+					return v
+				}
+				if len(parts) == 3 {
+					return parts[2]
+				}
+				return ""
+			}
+			c.unmapped = append(c.unmapped, fmt.Sprintf("%s[%s]", field, s))
+			return s
+		},
+	)
+}
+
+// stripJSONComments removes comments and trailing commas allowed in devcontainer.json
+func stripJSONComments(bytes []byte) []byte {
+	result := make([]byte, 0, len(bytes))
+	inString := false
+	for i := 0; i < len(bytes); i++ {
+		ch := bytes[i]
+		if inString {
+			result = append(result, ch)
+			if ch == '\\' && i+1 < len(bytes) {
+				i++
+				result = append(result, bytes[i])
+			} else if ch == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case ch == '"':
+			inString = true
+			result = append(result, ch)
+		case ch == '/' && i+1 < len(bytes) && bytes[i+1] == '/':
+			for i < len(bytes) && bytes[i] != '\n' {
+				i++
+			}
+			if i < len(bytes) {
+				result = append(result, '\n')
+			}
+		case ch == '/' && i+1 < len(bytes) && bytes[i+1] == '*':
+			end := strings.Index(string(bytes[i+2:]), "*/")
+			if end < 0 {
+				return result
+			}
+			i += end + 3
+		case ch == ']' || ch == '}':
+			// drop the trailing comma before the closing bracket
+			j := len(result) - 1
+			for j >= 0 && strings.ContainsRune(" \t\r\n", rune(result[j])) {
+				j--
+			}
+			if j >= 0 && result[j] == ',' {
+				result = append(result[:j], result[j+1:]...)
+			}
+			result = append(result, ch)
+		default:
+			result = append(result, ch)
+		}
+	}
+	return result
+}
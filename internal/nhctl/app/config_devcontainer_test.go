@@ -0,0 +1,92 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigFromDevContainer(t *testing.T) {
+	devContainer := `// generated by VS Code
+{
+	"name": "Go Reviews",
+	"image": "mcr.microsoft.com/devcontainers/go:${localEnv:GO_VERSION:1.16}",
+	/* ports of the app */
+	"forwardPorts": [8080, "localhost:9090", "db:5432"],
+	"containerEnv": {"GOPROXY": "https://goproxy.cn", "CGO_ENABLED": "1"},
+	"remoteEnv": {
+		"CGO_ENABLED": "0",
+		"GOPROXY": null,
+		"HOME_PATH": "${containerEnv:HOME}", // unknown before start
+	},
+	"postCreateCommand": "go mod download",
+	"postStartCommand": ["go", "run", "${containerWorkspaceFolder}/main.go"],
+	"workspaceFolder": "/workspaces/${localWorkspaceFolderBasename}",
+	"features": {"ghcr.io/devcontainers/features/git:1": {}},
+}
+`
+	dir, err := ioutil.TempDir("", "reviews")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = os.MkdirAll(filepath.Join(dir, ".devcontainer"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(
+		filepath.Join(dir, ".devcontainer", "devcontainer.json"), []byte(devContainer), 0644,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.Unsetenv("GO_VERSION")
+	a := &Application{Name: "bookinfo", ResourceTmpDir: dir}
+	config, err := a.loadOrGenerateConfig("", "", nil, "rawManifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.ApplicationConfig.ServiceConfigs) != 1 {
+		t.Fatalf("unexpected services %v", config.ApplicationConfig.ServiceConfigs)
+	}
+	svc := config.ApplicationConfig.ServiceConfigs[0]
+	// the service is named by the app installed, name of the dev container is only displayed
+	if svc.Name != "bookinfo" || svc.Type != "deployment" {
+		t.Errorf("unexpected service %s %s", svc.Name, svc.Type)
+	}
+
+	dev := svc.ContainerConfigs[0].Dev
+	workDir := "/workspaces/" + filepath.Base(dir)
+	if dev.WorkDir != workDir {
+		t.Errorf("unexpected workDir %s", dev.WorkDir)
+	}
+	if dev.Image != "mcr.microsoft.com/devcontainers/go:1.16" {
+		t.Errorf("unexpected image %s", dev.Image)
+	}
+	if !reflect.DeepEqual(dev.PortForward, []string{"8080:8080", "9090:9090"}) {
+		t.Errorf("unexpected ports %v", dev.PortForward)
+	}
+	if len(dev.Env) != 2 || dev.Env[0].Name != "CGO_ENABLED" || dev.Env[0].Value != "0" ||
+		dev.Env[1].Name != "HOME_PATH" {
+		t.Errorf("unexpected env %v", dev.Env)
+	}
+	if !reflect.DeepEqual(dev.Command.Build, []string{"go mod download"}) ||
+		!reflect.DeepEqual(dev.Command.Run, []string{"go", "run", workDir + "/main.go"}) {
+		t.Errorf("unexpected commands %v", dev.Command)
+	}
+
+	_, unmapped, err := ConvertDevContainerConfig(filepath.Join(dir, ".devcontainer", "devcontainer.json"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"features", "forwardPorts[db:5432]", "remoteEnv.HOME_PATH[${containerEnv:HOME}]"}
+	if !reflect.DeepEqual(unmapped, expected) {
+		t.Errorf("unexpected unmapped fields %v", unmapped)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package app
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	customyaml3 "nocalhost/pkg/nhctl/utils/custom_yaml_v3"
+	"strings"
+)
+
+// items of lists are merged by these fields, such as services by name and serviceType,
+// containers and env by name, helmValues by key and envFile by path
+var overlayMergeKeys = []string{"name", "serviceType", "key", "path"}
+
+// applyConfigOverlay strategically merges the overlay named in configProperties.overlays over application,
+// mappings are merged recursively, lists whose items have merge keys are merged item by item,
+// others are replaced
+func applyConfigOverlay(doc *customyaml3.Node, overlay string) error {
+	if overlay == "" {
+		return nil
+	}
+	root := doc
+	if root.Kind == customyaml3.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	overlayNode := mappingNodeValue(mappingNodeValue(mappingNodeValue(root, "configProperties"), "overlays"), overlay)
+	if overlayNode == nil {
+		return errors.New(fmt.Sprintf("Overlay %s not found in configProperties.overlays", overlay))
+	}
+	if root.Kind != customyaml3.MappingNode {
+		return errors.New("Overlays can only be applied to application config")
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "application" {
+			root.Content[i+1] = mergeOverlayNode(root.Content[i+1], overlayNode)
+			return nil
+		}
+	}
+	root.Content = append(
+		root.Content, &customyaml3.Node{Kind: customyaml3.ScalarNode, Tag: "!!str", Value: "application"}, overlayNode,
+	)
+	return nil
+}
+
+func mergeOverlayNode(base, overlay *customyaml3.Node) *customyaml3.Node {
+	switch {
+	case base.Kind == customyaml3.MappingNode && overlay.Kind == customyaml3.MappingNode:
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+			merged := false
+			for j := 0; j+1 < len(base.Content); j += 2 {
+				if base.Content[j].Value == key.Value {
+					base.Content[j+1] = mergeOverlayNode(base.Content[j+1], value)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				base.Content = append(base.Content, key, value)
+			}
+		}
+		return base
+	case base.Kind == customyaml3.SequenceNode && overlay.Kind == customyaml3.SequenceNode && isKeyedList(overlay):
+		for _, item := range overlay.Content {
+			identity := overlayItemIdentity(item)
+			merged := false
+			for j, baseItem := range base.Content {
+				if overlayItemIdentity(baseItem) == identity {
+					base.Content[j] = mergeOverlayNode(baseItem, item)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				base.Content = append(base.Content, item)
+			}
+		}
+		return base
+	default:
+		return overlay
+	}
+}
+
+func isKeyedList(node *customyaml3.Node) bool {
+	if len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		if overlayItemIdentity(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func overlayItemIdentity(node *customyaml3.Node) string {
+	ids := make([]string, 0)
+	for _, key := range overlayMergeKeys {
+		if v := mappingNodeValue(node, key); v != nil && v.Kind == customyaml3.ScalarNode {
+			ids = append(ids, key+"="+v.Value)
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+func mappingNodeValue(node *customyaml3.Node, key string) *customyaml3.Node {
+	if node == nil || node.Kind != customyaml3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package app
+
+import (
+	"nocalhost/internal/nhctl/envsubst"
+	"testing"
+)
+
+func TestRenderConfigWithOverlay(t *testing.T) {
+	config := `configProperties:
+  version: v2
+  overlays:
+    ci:
+      env:
+        - name: PROFILE
+          value: ${PROFILE:-ci}
+      services:
+        - name: reviews
+          serviceType: deployment
+          containers:
+            - name: reviews
+              dev:
+                image: reviews-ci
+                portForward:
+                  - 9090:9080
+        - name: ratings
+          serviceType: deployment
+application:
+  name: bookinfo
+  env:
+    - name: PROFILE
+      value: dev
+    - name: REGION
+      value: gz
+  services:
+    - name: reviews
+      serviceType: deployment
+      containers:
+        - name: reviews
+          dev:
+            image: reviews-dev
+            workDir: /home/nocalhost-dev
+            portForward:
+              - 9080:9080
+`
+	renderedConfig, err := RenderConfig(envsubst.TextRenderItem(config), "ci", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := renderedConfig.ApplicationConfig
+	envs := map[string]string{}
+	for _, env := range app.Env {
+		envs[env.Name] = env.Value
+	}
+	if len(envs) != 2 || envs["PROFILE"] != "ci" || envs["REGION"] != "gz" {
+		t.Fatalf("env should be merged by name, but got %v", envs)
+	}
+	if len(app.ServiceConfigs) != 2 {
+		t.Fatalf("services should be merged by name and type, but got %d", len(app.ServiceConfigs))
+	}
+	reviews := renderedConfig.GetSvcConfigV2("reviews", "deployment")
+	dev := reviews.GetContainerConfig("reviews").Dev
+	if dev.Image != "reviews-ci" || dev.WorkDir != "/home/nocalhost-dev" {
+		t.Fatalf("dev config should be merged, but got image %s, workDir %s", dev.Image, dev.WorkDir)
+	}
+	if len(dev.PortForward) != 1 || dev.PortForward[0] != "9090:9080" {
+		t.Fatalf("list of scalars should be replaced, but got %v", dev.PortForward)
+	}
+
+	if _, err = RenderConfig(envsubst.TextRenderItem(config), "teamA", nil); err == nil {
+		t.Fatal("overlay not defined should be reported")
+	}
+}
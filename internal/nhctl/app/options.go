@@ -14,6 +14,9 @@ type FileSyncOptions struct {
 	Container      string // container name of pod to sync
 	Resume         bool
 	Stop           bool
+
+	// ExecEngine runs the exec sync engine in the current process, used by nhctl itself
+	ExecEngine bool
 }
 
 type SyncStatusOptions struct {
@@ -21,6 +24,8 @@ type SyncStatusOptions struct {
 	WaitForSync bool
 	Watch       bool
 	Timeout     int64
+	Stats       bool
+	Conflicts   bool
 }
 
 type SyncStatusDirOptions struct {
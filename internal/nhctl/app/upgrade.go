@@ -40,6 +40,7 @@ func (a *Application) PrepareForUpgrade(flags *flag.InstallFlags) error {
 		return nil
 	}
 
+	a.configOverlay = flags.Overlay
 	config, err := a.loadOrGenerateConfig(flags.OuterConfig, flags.Config, flags.ResourcePath, flags.AppType)
 	if err != nil {
 		return err
@@ -47,6 +48,7 @@ func (a *Application) PrepareForUpgrade(flags *flag.InstallFlags) error {
 
 	a.appMeta.Config = config
 	a.appMeta.Config.Migrated = true
+	a.appMeta.SecretRefs = a.resolvedSecretRefs()
 	return a.appMeta.Update()
 }
 
@@ -23,6 +23,8 @@ type InstallFlags struct {
 	ResourcePath     []string
 	//Namespace        string
 	LocalPath string
+	// Overlay in configProperties.overlays merged over application config
+	Overlay string
 }
 
 type ListFlags struct {
@@ -0,0 +1,118 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package appmeta
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"nocalhost/internal/nhctl/common/base"
+	profile2 "nocalhost/internal/nhctl/profile"
+	"time"
+)
+
+var ErrDevSessionExists = errors.New("Dev session already exists")
+
+// ApplicationDevSessions records workloads which enter and end DevMode as a unit
+type ApplicationDevSessions map[ /* session name */ string]*DevSession
+
+type DevSession struct {
+	Name        string                `json:"name" yaml:"name"`
+	Identifier  string                `json:"identifier" yaml:"identifier"`
+	DevModeType profile2.DevModeType  `json:"devModeType" yaml:"devModeType"`
+	Status      DevStartStatus        `json:"status" yaml:"status"`
+	Workloads   []*DevSessionWorkload `json:"workloads" yaml:"workloads"`
+	CreatedAt   int64                 `json:"createdAt" yaml:"createdAt"`
+}
+
+type DevSessionWorkload struct {
+	Name      string       `json:"name" yaml:"name"`
+	Type      base.SvcType `json:"type" yaml:"type"`
+	Container string       `json:"container,omitempty" yaml:"container,omitempty"`
+}
+
+func (w *DevSessionWorkload) String() string {
+	return fmt.Sprintf("%s/%s", w.Type, w.Name)
+}
+
+// DevSessionStarting recode the session as starting, a workload can only belong to one session
+func (a *ApplicationMeta) DevSessionStarting(session *DevSession) error {
+	if a.DevSessions == nil {
+		a.DevSessions = ApplicationDevSessions{}
+	}
+
+	if _, ok := a.DevSessions[session.Name]; ok {
+		return errors.Wrap(ErrDevSessionExists, session.Name)
+	}
+
+	for _, s := range a.DevSessions {
+		for _, w := range s.Workloads {
+			for _, nw := range session.Workloads {
+				if w.Name == nw.Name && w.Type.Alias() == nw.Type.Alias() {
+					return errors.New(fmt.Sprintf("%s already belongs to dev session %s", nw.String(), s.Name))
+				}
+			}
+		}
+	}
+
+	session.Status = STARTING
+	session.CreatedAt = time.Now().Unix()
+	a.DevSessions[session.Name] = session
+	return a.Update()
+}
+
+// DevSessionStartComplete mark session as started after all the workloads enter DevMode
+func (a *ApplicationMeta) DevSessionStartComplete(name string) error {
+	session := a.GetDevSession(name)
+	if session == nil {
+		return errors.New(fmt.Sprintf("Dev session %s not found", name))
+	}
+	session.Status = STARTED
+	return a.Update()
+}
+
+func (a *ApplicationMeta) DevSessionEnd(name string) error {
+	if a.DevSessions == nil {
+		return nil
+	}
+	delete(a.DevSessions, name)
+	return a.Update()
+}
+
+func (a *ApplicationMeta) GetDevSession(name string) *DevSession {
+	if a.DevSessions == nil {
+		return nil
+	}
+	return a.DevSessions[name]
+}
+
+// DevSessionRemoveWorkload removes the workload from the session it belongs to after its DevMode
+// is ended separately, the session is removed with its last workload
+func (a *ApplicationMeta) DevSessionRemoveWorkload(name string, svcType base.SvcType) error {
+	if !a.DevSessions.remove(name, svcType) {
+		return nil
+	}
+	return a.Update()
+}
+
+// remove returns true if the workload is found, a new slice is allocated for workloads of the session
+// as callers may be ranging over the old one while ending DevMode of each workload
+func (s ApplicationDevSessions) remove(name string, svcType base.SvcType) bool {
+	for sessionName, session := range s {
+		for i, w := range session.Workloads {
+			if w.Name != name || w.Type.Alias() != svcType.Alias() {
+				continue
+			}
+			workloads := make([]*DevSessionWorkload, 0, len(session.Workloads)-1)
+			workloads = append(workloads, session.Workloads[:i]...)
+			session.Workloads = append(workloads, session.Workloads[i+1:]...)
+			if len(session.Workloads) == 0 {
+				delete(s, sessionName)
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package appmeta
+
+import (
+	"nocalhost/internal/nhctl/common/base"
+	"testing"
+)
+
+func TestDevSessionRemoveWhileRanging(t *testing.T) {
+	session := &DevSession{
+		Name: "review",
+		Workloads: []*DevSessionWorkload{
+			{Name: "productpage", Type: base.Deployment},
+			{Name: "reviews", Type: base.Deployment},
+			{Name: "ratings", Type: base.Deployment},
+			{Name: "db", Type: base.StatefulSet},
+		},
+	}
+	sessions := ApplicationDevSessions{session.Name: session}
+
+	ended := make([]string, 0)
+	for _, w := range session.Workloads {
+		if !sessions.remove(w.Name, w.Type) {
+			t.Fatalf("%s should be found in the session", w)
+		}
+		ended = append(ended, w.String())
+	}
+
+	if len(ended) != 4 || ended[1] != "deployment/reviews" || ended[3] != "statefulset/db" {
+		t.Fatalf("every workload should be ended exactly once, got %v", ended)
+	}
+	if _, ok := sessions[session.Name]; ok {
+		t.Fatal("session should be removed with its last workload")
+	}
+}
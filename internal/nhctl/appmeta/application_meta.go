@@ -56,6 +56,8 @@ const (
 	SecretConfigKey           = "c"
 	SecretStateKey            = "s"
 	SecretDepKey              = "d"
+	SecretDevSessionKey       = "ds"
+	SecretSecretRefsKey       = "sr"
 
 	Helm           AppType = "helmGit"
 	HelmRepo       AppType = "helmRepo"
@@ -166,6 +168,7 @@ func (as ApplicationMetas) Desc() (result ApplicationMetaSimples) {
 				Ns:                 meta.Ns,
 				ApplicationState:   meta.ApplicationState,
 				DevMeta:            meta.DevMeta,
+				DevSessions:        meta.DevSessions,
 				Manifest:           meta.Manifest,
 				PreInstallManifest: meta.PreInstallManifest,
 			},
@@ -179,9 +182,10 @@ type ApplicationMetaSimple struct {
 	Ns               string           `json:"ns"`
 	ApplicationState ApplicationState `json:"application_state"`
 	// manage the dev status of the application
-	DevMeta            ApplicationDevMeta `json:"dev_meta"`
-	Manifest           string             `json:"manifest"`
-	PreInstallManifest string             `json:"pre_install_manifest"`
+	DevMeta            ApplicationDevMeta     `json:"dev_meta"`
+	DevSessions        ApplicationDevSessions `json:"dev_sessions"`
+	Manifest           string                 `json:"manifest"`
+	PreInstallManifest string                 `json:"pre_install_manifest"`
 }
 
 func FakeAppMeta(ns, application string) *ApplicationMeta {
@@ -227,9 +231,15 @@ type ApplicationMeta struct {
 	// manage the dev status of the application
 	DevMeta ApplicationDevMeta `json:"dev_meta"`
 
+	// workloads enter and end DevMode as a unit
+	DevSessions ApplicationDevSessions `json:"dev_sessions"`
+
 	// store all the config of application
 	Config *profile2.NocalHostAppConfigV2 `json:"config"`
 
+	// references to secrets resolved while rendering Config, such as ns/name#key
+	SecretRefs []string `json:"secret_refs"`
+
 	// something like database
 	Secret *corev1.Secret `json:"secret"`
 
@@ -315,11 +325,24 @@ func (a *ApplicationMeta) ReAssignmentBySecret(secret *corev1.Secret) error {
 		a.DevMeta = *devMeta
 	}
 
+	if bs, ok := secret.Data[SecretDevSessionKey]; ok {
+		devSessions := ApplicationDevSessions{}
+
+		_ = yaml.Unmarshal(bs, &devSessions)
+		a.DevSessions = devSessions
+	}
+
 	if bs, ok := secret.Data[SecretConfigKey]; ok {
 		config, _ := unmarshalConfigUnStrict(decompress(bs))
 		a.Config = config
 	}
 
+	if bs, ok := secret.Data[SecretSecretRefsKey]; ok {
+		secretRefs := make([]string, 0)
+		_ = yaml.Unmarshal(bs, &secretRefs)
+		a.SecretRefs = secretRefs
+	}
+
 	if a.Config == nil {
 		a.Config = &profile2.NocalHostAppConfigV2{}
 	}
@@ -606,6 +629,20 @@ func (a *ApplicationMeta) InitGoClient(kubeConfigPath string) error {
 	return err
 }
 
+// InitGoClientInCluster inits client with the service account of the pod, hooks and custom resources
+// which need kubeconfig are not supported by the client
+func (a *ApplicationMeta) InitGoClientInCluster() error {
+	clientGo, err := clientgoutils.NewClientGoUtilsInCluster(a.Ns)
+	if err != nil {
+		return err
+	}
+	a.operator = &operator.ClientGoUtilClient{
+		ClientInner: clientGo,
+		Dc:          clientGo.GetDynamicClient(),
+	}
+	return nil
+}
+
 func (a *ApplicationMeta) SvcDevModePossessor(name string, svcType base.SvcType, identifier string, modeType profile2.DevModeType) bool {
 	name = devModeName(name, identifier, modeType)
 	devMeta := a.DevMeta
@@ -688,6 +725,20 @@ func (a *ApplicationMeta) SvcDevStartComplete(name string, svcType base.SvcType,
 }
 
 func (a *ApplicationMeta) SvcDevEnd(name, identifier string, svcType base.SvcType, modeType profile2.DevModeType) error {
+	a.svcDevEnd(name, identifier, svcType, modeType)
+	return a.Update()
+}
+
+// SvcDevEndInCluster ends DevMode of the svc and removes it from the dev session it belongs to, the secret
+// is updated without notifying the daemon, as it is used by components running in cluster
+func (a *ApplicationMeta) SvcDevEndInCluster(name, identifier string, svcType base.SvcType,
+	modeType profile2.DevModeType) error {
+	a.svcDevEnd(name, identifier, svcType, modeType)
+	a.DevSessions.remove(name, svcType)
+	return a.update(false)
+}
+
+func (a *ApplicationMeta) svcDevEnd(name, identifier string, svcType base.SvcType, modeType profile2.DevModeType) {
 	name = devModeName(name, identifier, modeType)
 	devMeta := a.DevMeta
 	if devMeta == nil {
@@ -704,7 +755,6 @@ func (a *ApplicationMeta) SvcDevEnd(name, identifier string, svcType base.SvcTyp
 
 	delete(m, inDevStartingMark)
 	delete(m, name)
-	return a.Update()
 }
 
 func (a *ApplicationMeta) CheckIfSvcDeveloping(name, identifier string, svcType base.SvcType, modeType profile2.DevModeType) DevStartStatus {
@@ -733,6 +783,10 @@ func (a *ApplicationMeta) CheckIfSvcDeveloping(name, identifier string, svcType
 
 // Update if update occurs errors, it will get a new secret from k8s, and retry update again, default retry times is 5
 func (a *ApplicationMeta) Update() error {
+	return a.update(true)
+}
+
+func (a *ApplicationMeta) update(notifyDaemon bool) error {
 	return retry.OnError(
 		retry.DefaultRetry, func(err error) bool {
 			if err != nil {
@@ -755,6 +809,9 @@ func (a *ApplicationMeta) Update() error {
 				return errors.Wrap(err, "Error while update Application meta ")
 			}
 			a.Secret = secret
+			if !notifyDaemon {
+				return nil
+			}
 			// update daemon application meta manually
 			if client, err := daemon_client.GetDaemonClient(false); err == nil {
 				_, _ = client.SendUpdateApplicationMetaCommand(
@@ -781,6 +838,9 @@ func (a *ApplicationMeta) prepare() {
 	config, _ := yaml.Marshal(a.Config)
 	a.Secret.Data[SecretConfigKey] = compress(config)
 
+	secretRefs, _ := yaml.Marshal(a.SecretRefs)
+	a.Secret.Data[SecretSecretRefsKey] = secretRefs
+
 	a.Secret.Data[SecretStateKey] = []byte(a.ApplicationState)
 	a.Secret.Data[SecretDepKey] = []byte(a.DepConfigName)
 	a.Secret.Data[SecretAppTypeKey] = []byte(a.ApplicationType)
@@ -788,6 +848,9 @@ func (a *ApplicationMeta) prepare() {
 
 	devMeta, _ := yaml.Marshal(&a.DevMeta)
 	a.Secret.Data[SecretDevMetaKey] = devMeta
+
+	devSessions, _ := yaml.Marshal(&a.DevSessions)
+	a.Secret.Data[SecretDevSessionKey] = devSessions
 }
 
 func (a *ApplicationMeta) IsInstalled() bool {
@@ -954,6 +1017,7 @@ func (a *ApplicationMeta) Delete() error {
 	a.PostDeleteManifest = ""
 	a.Manifest = ""
 	a.DevMeta = map[base.SvcType]map[string]string{}
+	a.DevSessions = ApplicationDevSessions{}
 	a.UninstallBackOff = time.Now().Add(time.Second * 10).UnixNano()
 
 	return a.Update()
@@ -963,6 +1027,12 @@ func (a *ApplicationMeta) GetCurrentDevModeTypeOfWorkload(workloadName string, w
 	if a.CheckIfSvcDeveloping(workloadName, identifier, workloadType, profile2.DuplicateDevMode) != NONE {
 		return profile2.DuplicateDevMode
 	}
+	if a.CheckIfSvcDeveloping(workloadName, identifier, workloadType, profile2.EphemeralDevMode) != NONE {
+		return profile2.EphemeralDevMode
+	}
+	if a.CheckIfSvcDeveloping(workloadName, identifier, workloadType, profile2.LocalDevMode) != NONE {
+		return profile2.LocalDevMode
+	}
 	if a.CheckIfSvcDeveloping(workloadName, identifier, workloadType, profile2.ReplaceDevMode) != NONE {
 		return profile2.ReplaceDevMode
 	}
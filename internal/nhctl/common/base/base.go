@@ -9,6 +9,9 @@ type DevModeAction struct {
 	ScalePatches    []PatchItem `json:"scalePatches" yaml:"scalePatches"`
 	PodTemplatePath string      `json:"podTemplatePath" yaml:"podTemplatePath"`
 	Create          bool        `json:"create" yaml:"create"`
+	// RollbackPatches undo what ScalePatches did before the original manifest is applied,
+	// applying it only merges the fields it contains, so the ones it omits would stay patched
+	RollbackPatches []PatchItem `json:"rollbackPatches,omitempty" yaml:"rollbackPatches,omitempty"`
 }
 
 type CrdDevModeAction struct {
@@ -23,6 +23,7 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"time"
 )
 
 var (
@@ -36,6 +37,9 @@ var (
 	Port         = "Port"
 	Container    = "Container"
 	Language     = "Language"
+	Duration     = "Duration"
+
+	supportedLanguages = []string{"node", "java", "go", "python", "php", "ruby"}
 
 	SUPPORT_SC = "NOCALHOST_SUPPORT_SC"
 	CONTAINERS = "NOCALHOST_CONTAINERS"
@@ -53,6 +57,7 @@ func init() {
 	_ = validate.RegisterValidationWithErrorMsg(Port, PortCheck)
 	_ = validate.RegisterValidationWithErrorMsg(Container, ContainerCheck)
 	_ = validate.RegisterValidationWithErrorMsg(Language, LanguageCheck)
+	_ = validate.RegisterValidationWithErrorMsg(Duration, IsDuration)
 
 	validate.RegisterTagNameFunc(
 		func(field reflect.StructField) string {
@@ -121,9 +126,12 @@ func IsSyncType(fl validator.FieldLevel) string {
 		val == "" ||
 			val == _const.DefaultSyncType ||
 			val == _const.SendOnlySyncType ||
-			val == _const.SendOnlySyncTypeAlias,
+			val == _const.SendOnlySyncTypeAlias ||
+			val == _const.ExecSyncType,
 		func() string {
-			return fmt.Sprintf("Must be %s or %s", _const.DefaultSyncType, _const.SendOnlySyncTypeAlias)
+			return fmt.Sprintf(
+				"Must be %s, %s or %s", _const.DefaultSyncType, _const.SendOnlySyncTypeAlias, _const.ExecSyncType,
+			)
 		},
 	)
 }
@@ -155,6 +163,29 @@ func IsQuantity(fl validator.FieldLevel) string {
 	)
 }
 
+// IsDuration checks the field is a positive duration, with param `dependency` the field is an item of
+// DependLabelSelector, only the duration of its profile.DependencyTimeoutSuffix is checked
+func IsDuration(fl validator.FieldLevel) string {
+	val := fl.Field().String()
+	if fl.Param() == "dependency" {
+		i := strings.LastIndex(val, profile.DependencyTimeoutSuffix)
+		if i < 0 {
+			return ""
+		}
+		val = val[i+len(profile.DependencyTimeoutSuffix):]
+	}
+	if val == "" {
+		return ""
+	}
+
+	d, err := time.ParseDuration(val)
+	return hintIfNoPass(
+		err == nil && d > 0, func() string {
+			return fmt.Sprintf("%s is not a positive duration, such as 30s, 5m", val)
+		},
+	)
+}
+
 func StorageClassSupported(fl validator.FieldLevel) string {
 	val := fl.Field().String()
 
@@ -189,12 +220,11 @@ func LanguageCheck(fl validator.FieldLevel) string {
 		return ""
 	}
 
-	languages := []string{"node", "java", "go", "python", "php", "ruby"}
-	set := sets.NewString(languages...)
+	set := sets.NewString(supportedLanguages...)
 	return hintIfNoPass(
 		set.Has(val),
 		func() string {
-			return fmt.Sprintf("language %s is unsupported, only %v supported", val, languages)
+			return fmt.Sprintf("language %s is unsupported, only %v supported", val, supportedLanguages)
 		},
 	)
 }
@@ -0,0 +1,185 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package config_validate
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+	"nocalhost/internal/nhctl/profile"
+	customyaml3 "nocalhost/pkg/nhctl/utils/custom_yaml_v3"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FieldError an error of the config file, Line and Column are 0 if the position is unknown
+type FieldError struct {
+	Line   int
+	Column int
+	Field  string
+	Msg    string
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Msg
+	}
+	return fmt.Sprintf("Error on field '%s', %s", e.Field, e.Msg)
+}
+
+var yamlErrLineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// ValidateConfigContent validates a rendered config, which may be an application config,
+// a service config or a list of service configs. Rules depending on the cluster are skipped
+func ValidateConfigContent(content []byte) ([]*FieldError, error) {
+	// Make sure no cluster-dependent condition prepared before is used
+	_ = os.Unsetenv(SUPPORT_SC)
+	_ = os.Unsetenv(CONTAINERS)
+
+	doc := customyaml3.Node{}
+	if err := customyaml3.Unmarshal(content, &doc); err != nil {
+		return []*FieldError{yamlFieldError(err.Error())}, nil
+	}
+	if len(doc.Content) == 0 {
+		return nil, errors.New("Config is empty")
+	}
+	root := doc.Content[0]
+
+	services := make([]*serviceNode, 0)
+	switch {
+	case root.Kind == customyaml3.SequenceNode:
+		for i, n := range root.Content {
+			services = append(services, &serviceNode{prefix: fmt.Sprintf("[%d]", i), node: n})
+		}
+	case mappingValue(root, "application") != nil || mappingValue(root, "configProperties") != nil:
+		if version := mappingValue(mappingValue(root, "configProperties"), "version"); version != nil &&
+			version.Value == "v1" {
+			return nil, errors.New("Config of v1 is not supported, please migrate it to v2")
+		}
+		if svcNodes := mappingValue(mappingValue(root, "application"), "services"); svcNodes != nil {
+			for i, n := range svcNodes.Content {
+				services = append(
+					services, &serviceNode{prefix: fmt.Sprintf("application.services[%d]", i), node: n},
+				)
+			}
+		}
+		config := profile.NocalHostAppConfigV2{}
+		if err := root.Decode(&config); err != nil {
+			return decodeFieldErrors(err), nil
+		}
+	default:
+		services = append(services, &serviceNode{node: root})
+	}
+
+	result := make([]*FieldError, 0)
+	for _, svc := range services {
+		svcConfig := profile.ServiceConfigV2{}
+		if err := svc.node.Decode(&svcConfig); err != nil {
+			result = append(result, decodeFieldErrors(err)...)
+			continue
+		}
+		for _, fe := range validationErrors(&svcConfig) {
+			// Namespace starts with the struct name
+			path := fe.Namespace()
+			if i := strings.Index(path, "."); i >= 0 {
+				path = path[i+1:]
+			}
+			pos := nodeOfPath(svc.node, path)
+			hint := fe.Msg()
+			if hint == "" {
+				hint = fmt.Sprintf("failed on the '%s' tag", fe.Tag())
+			}
+			result = append(
+				result, &FieldError{
+					Line:   pos.Line,
+					Column: pos.Column,
+					Field:  strings.TrimPrefix(svc.prefix+"."+path, "."),
+					Msg:    fmt.Sprintf("value '%v', hint: %s", fe.Value(), hint),
+				},
+			)
+		}
+	}
+	return result, nil
+}
+
+type serviceNode struct {
+	prefix string
+	node   *customyaml3.Node
+}
+
+func validationErrors(s *profile.ServiceConfigV2) validator.ValidationErrors {
+	if errs, ok := validate.Struct(s).(validator.ValidationErrors); ok {
+		return errs
+	}
+	return nil
+}
+
+func decodeFieldErrors(err error) []*FieldError {
+	if te, ok := err.(*customyaml3.TypeError); ok {
+		result := make([]*FieldError, 0, len(te.Errors))
+		for _, e := range te.Errors {
+			result = append(result, yamlFieldError(e))
+		}
+		return result
+	}
+	return []*FieldError{yamlFieldError(err.Error())}
+}
+
+func yamlFieldError(msg string) *FieldError {
+	if match := yamlErrLineRegex.FindStringSubmatch(msg); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return &FieldError{Line: line, Msg: match[2]}
+	}
+	return &FieldError{Msg: msg}
+}
+
+func mappingValue(node *customyaml3.Node, key string) *customyaml3.Node {
+	if node == nil || node.Kind != customyaml3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+var (
+	pathSegmentRegex = regexp.MustCompile(`^([^\[]*)((?:\[\d+\])*)$`)
+	pathIndexRegex   = regexp.MustCompile(`\d+`)
+)
+
+// nodeOfPath finds the node of field path such as containers[0].dev.portForward[1],
+// the deepest node found is returned if the field is not set in the config
+func nodeOfPath(node *customyaml3.Node, path string) *customyaml3.Node {
+	if path == "" {
+		return node
+	}
+	for _, segment := range strings.Split(path, ".") {
+		match := pathSegmentRegex.FindStringSubmatch(segment)
+		if match == nil {
+			return node
+		}
+		if match[1] != "" {
+			next := mappingValue(node, match[1])
+			if next == nil {
+				return node
+			}
+			node = next
+		}
+		for _, index := range pathIndexRegex.FindAllString(match[2], -1) {
+			i, _ := strconv.Atoi(index)
+			if node.Kind != customyaml3.SequenceNode || i >= len(node.Content) {
+				return node
+			}
+			node = node.Content[i]
+		}
+	}
+	return node
+}
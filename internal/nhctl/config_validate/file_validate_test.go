@@ -0,0 +1,100 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package config_validate
+
+import (
+	"encoding/json"
+	"nocalhost/internal/nhctl/profile"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigContent(t *testing.T) {
+	content := `configProperties:
+  version: v2
+application:
+  name: bookinfo
+  services:
+    - name: reviews
+      serviceType: deployment
+      containers:
+        - name: reviews
+          dev:
+            portForward:
+              - 9080
+              - "a:b"
+            sync:
+              type: both
+`
+	fieldErrors, err := ValidateConfigContent([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fieldErrors) != 2 {
+		t.Fatalf("expect 2 errors, but got %d", len(fieldErrors))
+	}
+	for _, fe := range fieldErrors {
+		switch {
+		case strings.HasSuffix(fe.Field, "dev.portForward[1]"):
+			if fe.Line != 13 || fe.Column != 17 {
+				t.Errorf("unexpected position %d:%d of %s", fe.Line, fe.Column, fe.Field)
+			}
+		case strings.HasSuffix(fe.Field, "dev.sync.type"):
+			if fe.Line != 15 || fe.Column != 21 {
+				t.Errorf("unexpected position %d:%d of %s", fe.Line, fe.Column, fe.Field)
+			}
+		default:
+			t.Errorf("unexpected error %s", fe.Error())
+		}
+	}
+
+	fieldErrors, err = ValidateConfigContent([]byte("name: reviews\ncontainers: reviews\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fieldErrors) != 1 || fieldErrors[0].Line != 2 {
+		t.Fatalf("type error of line 2 should be reported, but got %v", fieldErrors)
+	}
+
+	fieldErrors, err = ValidateConfigContent(
+		[]byte("name: reviews\nserviceType: deployment\ndependLabelSelector:\n  grpc:\n    - ratings:9080\n  timeout: 5\n"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fieldErrors) != 1 || fieldErrors[0].Field != "dependLabelSelector.timeout" || fieldErrors[0].Line != 6 {
+		t.Fatalf("invalid timeout of line 6 should be reported, but got %v", fieldErrors)
+	}
+
+	fieldErrors, err = ValidateConfigContent(
+		[]byte(
+			"name: reviews\nserviceType: deployment\ndependLabelSelector:\n  grpc:\n    - ratings:9080 timeout=30s\n" +
+				"    - details:9080 timeout=5\n  timeout: 1m\n",
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fieldErrors) != 1 || fieldErrors[0].Field != "dependLabelSelector.grpc[1]" || fieldErrors[0].Line != 6 {
+		t.Fatalf("invalid timeout of the dependency in line 6 should be reported, but got %v", fieldErrors)
+	}
+}
+
+func TestGenerateSchema(t *testing.T) {
+	schema := GenerateSchema(profile.ServiceConfigV2{}, "")
+	bys, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(bys)
+	for _, expected := range []string{
+		`"required":["name","serviceType"]`, `"$ref":"#/definitions/ContainerDevConfig"`, `"sendReceive"`,
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("%s not found in schema", expected)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package config_validate
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	_const "nocalhost/internal/nhctl/const"
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schemaOfTags translates custom validate tags to JSON Schema keywords, rules depending on
+// the cluster such as StorageClass and Container can not be expressed
+var schemaOfTags = map[string]map[string]interface{}{
+	SyncType: {
+		"enum": []string{
+			"", _const.DefaultSyncType, _const.SendOnlySyncType, _const.SendOnlySyncTypeAlias, _const.ExecSyncType,
+		},
+	},
+	SyncMode: {"enum": []string{"", _const.PatternMode, _const.GitIgnoreMode}},
+	Language: {"enum": append([]string{""}, supportedLanguages...)},
+	Port:     {"minimum": 0, "maximum": 65535},
+	Quantity: {
+		"pattern":     `^$|^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$`,
+		"description": "Quantity of k8s, such as 500m, 1Gi",
+	},
+	PortForward: {"description": "localPort:remotePort, or port if they are the same"},
+	DNS1123:     {"pattern": `^$|^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`, "maxLength": 63},
+	Duration:    {"pattern": `^$|^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`, "description": "Duration, such as 30s, 5m"},
+}
+
+// schemaOfTypes types whose values are restricted more than their kinds
+var schemaOfTypes = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(corev1.PullPolicy("")): {
+		"type": "string",
+		"enum": []corev1.PullPolicy{"", corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent},
+	},
+}
+
+// GenerateSchema generates JSON Schema of the config from its go type,
+// struct types are put in definitions and referred by name
+func GenerateSchema(config interface{}, title string) map[string]interface{} {
+	g := &schemaGenerator{definitions: map[string]interface{}{}}
+	schema := g.schemaOf(reflect.TypeOf(config))
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = title
+	schema["definitions"] = g.definitions
+	return schema
+}
+
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if s, ok := schemaOfTypes[t]; ok {
+		return copySchema(s)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := g.definitions[t.Name()]; !ok {
+			// placeholder for recursive types
+			g.definitions[t.Name()] = nil
+			g.definitions[t.Name()] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// interface{} accepts anything
+		return map[string]interface{}{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fieldSchema := g.schemaOf(field.Type)
+		for _, tag := range strings.Split(field.Tag.Get("validate"), ",") {
+			if tag == "required" {
+				required = append(required, name)
+				continue
+			}
+			if s, ok := schemaOfTags[tag]; ok {
+				target := fieldSchema
+				// tags after dive apply to items
+				if items, ok := fieldSchema["items"].(map[string]interface{}); ok {
+					target = items
+				}
+				for k, v := range s {
+					target[k] = v
+				}
+			}
+		}
+		properties[name] = fieldSchema
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func copySchema(s map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(s))
+	for k, v := range s {
+		c[k] = v
+	}
+	return c
+}
@@ -22,6 +22,7 @@ const (
 	NocalhostApplicationName         = "dev.nocalhost/application-name"
 	NocalhostApplicationNamespace    = "dev.nocalhost/application-namespace"
 	NocalhostDevContainerAnnotations = "dev.nocalhost/nocalhost-dev"
+	NocalhostDevSidecarAnnotations   = "dev.nocalhost/nocalhost-sidecar"
 
 	NocalhostDefaultDevContainerName = "nocalhost-dev"
 	NocalhostDefaultDevSidecarName   = "nocalhost-sidecar"
@@ -38,11 +39,22 @@ const (
 	ServiceTypeLabel         = "nocalhost.dev/service-type"
 	AppLabel                 = "nocalhost.dev/app"
 
+	// VolumeSnapshots of pvcs created for persistentVolumeDirs are grouped by this label
+	PersistentVolumeSnapshotLabel          = "nocalhost.dev/snapshot"
+	PersistentVolumeCapacityAnnotation     = "nocalhost.dev/capacity"
+	PersistentVolumeStorageClassAnnotation = "nocalhost.dev/storage-class"
+
 	DefaultSideCarImage = "10.155.97.245/k8s/nocalhost-sidecar:syncthing"
 	SSHSideCarImage     = "10.155.97.245/k8s/nocalhost-sidecar:sshversion"
 	DefaultVPNImage     = "10.155.97.245/k8s/nocalhost-vpn:v1"
 
 	DefaultApplicationSyncPidFile = "syncthing.pid"
+	// exec sync engine runs in a nhctl process, which reports its status to exec-sync.json
+	DefaultExecSyncPidFile    = "exec-sync.pid"
+	DefaultExecSyncStatusFile = "exec-sync.json"
+	DefaultExecSyncLogFile    = "exec-sync.log"
+	// terminal-${pid}.pid is created in sync dir while a terminal of DevContainer is open
+	DefaultTerminalPidFilePrefix = "terminal-"
 
 	EnableFullLogEnvKey = "NH_FULL_LOG"
 
@@ -73,8 +85,15 @@ const (
 	NocalhostViewerRoleBinding = "nocalhost-viewer-role-binding"
 	NocalhostViewerRoleName    = "nocalhost-viewer-role"
 
+	// deprecated, replicas of hpa are recorded in the workload by NocalhostSuspendedHPAAnnotation now
 	HPAOriginalMaxReplicasKey = "nocalhost.dev.hpa.origin.max.replicas"
 	HPAOriginalMinReplicasKey = "nocalhost.dev.hpa.origin.min.replicas"
+	// recorded in workload while in DevMode, restore hpa by it while DevMode ends
+	NocalhostSuspendedHPAAnnotation = "dev.nocalhost/suspended-hpa"
+	// replace DevMode is ended by nocalhost-dep if no heartbeat is received within the ttl,
+	// ttl recorded in namespace is the default of the dev space, which is set by nocalhost-api
+	DevModeTTLAnnotation       = "dev.nocalhost/dev-mode-ttl"
+	DevModeHeartbeatAnnotation = "dev.nocalhost/dev-mode-heartbeat"
 
 	// sycnthing
 
@@ -82,6 +101,8 @@ const (
 	DefaultSyncType       = "sendReceive" // default sync mode
 	SendOnlySyncType      = "sendonly"
 	SendOnlySyncTypeAlias = "send"
+	// ExecSyncType files are sent by tar over the exec api of k8s, no syncthing is needed
+	ExecSyncType = "exec"
 
 	// sync mode
 	GitIgnoreMode = "gitIgnore"
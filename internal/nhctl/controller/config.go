@@ -74,6 +74,15 @@ func (c *Controller) GetPersistentVolumeDirs(container string) []*profile.Persis
 	return nil
 }
 
+// GetVolumeSnapshot returns name of snapshots taken by `nhctl pvc snapshot` to seed pvcs being created
+func (c *Controller) GetVolumeSnapshot(container string) string {
+	devConfig := c.config.GetContainerDevConfigOrDefault(container)
+	if devConfig != nil {
+		return devConfig.VolumeSnapshot
+	}
+	return ""
+}
+
 func (c *Controller) GetImagePullPolicy(container string) corev1.PullPolicy {
 	devConfig := c.config.GetContainerDevConfigOrDefault(container)
 	if devConfig != nil && devConfig.Image != "" {
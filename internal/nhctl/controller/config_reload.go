@@ -0,0 +1,157 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"nocalhost/internal/nhctl/profile"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/pkg/nhctl/log"
+	"reflect"
+)
+
+const ConfigReloadedReason = "NocalhostConfigReloaded"
+
+// ApplyLiveConfigChanges applies port-forwards and sync patterns changed from `from` to the
+// running DevMode of this device, other changes take effect after DevMode is restarted
+func (c *Controller) ApplyLiveConfigChanges(from *profile.ServiceConfigV2) error {
+	if !c.IsInDevMode() || !c.IsProcessor() {
+		return nil
+	}
+	svcProfile, err := c.GetProfile()
+	if err != nil {
+		return err
+	}
+	container := svcProfile.OriginDevContainer
+	fromDev := from.GetContainerDevConfigOrDefault(container)
+	toDev := c.Config().GetContainerDevConfigOrDefault(container)
+	if fromDev == nil || toDev == nil {
+		return nil
+	}
+
+	added, removed := diffPortForward(fromDev.PortForward, toDev.PortForward)
+	for _, pf := range removed {
+		lPort, rPort, err := utils.GetPortForwardForString(pf)
+		if err != nil {
+			continue
+		}
+		log.Infof("Stopping port-forward %d:%d removed from config", lPort, rPort)
+		utils.Should(c.EndDevPortForward(lPort, rPort))
+	}
+	if len(added) > 0 {
+		podName, err := c.GetDevModePodName()
+		if err != nil {
+			return err
+		}
+		for _, pf := range added {
+			lPort, rPort, err := utils.GetPortForwardForString(pf)
+			if err != nil {
+				log.WarnE(err, "")
+				continue
+			}
+			log.Infof("Forwarding %d:%d added to config", lPort, rPort)
+			utils.Should(c.PortForward(podName, lPort, rPort, ""))
+		}
+	}
+
+	if svcProfile.Syncing && !syncPatternsEqual(fromDev.Sync, toDev.Sync) {
+		return c.ReloadSyncIgnores(container, svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin)
+	}
+	return nil
+}
+
+// LiveConfigContainers returns names of the container configs whose changes ApplyLiveConfigChanges
+// applies, that is the container in DevMode of this device
+func (c *Controller) LiveConfigContainers() []string {
+	if !c.IsInDevMode() || !c.IsProcessor() {
+		return nil
+	}
+	svcProfile, err := c.GetProfile()
+	if err != nil {
+		log.WarnE(err, "Failed to get profile")
+		return nil
+	}
+	return []string{c.Config().GetContainerConfigNameOrDefault(svcProfile.OriginDevContainer)}
+}
+
+// ReloadSyncIgnores regenerates ignored patterns of the running file sync from config and .gitignore files
+func (c *Controller) ReloadSyncIgnores(container string, localSyncDir []string) error {
+	if c.IsExecSync(container) {
+		// exec sync process reads patterns while starting
+		log.Infof("Restarting exec sync process to apply sync patterns changed")
+		if err := c.StopExecSyncProcess(); err != nil {
+			return err
+		}
+		return c.StartExecSyncProcess(container, c.Client.KubeConfigFilePath())
+	}
+	s, err := c.NewSyncthing(container, localSyncDir, false)
+	if err != nil {
+		return err
+	}
+	if err = s.ReloadIgnoredFileConfig(); err != nil {
+		return err
+	}
+	// rescan to apply the new patterns now
+	if err = c.NewSyncthingHttpClient(2).Scan(); err != nil {
+		log.WarnE(err, "Failed to rescan files after sync patterns changed")
+	}
+	return nil
+}
+
+// RecordConfigReloadedEvent records an event to the workload with fields changed
+func (c *Controller) RecordConfigReloadedEvent(changes *profile.SvcConfigChanges) error {
+	u, err := c.GetUnstructured()
+	if err != nil {
+		return err
+	}
+	ref := &corev1.ObjectReference{
+		Kind:            u.GetKind(),
+		APIVersion:      u.GetAPIVersion(),
+		Name:            u.GetName(),
+		Namespace:       u.GetNamespace(),
+		UID:             u.GetUID(),
+		ResourceVersion: u.GetResourceVersion(),
+	}
+	return errors.Wrap(
+		c.Client.CreateEvent(
+			ref, corev1.EventTypeNormal, ConfigReloadedReason,
+			fmt.Sprintf("Nocalhost config of %s reloaded, %s", c.Name, changes.String()),
+		), "",
+	)
+}
+
+// diffPortForward returns port-forwards added and removed
+func diffPortForward(from, to []string) ([]string, []string) {
+	fromMap, toMap := map[string]bool{}, map[string]bool{}
+	for _, pf := range from {
+		fromMap[pf] = true
+	}
+	for _, pf := range to {
+		toMap[pf] = true
+	}
+	added, removed := make([]string, 0), make([]string, 0)
+	for _, pf := range to {
+		if !fromMap[pf] {
+			added = append(added, pf)
+		}
+	}
+	for _, pf := range from {
+		if !toMap[pf] {
+			removed = append(removed, pf)
+		}
+	}
+	return added, removed
+}
+
+func syncPatternsEqual(from, to *profile.SyncConfig) bool {
+	if from == nil || to == nil {
+		return from == to
+	}
+	return reflect.DeepEqual(from.FilePattern, to.FilePattern) &&
+		reflect.DeepEqual(from.IgnoreFilePattern, to.IgnoreFilePattern) && from.UseGitIgnore == to.UseGitIgnore
+}
@@ -8,11 +8,21 @@ package controller
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	_const "nocalhost/internal/nhctl/const"
+	"nocalhost/internal/nhctl/nocalhost"
 	"nocalhost/internal/nhctl/profile"
 	"nocalhost/pkg/nhctl/clientgoutils"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestIsResourcesLimitToLow(t *testing.T) {
@@ -53,3 +63,460 @@ func TestDeploymentController_ReplaceImage(t *testing.T) {
 
 	fmt.Printf("%v\n", podSpec)
 }
+
+func TestFindEphemeralDevPodName(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "reviews-0",
+			Annotations: map[string]string{
+				_const.NocalhostDevContainerAnnotations: "nocalhost-dev-abc",
+				_const.NocalhostDevSidecarAnnotations:   "nocalhost-sidecar-abc",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "reviews"}},
+			EphemeralContainers: []corev1.EphemeralContainer{
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "nocalhost-dev-abc"}},
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "nocalhost-sidecar-abc"}},
+			},
+		},
+	}
+	if _, err := findDevPodName(pod); err == nil {
+		t.Fatal("ephemeral containers are not running, dev pod should not be ready")
+	}
+
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{
+		{Name: "nocalhost-dev-abc", State: running},
+		{Name: "nocalhost-sidecar-abc", State: running},
+	}
+	name, err := findDevPodName(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != pod.Name {
+		t.Fatalf("expect %s, but got %s", pod.Name, name)
+	}
+}
+
+func TestSecretVolumesToEnv(t *testing.T) {
+	c := &Controller{Name: "reviews", Type: "deployment"}
+	envs, cmd := secretVolumesToEnv(c.generateSyncVolumesAndMounts(false))
+	if len(envs) != 3 {
+		t.Fatalf("expect 3 envs, but got %d", len(envs))
+	}
+	for _, env := range envs {
+		if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef.Name != c.GetSyncThingSecretName() {
+			t.Fatalf("env %s does not reference syncthing secret", env.Name)
+		}
+	}
+	expect := "mkdir -p /var/syncthing/secret" +
+		` && printf '%s' "$NOCALHOST_NOCALHOST_SYNCTHING_SECRET_CONFIG_XML" > /var/syncthing/secret/config.xml` +
+		` && printf '%s' "$NOCALHOST_NOCALHOST_SYNCTHING_SECRET_CERT_PEM" > /var/syncthing/secret/cert.pem` +
+		` && printf '%s' "$NOCALHOST_NOCALHOST_SYNCTHING_SECRET_KEY_PEM" > /var/syncthing/secret/key.pem`
+	if cmd != expect {
+		t.Fatalf("expect command %q, but got %q", expect, cmd)
+	}
+}
+
+func TestEphemeralSidecarCommand(t *testing.T) {
+	for _, c := range []struct {
+		restore, command, expect string
+	}{
+		{
+			"mkdir -p /a", "syncthing",
+			"mkdir -p /a || exit 1; (syncthing) & while [ ! -f /tmp/nocalhost-dev-end ]; do sleep 1; done",
+		},
+		{"", "syncthing", "(syncthing) & while [ ! -f /tmp/nocalhost-dev-end ]; do sleep 1; done"},
+		{"", "", "while [ ! -f /tmp/nocalhost-dev-end ]; do sleep 1; done"},
+	} {
+		if cmd := ephemeralSidecarCommand(c.restore, c.command); cmd != c.expect {
+			t.Errorf("expect command %q, but got %q", c.expect, cmd)
+		}
+	}
+}
+
+func TestApplyPatchLocally(t *testing.T) {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "reviews"},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{map[string]interface{}{"name": "reviews", "image": "reviews:v1"}},
+					},
+				},
+			},
+		},
+	}
+
+	if err := applyPatchLocally(u, `[{"op":"replace","path":"/spec/replicas","value":1}]`, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyPatchLocally(u, "metadata:\n  annotations:\n    a: b", "merge"); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyPatchLocally(
+		u, `{"spec":{"template":{"spec":{"containers":[{"name":"reviews","image":"reviews:dev"}]}}}}`, "strategic",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	replicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+	containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	if replicas != 1 || u.GetAnnotations()["a"] != "b" || len(containers) != 1 ||
+		containers[0].(map[string]interface{})["image"] != "reviews:dev" {
+		t.Fatalf("unexpected result %v", u.Object)
+	}
+
+	u.SetKind("Rollout")
+	u.SetAPIVersion("argoproj.io/v1alpha1")
+	if err := applyPatchLocally(u, `{"spec":{"replicas":2}}`, "strategic"); err == nil {
+		t.Fatal("strategic merge patch should not be supported for CRD")
+	}
+}
+
+func TestDryRunPatchesLocally(t *testing.T) {
+	live := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "reviews"},
+			"spec":       map[string]interface{}{"replicas": int64(3)},
+		},
+	}
+	c := &Controller{Name: "reviews", Type: "deployment", AppName: "bookinfo", NameSpace: "nocalhost-test"}
+	c.dryRun = &DevModeDryRunResult{Live: live, DevMode: live.DeepCopy()}
+
+	if err := c.RecordDevModeTTL(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	generated := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: c.getGeneratedDeploymentName()}}
+	if err := c.createDeployment(generated); err != nil {
+		t.Fatal(err)
+	}
+	generatedName := c.dryRun.Generated.GetName()
+	if err := c.patchResource("deployment", generatedName, `{"spec":{"paused":true}}`, "merge"); err != nil {
+		t.Fatal(err)
+	}
+
+	annotations := c.dryRun.DevMode.GetAnnotations()
+	if annotations[_const.DevModeTTLAnnotation] != "1h0m0s" || annotations[_const.DevModeHeartbeatAnnotation] == "" {
+		t.Fatalf("ttl should be recorded in the workload: %v", annotations)
+	}
+	if paused, _, _ := unstructured.NestedBool(c.dryRun.Generated.Object, "spec", "paused"); !paused {
+		t.Fatal("patches to the generated deployment should be applied to it")
+	}
+	if len(live.GetAnnotations()) != 0 {
+		t.Fatal("live workload should not be changed")
+	}
+
+	if err := c.ClearDevModeTTL(); err != nil {
+		t.Fatal(err)
+	}
+	annotations = c.dryRun.DevMode.GetAnnotations()
+	if _, ok := annotations[_const.DevModeTTLAnnotation]; ok {
+		t.Fatalf("ttl should be removed from the workload: %v", annotations)
+	}
+	if _, ok := annotations[_const.DevModeHeartbeatAnnotation]; ok {
+		t.Fatalf("heartbeat should be removed from the workload: %v", annotations)
+	}
+}
+
+func TestSuspendedHPAOf(t *testing.T) {
+	var min int32 = 2
+	hl := []autoscalingv1.HorizontalPodAutoscaler{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews"},
+			Spec:       autoscalingv1.HorizontalPodAutoscalerSpec{MinReplicas: &min, MaxReplicas: 10},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews-no-min"},
+			Spec:       autoscalingv1.HorizontalPodAutoscalerSpec{MaxReplicas: 5},
+		},
+	}
+	bys, _ := json.Marshal(suspendedHPAOf(hl))
+
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetAnnotations(map[string]string{_const.NocalhostSuspendedHPAAnnotation: string(bys)})
+
+	suspended := make([]*profile.SuspendedHPA, 0)
+	if err := json.Unmarshal([]byte(u.GetAnnotations()[_const.NocalhostSuspendedHPAAnnotation]), &suspended); err != nil {
+		t.Fatal(err)
+	}
+	if len(suspended) != 2 || *suspended[0].MinReplicas != 2 || suspended[0].MaxReplicas != 10 ||
+		suspended[1].MinReplicas != nil || suspended[1].MaxReplicas != 5 {
+		t.Fatalf("unexpected suspended hpa %s", string(bys))
+	}
+
+	// record must not be kept in original workload definition
+	RemoveUselessInfo(u)
+	if _, ok := u.GetAnnotations()[_const.NocalhostSuspendedHPAAnnotation]; ok {
+		t.Fatal("suspended hpa record should be removed")
+	}
+}
+
+func TestSuspendedHPAToRestore(t *testing.T) {
+	// client is nil, the workload must not be read in DevMode other than replace
+	for _, modeType := range []profile.DevModeType{
+		profile.DuplicateDevMode, profile.EphemeralDevMode, profile.LocalDevMode,
+	} {
+		c := &Controller{Name: "reviews", Type: "deployment", DevModeType: modeType}
+		if suspended, restore := c.suspendedHPAToRestore(); restore || suspended != nil {
+			t.Fatalf("hpa should not be restored while ending %s DevMode", modeType)
+		}
+	}
+}
+
+func TestDevModeExpiry(t *testing.T) {
+	heartbeat := "2021-10-01T08:00:00Z"
+
+	if _, ok, err := DevModeExpiry(map[string]string{}, time.Hour); ok || err != nil {
+		t.Fatal("workload without ttl recorded should never expire")
+	}
+
+	a := map[string]string{
+		_const.DevModeTTLAnnotation:       "",
+		_const.DevModeHeartbeatAnnotation: heartbeat,
+	}
+	if _, ok, err := DevModeExpiry(a, 0); ok || err != nil {
+		t.Fatal("workload should never expire if neither workload nor dev space specifies a ttl")
+	}
+	expiry, ok, err := DevModeExpiry(a, time.Hour)
+	if err != nil || !ok || expiry.Format(time.RFC3339) != "2021-10-01T09:00:00Z" {
+		t.Fatalf("ttl of dev space should be used, but got %s %v %v", expiry, ok, err)
+	}
+
+	a[_const.DevModeTTLAnnotation] = "30m"
+	expiry, ok, err = DevModeExpiry(a, time.Hour)
+	if err != nil || !ok || expiry.Format(time.RFC3339) != "2021-10-01T08:30:00Z" {
+		t.Fatalf("ttl of workload should be used, but got %s %v %v", expiry, ok, err)
+	}
+
+	a[_const.DevModeHeartbeatAnnotation] = "yesterday"
+	if _, _, err = DevModeExpiry(a, time.Hour); err == nil {
+		t.Fatal("invalid heartbeat should be reported")
+	}
+}
+
+func TestFormatEnvFile(t *testing.T) {
+	envs := sortedEnv(
+		map[string]string{
+			"HOST":  "db",
+			"URL":   expandEnvRef("mysql://$(HOST):3306/$(UNKNOWN)", map[string]string{"HOST": "db"}),
+			"MOTD":  "hello\nworld",
+			"EMPTY": "",
+		},
+	)
+	mounts := map[string]string{"/etc/config": "/tmp/nocalhost/etc/config"}
+
+	expected := localEnvFileHeader + "\n" +
+		"# /etc/config -> /tmp/nocalhost/etc/config\n" +
+		"EMPTY=\"\"\n" +
+		"HOST=db\n" +
+		"MOTD=\"hello\\nworld\"\n" +
+		"URL=\"mysql://db:3306/$(UNKNOWN)\"\n"
+	if s := formatEnvFile(localEnvFileHeader, envs, mounts); s != expected {
+		t.Fatalf("unexpected env file:\n%s", s)
+	}
+}
+
+func TestExportVolumesToNonEmptyDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nhctl-env-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	userFile := filepath.Join(dir, "main.go")
+	if err = ioutil.WriteFile(userFile, []byte("package main"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+	ctn := &corev1.Container{VolumeMounts: []corev1.VolumeMount{{Name: "cache", MountPath: "/cache"}}}
+	c := &Controller{}
+
+	if _, err = c.exportVolumes(pod, ctn, dir, false); err == nil {
+		t.Fatal("non-empty dir should be refused without force")
+	}
+	if _, err = c.exportVolumes(pod, ctn, dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := ioutil.ReadFile(userFile); err != nil || string(content) != "package main" {
+		t.Fatalf("file in volumes dir should be kept, content: %q, err: %v", content, err)
+	}
+}
+
+func TestPatchInitDevContainerToPodSpec(t *testing.T) {
+	podSpec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "wait-db"}, {Name: "migrate"}},
+		Containers:     []corev1.Container{{Name: "reviews"}},
+	}
+	devContainer := &corev1.Container{Name: _const.NocalhostDefaultDevContainerName}
+	sidecar := &corev1.Container{Name: _const.NocalhostDefaultDevSidecarName}
+	volume := convertToInitDevContainers(devContainer, sidecar)
+	patchDevContainerToPodSpec(podSpec, "migrate", devContainer, sidecar, []corev1.Volume{volume})
+
+	names := make([]string, 0)
+	for _, c := range podSpec.InitContainers {
+		names = append(names, c.Name)
+	}
+	expected := []string{"wait-db", _const.NocalhostDefaultDevSidecarName, _const.NocalhostDefaultDevContainerName}
+	if fmt.Sprint(names) != fmt.Sprint(expected) {
+		t.Fatalf("expect init containers %v, but got %v", expected, names)
+	}
+	if len(podSpec.Containers) != 1 || podSpec.Containers[0].Name != "reviews" {
+		t.Fatal("app containers should not be changed")
+	}
+
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "reviews-0"}, Spec: *podSpec}
+	if _, err := findDevPodName(pod); err == nil {
+		t.Fatal("init DevContainer is not running, dev pod should not be ready")
+	}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name:  _const.NocalhostDefaultDevContainerName,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		},
+	}
+	if name, err := findDevPodName(pod); err != nil || name != pod.Name {
+		t.Fatalf("expect %s, but got %s %v", pod.Name, name, err)
+	}
+}
+
+func TestExecSyncDevPodWithoutSidecar(t *testing.T) {
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "reviews"}}}
+	devContainer := &corev1.Container{Name: _const.NocalhostDefaultDevContainerName}
+	patchDevContainerToPodSpec(podSpec, "reviews", devContainer, nil, nil)
+	if len(podSpec.Containers) != 1 || podSpec.Containers[0].Name != _const.NocalhostDefaultDevContainerName {
+		t.Fatalf("no sidecar should be added in exec sync, containers: %v", podSpec.Containers)
+	}
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "reviews-0",
+			Annotations: map[string]string{
+				_const.NocalhostDevContainerAnnotations: _const.NocalhostDefaultDevContainerName,
+				_const.NocalhostDevSidecarAnnotations:   _const.NocalhostDefaultDevContainerName,
+			},
+		},
+		Spec: *podSpec,
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: _const.NocalhostDefaultDevContainerName, Ready: true}},
+		},
+	}
+	if name, err := findDevPodName(pod); err != nil || name != pod.Name {
+		t.Fatalf("expect %s, but got %s %v", pod.Name, name, err)
+	}
+}
+
+func TestArgoRolloutDevModeAction(t *testing.T) {
+	da, err := nocalhost.GetDevModeActionBySvcType("rollouts.v1alpha1.argoproj.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Rollout",
+			"metadata":   map[string]interface{}{"name": "reviews"},
+			"spec":       map[string]interface{}{"replicas": int64(3)},
+		},
+	}
+
+	for _, item := range da.ScalePatches {
+		if err = applyPatchLocally(u, item.Patch, item.Type); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if paused, _, _ := unstructured.NestedBool(u.Object, "spec", "paused"); !paused {
+		t.Fatal("rollout should be paused in DevMode")
+	}
+	if replicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas"); replicas != 0 {
+		t.Fatal("rollout should be scaled down in DevMode")
+	}
+
+	for _, item := range da.RollbackPatches {
+		if err = applyPatchLocally(u, item.Patch, item.Type); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if paused, _, _ := unstructured.NestedBool(u.Object, "spec", "paused"); paused {
+		t.Fatal("rollout should be resumed after rolling back")
+	}
+}
+
+func TestKnativeServiceDevModeAction(t *testing.T) {
+	da, err := nocalhost.GetDevModeActionBySvcType("services.v1.serving.knative.dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "serving.knative.dev/v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": "hello"},
+			"spec":       map[string]interface{}{},
+			"status":     map[string]interface{}{"latestReadyRevisionName": "hello-00002"},
+		},
+	}
+
+	for _, item := range da.ScalePatches {
+		patch, err := renderPatch(item.Patch, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = applyPatchLocally(u, patch, item.Type); err != nil {
+			t.Fatal(err)
+		}
+	}
+	traffic, _, _ := unstructured.NestedSlice(u.Object, "spec", "traffic")
+	if len(traffic) != 1 || traffic[0].(map[string]interface{})["revisionName"] != "hello-00002" ||
+		traffic[0].(map[string]interface{})["latestRevision"] != false {
+		t.Fatalf("traffic should be pinned to the latest ready revision in DevMode, got %v", traffic)
+	}
+
+	for _, item := range da.RollbackPatches {
+		if err = applyPatchLocally(u, item.Patch, item.Type); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, found, _ := unstructured.NestedSlice(u.Object, "spec", "traffic"); found {
+		t.Fatal("traffic should follow the latest revision again after rolling back")
+	}
+
+	// a service without ready revision can not be pinned
+	unstructured.RemoveNestedField(u.Object, "status")
+	if _, err = renderPatch(da.ScalePatches[0].Patch, u); err == nil {
+		t.Fatal("rendering should fail without latest ready revision")
+	}
+}
+
+func TestSnapshotPVCSpec(t *testing.T) {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	snapshot.SetAnnotations(
+		map[string]string{
+			_const.PersistentVolumeCapacityAnnotation:     "10Gi",
+			_const.PersistentVolumeStorageClassAnnotation: "cbs",
+		},
+	)
+	capacity, storageClass := snapshotPVCSpec(snapshot)
+	if capacity != "10Gi" || storageClass == nil || *storageClass != "cbs" {
+		t.Fatalf("unexpected pvc spec %s %v", capacity, storageClass)
+	}
+
+	// pvc can not be smaller than the snapshot
+	_ = unstructured.SetNestedField(snapshot.Object, "20Gi", "status", "restoreSize")
+	if capacity, _ = snapshotPVCSpec(snapshot); capacity != "20Gi" {
+		t.Fatalf("capacity should be restore size of the snapshot, but got %s", capacity)
+	}
+}
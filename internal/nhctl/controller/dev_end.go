@@ -6,6 +6,7 @@
 package controller
 
 import (
+	"nocalhost/internal/nhctl/profile"
 	"nocalhost/internal/nhctl/utils"
 	"nocalhost/pkg/nhctl/log"
 	"os"
@@ -19,6 +20,9 @@ func (c *Controller) DevEnd(reset bool) error {
 		log.WarnE(err, "StopSyncAndPortForwardProcess failed")
 	}
 
+	// Read before rolling back, the record may be removed with DevMode manifest
+	suspendedHPA, restoreHPA := c.suspendedHPAToRestore()
+
 	if err := c.BuildPodController().RollBack(reset); err != nil {
 		//if !reset {
 		//	return err
@@ -26,7 +30,29 @@ func (c *Controller) DevEnd(reset bool) error {
 		log.WarnE(err, "something incorrect occurs when rolling back")
 	}
 
+	if restoreHPA {
+		c.RestoreHPA(suspendedHPA)
+	}
+
+	if c.DevModeType.IsReplaceDevMode() {
+		utils.ShouldI(c.ClearDevModeTTL(), "Failed to remove ttl of DevMode")
+	}
+
 	utils.ShouldI(c.AppMeta.SvcDevEnd(c.Name, c.Identifier, c.Type, c.DevModeType), "something incorrect occurs when updating secret")
+	utils.ShouldI(c.AppMeta.DevSessionRemoveWorkload(c.Name, c.Type), "Failed to remove workload from dev session")
 
 	return nil
 }
+
+// suspendedHPAToRestore returns hpa suspended by replace DevMode and true if they need to be restored.
+// Other DevMode never suspends hpa, ending them must not restore hpa suspended by someone's replace DevMode
+func (c *Controller) suspendedHPAToRestore() ([]*profile.SuspendedHPA, bool) {
+	if !c.DevModeType.IsReplaceDevMode() {
+		return nil, false
+	}
+	suspendedHPA, err := c.GetSuspendedHPA()
+	if err != nil {
+		log.WarnE(err, "Failed to get suspended hpa")
+	}
+	return suspendedHPA, true
+}
@@ -0,0 +1,151 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mitchellh/go-ps"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	_const "nocalhost/internal/nhctl/const"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecordDevModeTTL records ttl and the first heartbeat of DevMode in the workload's annotations,
+// ttl of the dev space is used by nocalhost-dep if ttl is 0
+func (c *Controller) RecordDevModeTTL(ttl time.Duration) error {
+	value := ""
+	if ttl > 0 {
+		value = ttl.String()
+	}
+	return c.patchDevModeAnnotations(
+		map[string]interface{}{
+			_const.DevModeTTLAnnotation:          value,
+			_const.DevModeHeartbeatAnnotation:    time.Now().UTC().Format(time.RFC3339),
+			_const.NocalhostApplicationName:      c.AppName,
+			_const.NocalhostApplicationNamespace: c.NameSpace,
+		},
+	)
+}
+
+// DevModeHeartbeat keeps DevMode from being expired, nothing is done if the workload has no ttl recorded
+func (c *Controller) DevModeHeartbeat() error {
+	um, err := c.GetUnstructured()
+	if err != nil {
+		return err
+	}
+	if _, ok := um.GetAnnotations()[_const.DevModeTTLAnnotation]; !ok {
+		return nil
+	}
+	return c.patchDevModeAnnotations(
+		map[string]interface{}{_const.DevModeHeartbeatAnnotation: time.Now().UTC().Format(time.RFC3339)},
+	)
+}
+
+// ClearDevModeTTL removes ttl and heartbeat of DevMode from the workload's annotations, rolling back a workload
+// with DevModeAction.Create applies the original manifest, which does not remove them
+func (c *Controller) ClearDevModeTTL() error {
+	return c.patchResource(c.Type.String(), c.Name, ClearDevModeTTLPatch(), "merge")
+}
+
+// ClearDevModeTTLPatch returns the merge patch removing ttl and heartbeat of DevMode
+func ClearDevModeTTLPatch() string {
+	mBytes, _ := json.Marshal(
+		map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					_const.DevModeTTLAnnotation:       nil,
+					_const.DevModeHeartbeatAnnotation: nil,
+				},
+			},
+		},
+	)
+	return string(mBytes)
+}
+
+func (c *Controller) patchDevModeAnnotations(annotations map[string]interface{}) error {
+	mBytes, _ := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": annotations}})
+	return c.patchResource(c.Type.String(), c.Name, string(mBytes), "merge")
+}
+
+// DevModeExpiry returns when DevMode recorded in annotations expires, false is returned
+// if the workload has no ttl recorded or neither the workload nor the dev space specifies a ttl
+func DevModeExpiry(annotations map[string]string, defaultTTL time.Duration) (time.Time, bool, error) {
+	value, ok := annotations[_const.DevModeTTLAnnotation]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	ttl := defaultTTL
+	if value != "" {
+		var err error
+		if ttl, err = time.ParseDuration(value); err != nil {
+			return time.Time{}, false, errors.Wrap(err, fmt.Sprintf("Invalid annotation %s", _const.DevModeTTLAnnotation))
+		}
+	}
+	if ttl <= 0 {
+		return time.Time{}, false, nil
+	}
+
+	heartbeat, err := time.Parse(time.RFC3339, annotations[_const.DevModeHeartbeatAnnotation])
+	if err != nil {
+		return time.Time{}, false, errors.Wrap(
+			err, fmt.Sprintf("Invalid annotation %s", _const.DevModeHeartbeatAnnotation),
+		)
+	}
+	return heartbeat.Add(ttl), true, nil
+}
+
+// IsSyncActive returns true if syncthing of the workload is running in this device
+func (c *Controller) IsSyncActive() bool {
+	pid, err := c.GetSyncThingPid()
+	if err != nil || pid == 0 {
+		return false
+	}
+	pro, err := ps.FindProcess(pid)
+	return err == nil && pro != nil
+}
+
+// IsTerminalActive returns true if any terminal of DevContainer is open in this device
+func (c *Controller) IsTerminalActive() bool {
+	files, err := filepath.Glob(filepath.Join(c.GetSyncDir(), _const.DefaultTerminalPidFilePrefix+"*.pid"))
+	if err != nil {
+		return false
+	}
+	for _, f := range files {
+		pid, err := strconv.Atoi(
+			strings.TrimSuffix(strings.TrimPrefix(filepath.Base(f), _const.DefaultTerminalPidFilePrefix), ".pid"),
+		)
+		if err != nil {
+			continue
+		}
+		if pro, err := ps.FindProcess(pid); err == nil && pro != nil {
+			return true
+		}
+		// terminal exited without cleaning up
+		_ = os.Remove(f)
+	}
+	return false
+}
+
+// markTerminalOpen creates the pid file of current process, which should be removed after terminal exits
+func (c *Controller) markTerminalOpen() func() {
+	pidFile := filepath.Join(
+		c.GetSyncDir(), fmt.Sprintf("%s%d.pid", _const.DefaultTerminalPidFilePrefix, os.Getpid()),
+	)
+	if err := ioutil.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		log.WarnE(errors.Wrap(err, ""), "Failed to create terminal pid file")
+	}
+	return func() {
+		_ = os.Remove(pidFile)
+	}
+}
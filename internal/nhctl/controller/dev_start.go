@@ -19,6 +19,7 @@ import (
 	"nocalhost/internal/nhctl/profile"
 	secret_config "nocalhost/internal/nhctl/syncthing/secret-config"
 	"nocalhost/internal/nhctl/utils"
+	"nocalhost/pkg/nhctl/clientgoutils"
 	"nocalhost/pkg/nhctl/log"
 	"strings"
 	"time"
@@ -190,19 +191,7 @@ func (c *Controller) genWorkDirAndPVAndMounts(container, storageClass string, ig
 		}
 
 		// Check if pvc is already exist
-		labels := map[string]string{}
-		if duplicateDevMode {
-			labels = c.getDuplicateLabelsMap()
-			labels[_const.DevWorkloadIgnored] = "false"
-			labels[_const.AppLabel] = c.AppName
-			labels[_const.ServiceLabel] = c.Name
-			labels[_const.ServiceTypeLabel] = string(c.Type)
-		} else {
-			labels[_const.AppLabel] = c.AppName
-			labels[_const.ServiceLabel] = c.Name
-			labels[_const.ServiceTypeLabel] = string(c.Type)
-		}
-		labels[_const.PersistentVolumeDirLabel] = utils.Sha1ToString(persistentVolume.Path)
+		labels := c.persistentVolumeLabels(persistentVolume.Path, duplicateDevMode)
 		claims, err := c.Client.GetPvcByLabels(labels)
 		if err != nil {
 			log.WarnE(err, fmt.Sprintf("Fail to get a pvc for %s", persistentVolume.Path))
@@ -229,7 +218,9 @@ func (c *Controller) genWorkDirAndPVAndMounts(container, storageClass string, ig
 				"No PVC for %s found, trying to create one with storage class %s...",
 				persistentVolume.Path, storageClass,
 			)
-			pvc, err = c.createPvcForPersistentVolumeDir(persistentVolume, labels, storageClass)
+			pvc, err = c.createPvcForPersistentVolumeDir(
+				persistentVolume, labels, storageClass, c.GetVolumeSnapshot(container),
+			)
 			if err != nil || pvc == nil {
 				return nil, nil, errors.Wrap(
 					nocalhost.CreatePvcFailed, "Failed to create pvc for "+persistentVolume.Path,
@@ -293,10 +284,65 @@ func (c *Controller) genWorkDirAndPVAndMounts(container, storageClass string, ig
 	return volumes, volumeMounts, nil
 }
 
+// genSyncFolderVolumesAndMounts shares remote paths of sync folders between sidecar and DevContainer,
+// paths in workDir or persistent volume dirs are shared already
+func (c *Controller) genSyncFolderVolumesAndMounts(container, workDir string) (
+	[]corev1.Volume, []corev1.VolumeMount) {
+	volumes := make([]corev1.Volume, 0)
+	volumeMounts := make([]corev1.VolumeMount, 0)
+	devConfig := c.Config().GetContainerDevConfigOrDefault(container)
+	if devConfig == nil || devConfig.Sync == nil {
+		return volumes, volumeMounts
+	}
+
+	sharedDirs := []string{workDir}
+	for _, persistentVolume := range c.GetPersistentVolumeDirs(container) {
+		sharedDirs = append(sharedDirs, persistentVolume.Path)
+	}
+	for index, folder := range devConfig.Sync.Folders {
+		if folder.RemotePath == "" || isInDirs(folder.RemotePath, sharedDirs) {
+			continue
+		}
+		name := fmt.Sprintf("nocalhost-sync-folder-%d", index)
+		volumes = append(
+			volumes, corev1.Volume{
+				Name:         name,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			},
+		)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: name, MountPath: folder.RemotePath})
+	}
+	return volumes, volumeMounts
+}
+
+func isInDirs(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if dir != "" && (path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// persistentVolumeLabels returns labels of the pvc created for persistent volume dir
+func (c *Controller) persistentVolumeLabels(path string, duplicateDevMode bool) map[string]string {
+	labels := map[string]string{}
+	if duplicateDevMode {
+		labels = c.getDuplicateLabelsMap()
+		labels[_const.DevWorkloadIgnored] = "false"
+	}
+	labels[_const.AppLabel] = c.AppName
+	labels[_const.ServiceLabel] = c.Name
+	labels[_const.ServiceTypeLabel] = string(c.Type)
+	labels[_const.PersistentVolumeDirLabel] = utils.Sha1ToString(path)
+	return labels
+}
+
 // Initial a pvc for persistent volume dir, and waiting util pvc succeed to bound to a pv
 // If pvc failed to bound to a pv, the pvc will been deleted, and return nil
+// If snapshot is specified, the pvc is populated from the VolumeSnapshot of the dir in it
 func (c *Controller) createPvcForPersistentVolumeDir(
-	persistentVolume *profile.PersistentVolumeDir, labels map[string]string, storageClass string,
+	persistentVolume *profile.PersistentVolumeDir, labels map[string]string, storageClass, snapshot string,
 ) (*corev1.PersistentVolumeClaim, error) {
 	var (
 		pvc *corev1.PersistentVolumeClaim
@@ -310,10 +356,30 @@ func (c *Controller) createPvcForPersistentVolumeDir(
 		capacity = "10Gi"
 	}
 
+	var snapshotName string
+	if snapshot != "" {
+		if snapshotName, capacity, err = c.findPersistentVolumeSnapshot(snapshot, persistentVolume.Path, capacity); err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to find snapshot %s, creating an empty pvc", snapshot))
+		}
+	}
+
+	if c.dryRun != nil {
+		var sc *string
+		if storageClass != "" {
+			sc = &storageClass
+		}
+		if pvc, err = clientgoutils.NewPVC(pvcName, labels, annotations, capacity, sc); err != nil {
+			return nil, err
+		}
+		clientgoutils.SetPVCSnapshotDataSource(pvc, snapshotName)
+		c.dryRun.Pvcs = append(c.dryRun.Pvcs, pvc)
+		return pvc, nil
+	}
+
 	if storageClass == "" {
-		pvc, err = c.Client.CreatePVC(pvcName, labels, annotations, capacity, nil)
+		pvc, err = c.Client.CreatePVCFromSnapshot(pvcName, labels, annotations, capacity, nil, snapshotName)
 	} else {
-		pvc, err = c.Client.CreatePVC(pvcName, labels, annotations, capacity, &storageClass)
+		pvc, err = c.Client.CreatePVCFromSnapshot(pvcName, labels, annotations, capacity, &storageClass, snapshotName)
 	}
 	if err != nil {
 		return nil, err
@@ -482,15 +548,49 @@ func convertToResourceList(cpu string, mem string) (corev1.ResourceList, error)
 	return requestMap, nil
 }
 
+// devContainerNamesOfPod returns names of DevContainer and sidecar container of a dev pod
+func devContainerNamesOfPod(pod *corev1.Pod) (string, string) {
+	devContainerName, ok := pod.Annotations[_const.NocalhostDevContainerAnnotations]
+	if !ok {
+		devContainerName = _const.NocalhostDefaultDevContainerName
+	}
+	sidecarName, ok := pod.Annotations[_const.NocalhostDevSidecarAnnotations]
+	if !ok {
+		sidecarName = _const.NocalhostDefaultDevSidecarName
+		// syncthing runs in DevContainer which replaces an init container
+		if findInitContainerInPodSpec(&pod.Spec, devContainerName) != nil {
+			sidecarName = devContainerName
+		}
+	}
+	return devContainerName, sidecarName
+}
+
+// containerNamesOfPod returns names of containers, ephemeral containers and init containers
+func containerNamesOfPod(pod *corev1.Pod) []string {
+	names := make([]string, 0)
+	for _, container := range pod.Spec.Containers {
+		names = append(names, container.Name)
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		names = append(names, container.Name)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		names = append(names, container.Name)
+	}
+	return names
+}
+
 // Find Ready Dev Pod's name
 func findDevPodName(podList ...corev1.Pod) (string, error) {
 	resultPodList := make([]corev1.Pod, 0)
 	for _, pod := range podList {
 		//if pod.Status.Phase == "Running" && pod.DeletionTimestamp == nil {
 		if pod.DeletionTimestamp == nil {
-			for _, container := range pod.Spec.Containers {
-				if container.Name == _const.DefaultNocalhostSideCarName {
+			_, sidecarName := devContainerNamesOfPod(&pod)
+			for _, name := range containerNamesOfPod(&pod) {
+				if name == sidecarName {
 					resultPodList = append(resultPodList, pod)
+					break
 				}
 			}
 		}
@@ -506,20 +606,24 @@ func findDevPodName(podList ...corev1.Pod) (string, error) {
 			}
 		}
 
-		devContainerName, ok := latestPod.Annotations[_const.NocalhostDevContainerAnnotations]
-		if !ok {
-			devContainerName = _const.NocalhostDefaultDevContainerName
-		}
+		devContainerName, sidecarName := devContainerNamesOfPod(&latestPod)
 
-		count := 0
+		ready := make(map[string]bool, 0)
 		for _, status := range latestPod.Status.ContainerStatuses {
-
-			if (status.Name == devContainerName || status.Name == _const.DefaultNocalhostSideCarName) &&
-				status.Ready {
-				count++
+			if status.Ready {
+				ready[status.Name] = true
 			}
 		}
-		if count == 2 {
+		// ephemeral and init containers do not support probes, running means ready
+		runningStatuses := make([]corev1.ContainerStatus, 0)
+		runningStatuses = append(runningStatuses, latestPod.Status.EphemeralContainerStatuses...)
+		runningStatuses = append(runningStatuses, latestPod.Status.InitContainerStatuses...)
+		for _, status := range runningStatuses {
+			if status.State.Running != nil {
+				ready[status.Name] = true
+			}
+		}
+		if ready[devContainerName] && ready[sidecarName] {
 			return latestPod.Name, nil
 		}
 
@@ -535,21 +639,21 @@ func containerStatusForDevPod(pod *corev1.Pod, consumeFun func(status string, er
 		return false
 	}
 
-	devContainerName, ok := pod.Annotations[_const.NocalhostDevContainerAnnotations]
-	if !ok {
-		devContainerName = _const.NocalhostDefaultDevContainerName
-	}
-
-	// dev container must have 2 containers: nocalhost-dev & nocalhost-sidecar
+	devContainerName, sidecarName := devContainerNamesOfPod(pod)
 
-	containerFoundCounter := 0
-	for _, container := range pod.Spec.Containers {
-		if container.Name == devContainerName || container.Name == _const.NocalhostDefaultDevSidecarName {
-			containerFoundCounter++
+	// dev container must have 2 containers: nocalhost-dev & nocalhost-sidecar,
+	// they are the same one if DevContainer replaces an init container
+	var devContainerFound, sidecarFound bool
+	for _, name := range containerNamesOfPod(pod) {
+		if name == devContainerName {
+			devContainerFound = true
+		}
+		if name == sidecarName {
+			sidecarFound = true
 		}
 	}
 
-	if containerFoundCounter < 2 {
+	if !devContainerFound || !sidecarFound {
 		return false
 	}
 
@@ -565,8 +669,12 @@ func containerStatusForDevPod(pod *corev1.Pod, consumeFun func(status string, er
 		}
 	}
 
-	for _, status := range pod.Status.ContainerStatuses {
-		if status.Name != devContainerName && status.Name != _const.NocalhostDefaultDevSidecarName {
+	statuses := make([]corev1.ContainerStatus, 0)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	statuses = append(statuses, pod.Status.EphemeralContainerStatuses...)
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	for _, status := range statuses {
+		if status.Name != devContainerName && status.Name != sidecarName {
 			continue
 		}
 
@@ -611,9 +719,17 @@ func (c *Controller) genContainersAndVolumes(podSpec *corev1.PodSpec,
 	containerName, devImage, storageClass string, duplicateDevMode bool) (*corev1.Container,
 	*corev1.Container, []corev1.Volume, error) {
 
-	devContainer, err := findDevContainerInPodSpec(podSpec, containerName)
-	if err != nil {
-		return nil, nil, nil, err
+	// DevContainer replacing an init container is patched while patching to pod spec,
+	// init containers of the original pod spec are kept
+	var devContainer *corev1.Container
+	initContainer := findInitContainerInPodSpec(podSpec, containerName)
+	if initContainer != nil {
+		devContainer = initContainer.DeepCopy()
+	} else {
+		var err error
+		if devContainer, err = findDevContainerInPodSpec(podSpec, containerName); err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
 	devModeVolumes := make([]corev1.Volume, 0)
@@ -631,10 +747,13 @@ func (c *Controller) genContainersAndVolumes(podSpec *corev1.PodSpec,
 		}
 	}
 
-	// Set volumes
-	syncthingVolumes, syncthingVolumeMounts := c.generateSyncVolumesAndMounts(duplicateDevMode)
-	devModeVolumes = append(devModeVolumes, syncthingVolumes...)
-	devModeMounts = append(devModeMounts, syncthingVolumeMounts...)
+	// Set volumes, files are sent over the exec api instead of syncthing in exec sync
+	execSync := c.IsExecSync(containerName)
+	if !execSync {
+		syncthingVolumes, syncthingVolumeMounts := c.generateSyncVolumesAndMounts(duplicateDevMode)
+		devModeVolumes = append(devModeVolumes, syncthingVolumes...)
+		devModeMounts = append(devModeMounts, syncthingVolumeMounts...)
+	}
 
 	workDirAndPersistVolumes, workDirAndPersistVolumeMounts, err := c.genWorkDirAndPVAndMounts(
 		containerName, storageClass, workDirAlreadyMounted, duplicateDevMode,
@@ -646,6 +765,10 @@ func (c *Controller) genContainersAndVolumes(podSpec *corev1.PodSpec,
 	devModeVolumes = append(devModeVolumes, workDirAndPersistVolumes...)
 	devModeMounts = append(devModeMounts, workDirAndPersistVolumeMounts...)
 
+	syncFolderVolumes, syncFolderMounts := c.genSyncFolderVolumesAndMounts(containerName, workDir)
+	devModeVolumes = append(devModeVolumes, syncFolderVolumes...)
+	devModeMounts = append(devModeMounts, syncFolderMounts...)
+
 	if devImage == "" {
 		devImage = c.GetDevImage(containerName) // Default : replace the first container
 	}
@@ -708,11 +831,32 @@ func (c *Controller) genContainersAndVolumes(podSpec *corev1.PodSpec,
 	}
 	rq, _ := convertResourceQuota(r)
 	sideCarContainer.Resources = *rq
+
+	if initContainer != nil {
+		log.Infof(
+			"DevContainer replaces init container %s, app containers are held until "+
+				"`nhctl dev init-complete` is executed", containerName,
+		)
+		if execSync {
+			holdInitDevContainer(devContainer)
+		} else {
+			devModeVolumes = append(devModeVolumes, convertToInitDevContainers(devContainer, &sideCarContainer))
+		}
+	}
+	// no sidecar runs syncthing in exec sync
+	if execSync {
+		return devContainer, nil, devModeVolumes, nil
+	}
 	return devContainer, &sideCarContainer, devModeVolumes, nil
 }
 
 func patchDevContainerToPodSpec(podSpec *corev1.PodSpec, containerName string, devContainer,
 	sidecarContainer *corev1.Container, devModeVolumes []corev1.Volume) {
+	if patchInitDevContainerToPodSpec(podSpec, containerName, devContainer, sidecarContainer) {
+		podSpec.Volumes = append(podSpec.Volumes, devModeVolumes...)
+		return
+	}
+
 	if containerName != "" {
 		for index, c := range podSpec.Containers {
 			if c.Name == containerName {
@@ -737,7 +881,9 @@ func patchDevContainerToPodSpec(podSpec *corev1.PodSpec, containerName string, d
 		podSpec.Containers[i].StartupProbe = nil
 	}
 
-	podSpec.Containers = append(podSpec.Containers, *sidecarContainer)
+	if sidecarContainer != nil {
+		podSpec.Containers = append(podSpec.Containers, *sidecarContainer)
+	}
 }
 
 // IsResourcesLimitTooLow
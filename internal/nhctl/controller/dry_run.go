@@ -0,0 +1,171 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"nocalhost/internal/nhctl/model"
+	"nocalhost/pkg/nhctl/log"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+// DevModeDryRunResult is what entering DevMode would apply to the cluster
+type DevModeDryRunResult struct {
+	// Live is the workload currently running in the cluster
+	Live *unstructured.Unstructured
+	// DevMode is the workload after all the patches applied
+	DevMode *unstructured.Unstructured
+	// Generated is created to run DevContainer if DevModeAction.Create is true
+	Generated *unstructured.Unstructured
+	// Pvcs are created for PersistentVolumeDirs
+	Pvcs []*corev1.PersistentVolumeClaim
+}
+
+// DryRunDevModeManifest goes through what entering replace DevMode does with a copy of the live
+// workload, changes are applied to the copy locally, nothing in the cluster will be changed
+func (c *Controller) DryRunDevModeManifest(ops *model.DevStartOptions, ttl time.Duration) (
+	*DevModeDryRunResult, error) {
+	if !c.DevModeType.IsReplaceDevMode() {
+		return nil, errors.New(fmt.Sprintf("Dry run is not supported in %s DevMode", c.DevModeType))
+	}
+
+	live, err := c.GetUnstructured()
+	if err != nil {
+		return nil, err
+	}
+	result := &DevModeDryRunResult{
+		Live:    live,
+		DevMode: live.DeepCopy(),
+		Pvcs:    make([]*corev1.PersistentVolumeClaim, 0),
+	}
+
+	c.dryRun = result
+	defer func() {
+		c.dryRun = nil
+	}()
+
+	// the same steps as `nhctl dev start` does in replace DevMode
+	if err = c.SuspendHPA(); err != nil {
+		log.WarnE(err, "Failed to suspend hpa")
+	}
+	if err = c.BuildPodController().ReplaceImage(context.TODO(), ops); err != nil {
+		return nil, err
+	}
+	if err = c.RecordDevModeTTL(ttl); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// patchResource patches the resource in cluster, the workload or the generated deployment in
+// the result is patched instead while dry running
+func (c *Controller) patchResource(resourceType, name, patch, patchType string) error {
+	if c.dryRun == nil {
+		return c.Client.Patch(resourceType, name, patch, patchType)
+	}
+	if c.dryRun.Generated != nil && name == c.dryRun.Generated.GetName() {
+		return applyPatchLocally(c.dryRun.Generated, patch, patchType)
+	}
+	return applyPatchLocally(c.dryRun.DevMode, patch, patchType)
+}
+
+// createDeployment creates the deployment in cluster, it is recorded as generated while dry running
+func (c *Controller) createDeployment(deployment *appsv1.Deployment) error {
+	if c.dryRun == nil {
+		_, err := c.Client.CreateDeployment(deployment)
+		return err
+	}
+	generated, err := toUnstructured(deployment)
+	if err != nil {
+		return err
+	}
+	generated.SetAPIVersion(appsv1.SchemeGroupVersion.String())
+	generated.SetKind("Deployment")
+	c.dryRun.Generated = generated
+	return nil
+}
+
+// recreatePod replaces the pod in cluster with pod, the pod becomes the workload in DevMode while dry running
+func (c *Controller) recreatePod(pod *corev1.Pod) error {
+	if c.dryRun == nil {
+		if err := c.Client.DeletePodByName(pod.Name, 0); err != nil {
+			return err
+		}
+		time.Sleep(1 * time.Second)
+
+		log.Info("Create dev pod...")
+		_, err := c.Client.CreatePod(pod)
+		return err
+	}
+	devMode, err := toUnstructured(pod)
+	if err != nil {
+		return err
+	}
+	devMode.SetAPIVersion(corev1.SchemeGroupVersion.String())
+	devMode.SetKind("Pod")
+	c.dryRun.DevMode = devMode
+	return nil
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &unstructured.Unstructured{Object: m}, nil
+}
+
+// applyPatchLocally applies patch to u in the same way as `kubectl patch`
+func applyPatchLocally(u *unstructured.Unstructured, patch, patchType string) error {
+	patchBytes, err := yaml.YAMLToJSON([]byte(patch))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Unable to parse patch %s", patch))
+	}
+
+	original, err := u.MarshalJSON()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var patched []byte
+	switch patchType {
+	case "json":
+		p, err := jsonpatch.DecodePatch(patchBytes)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if patched, err = p.Apply(original); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to apply patch %s", patch))
+		}
+	case "merge":
+		if patched, err = jsonpatch.MergePatch(original, patchBytes); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to apply patch %s", patch))
+		}
+	case "strategic", "":
+		dataStruct, err := scheme.Scheme.New(u.GroupVersionKind())
+		if err != nil {
+			return errors.Wrap(
+				err, fmt.Sprintf("Strategic merge patch is not supported for %s", u.GroupVersionKind()),
+			)
+		}
+		if patched, err = strategicpatch.StrategicMergePatch(original, patchBytes, dataStruct); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to apply patch %s", patch))
+		}
+	default:
+		return errors.New(fmt.Sprintf("Unsupported patch type %s", patchType))
+	}
+	return errors.WithStack(u.UnmarshalJSON(patched))
+}
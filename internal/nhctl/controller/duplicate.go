@@ -231,8 +231,7 @@ func (c *Controller) ReplaceDuplicateModeImage(ctx context.Context, ops *model.D
 	delete(podTemplate.Labels, "pod-template-hash")
 	c.devModePodLabels = podTemplate.Labels
 
-	c.waitDevPodToBeReady()
-	return nil
+	return c.waitDevPodToBeReady()
 }
 
 func addAnnotationToDuplicate(podTemplate *v1.PodTemplateSpec, uuid string, header map[string]string) {
@@ -89,8 +89,7 @@ func (r *DuplicateRawPodController) ReplaceImage(ctx context.Context, ops *model
 
 	r.patchAfterDevContainerReplaced(ops.Container, originalPod.Kind, originalPod.Name)
 
-	r.waitDevPodToBeReady()
-	return nil
+	return r.waitDevPodToBeReady()
 }
 
 func (r *DuplicateRawPodController) RollBack(reset bool) error {
@@ -0,0 +1,343 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"nocalhost/internal/nhctl/profile"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExportContainerEnv resolves env the container sees in pod, configmaps and secrets mounted by the container
+// are exported to volumeDir if it is not empty, mount paths in container to paths in local are returned.
+// volumeDir with content is refused unless force is true, files already in it are never removed
+func (c *Controller) ExportContainerEnv(container, volumeDir string, force bool) (
+	[]*profile.Env, map[string]string, error) {
+	pod, err := c.findPodToExport()
+	if err != nil {
+		return nil, nil, err
+	}
+	ctn, err := findDevContainerInPodSpec(&pod.Spec, container)
+	if err != nil {
+		return nil, nil, err
+	}
+	envMap, err := c.resolveContainerEnv(pod, ctn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mounts := make(map[string]string, 0)
+	if volumeDir != "" {
+		if mounts, err = c.exportVolumes(pod, ctn, volumeDir, force); err != nil {
+			return nil, nil, err
+		}
+	}
+	return sortedEnv(envMap), mounts, nil
+}
+
+func sortedEnv(envMap map[string]string) []*profile.Env {
+	envs := make([]*profile.Env, 0, len(envMap))
+	for k, v := range envMap {
+		envs = append(envs, &profile.Env{Name: k, Value: v})
+	}
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Name < envs[j].Name })
+	return envs
+}
+
+// findPodToExport prefers a running pod, whose fields can be referred by env,
+// pod template is used if the workload has no pod running
+func (c *Controller) findPodToExport() (*corev1.Pod, error) {
+	pods, err := c.GetPodList()
+	if err == nil {
+		for i, pod := range pods {
+			if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning {
+				return &pods[i], nil
+			}
+		}
+	}
+	pt, err := c.GetPodTemplate()
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Pod{ObjectMeta: pt.ObjectMeta, Spec: pt.Spec}, nil
+}
+
+// resolveContainerEnv resolves env of the container in the same order as kubelet does
+func (c *Controller) resolveContainerEnv(pod *corev1.Pod, container *corev1.Container) (map[string]string, error) {
+	envs := make(map[string]string, 0)
+
+	for _, from := range container.EnvFrom {
+		var data map[string]string
+		if from.ConfigMapRef != nil {
+			cm, err := c.Client.GetConfigMaps(from.ConfigMapRef.Name)
+			if err != nil {
+				if isOptional(from.ConfigMapRef.Optional) {
+					continue
+				}
+				return nil, err
+			}
+			data = cm.Data
+		} else if from.SecretRef != nil {
+			secret, err := c.Client.GetSecret(from.SecretRef.Name)
+			if err != nil {
+				if isOptional(from.SecretRef.Optional) {
+					continue
+				}
+				return nil, err
+			}
+			data = make(map[string]string, len(secret.Data))
+			for k, v := range secret.Data {
+				data[k] = string(v)
+			}
+		}
+		for k, v := range data {
+			envs[from.Prefix+k] = v
+		}
+	}
+
+	for _, env := range container.Env {
+		if env.ValueFrom == nil {
+			envs[env.Name] = expandEnvRef(env.Value, envs)
+			continue
+		}
+		value, ok, err := c.resolveEnvVarSource(pod, env.ValueFrom)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Failed to resolve env %s", env.Name))
+		}
+		if ok {
+			envs[env.Name] = value
+		}
+	}
+
+	return envs, nil
+}
+
+func (c *Controller) resolveEnvVarSource(pod *corev1.Pod, source *corev1.EnvVarSource) (string, bool, error) {
+	switch {
+	case source.ConfigMapKeyRef != nil:
+		cm, err := c.Client.GetConfigMaps(source.ConfigMapKeyRef.Name)
+		if err != nil {
+			return "", false, ignoreOptional(err, source.ConfigMapKeyRef.Optional)
+		}
+		value, ok := cm.Data[source.ConfigMapKeyRef.Key]
+		if !ok && !isOptional(source.ConfigMapKeyRef.Optional) {
+			return "", false, errors.New(
+				fmt.Sprintf("Key %s not found in configmap %s", source.ConfigMapKeyRef.Key, cm.Name),
+			)
+		}
+		return value, ok, nil
+	case source.SecretKeyRef != nil:
+		secret, err := c.Client.GetSecret(source.SecretKeyRef.Name)
+		if err != nil {
+			return "", false, ignoreOptional(err, source.SecretKeyRef.Optional)
+		}
+		value, ok := secret.Data[source.SecretKeyRef.Key]
+		if !ok && !isOptional(source.SecretKeyRef.Optional) {
+			return "", false, errors.New(
+				fmt.Sprintf("Key %s not found in secret %s", source.SecretKeyRef.Key, secret.Name),
+			)
+		}
+		return string(value), ok, nil
+	case source.FieldRef != nil:
+		value, ok := podFieldValue(pod, source.FieldRef.FieldPath)
+		return value, ok, nil
+	default:
+		// resourceFieldRef makes no sense to a local process
+		return "", false, nil
+	}
+}
+
+var podFieldPathRegex = regexp.MustCompile(`^metadata\.(labels|annotations)\['(.+)'\]$`)
+
+func podFieldValue(pod *corev1.Pod, fieldPath string) (string, bool) {
+	switch fieldPath {
+	case "metadata.name":
+		return pod.Name, true
+	case "metadata.namespace":
+		return pod.Namespace, true
+	case "metadata.uid":
+		return string(pod.UID), true
+	case "spec.nodeName":
+		return pod.Spec.NodeName, true
+	case "spec.serviceAccountName":
+		return pod.Spec.ServiceAccountName, true
+	case "status.hostIP":
+		return pod.Status.HostIP, true
+	case "status.podIP":
+		return pod.Status.PodIP, true
+	}
+	if match := podFieldPathRegex.FindStringSubmatch(fieldPath); match != nil {
+		if match[1] == "labels" {
+			value, ok := pod.Labels[match[2]]
+			return value, ok
+		}
+		value, ok := pod.Annotations[match[2]]
+		return value, ok
+	}
+	return "", false
+}
+
+var envRefRegex = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+// expandEnvRef expands $(VAR) with env defined before it, just like kubelet
+func expandEnvRef(value string, envs map[string]string) string {
+	return envRefRegex.ReplaceAllStringFunc(
+		value, func(s string) string {
+			if v, ok := envs[s[2:len(s)-1]]; ok {
+				return v
+			}
+			return s
+		},
+	)
+}
+
+// exportVolumes writes configmaps and secrets mounted by the container to dir,
+// returns mount paths in container to paths in local
+func (c *Controller) exportVolumes(pod *corev1.Pod, container *corev1.Container, dir string, force bool) (
+	map[string]string, error) {
+	if !force {
+		if entries, err := ioutil.ReadDir(dir); err == nil && len(entries) > 0 {
+			return nil, errors.New(fmt.Sprintf("Directory %s is not empty, use --force to export volumes into it", dir))
+		}
+	}
+
+	volumes := make(map[string]corev1.Volume, 0)
+	for _, v := range pod.Spec.Volumes {
+		volumes[v.Name] = v
+	}
+
+	mounts := make(map[string]string, 0)
+	for _, vm := range container.VolumeMounts {
+		v, ok := volumes[vm.Name]
+		if !ok {
+			continue
+		}
+
+		var data map[string][]byte
+		var items []corev1.KeyToPath
+		switch {
+		case v.ConfigMap != nil:
+			cm, err := c.Client.GetConfigMaps(v.ConfigMap.Name)
+			if err != nil {
+				if isOptional(v.ConfigMap.Optional) {
+					continue
+				}
+				return nil, err
+			}
+			data = make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+			for k, value := range cm.Data {
+				data[k] = []byte(value)
+			}
+			for k, value := range cm.BinaryData {
+				data[k] = value
+			}
+			items = v.ConfigMap.Items
+		case v.Secret != nil:
+			secret, err := c.Client.GetSecret(v.Secret.SecretName)
+			if err != nil {
+				if isOptional(v.Secret.Optional) {
+					continue
+				}
+				return nil, err
+			}
+			data = secret.Data
+			items = v.Secret.Items
+		default:
+			continue
+		}
+
+		files := make(map[string][]byte, 0)
+		if len(items) > 0 {
+			for _, item := range items {
+				if value, ok := data[item.Key]; ok {
+					files[item.Path] = value
+				}
+			}
+		} else {
+			files = data
+		}
+
+		localPath := filepath.Join(dir, filepath.FromSlash(vm.MountPath))
+		for name, content := range files {
+			if vm.SubPath != "" {
+				if name != vm.SubPath {
+					continue
+				}
+				name = ""
+			}
+			file := filepath.Join(localPath, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if err := ioutil.WriteFile(file, content, 0600); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		mounts[vm.MountPath] = localPath
+		log.Infof("Volume %s mounted at %s has been exported to %s", vm.Name, vm.MountPath, localPath)
+	}
+	return mounts, nil
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+func ignoreOptional(err error, optional *bool) error {
+	if isOptional(optional) {
+		return nil
+	}
+	return err
+}
+
+// WriteEnvFile writes envs in dotenv format, mount paths are listed as comments after header
+func WriteEnvFile(envFile *profile.EnvFile, header string, envs []*profile.Env, mounts map[string]string) error {
+	if dir := filepath.Dir(envFile.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return errors.WithStack(ioutil.WriteFile(envFile.Path, []byte(formatEnvFile(header, envs, mounts)), 0600))
+}
+
+func formatEnvFile(header string, envs []*profile.Env, mounts map[string]string) string {
+	var sb strings.Builder
+	if header != "" {
+		sb.WriteString(header + "\n")
+	}
+
+	mountPaths := make([]string, 0, len(mounts))
+	for k := range mounts {
+		mountPaths = append(mountPaths, k)
+	}
+	sort.Strings(mountPaths)
+	for _, mountPath := range mountPaths {
+		sb.WriteString(fmt.Sprintf("# %s -> %s\n", mountPath, mounts[mountPath]))
+	}
+
+	for _, env := range envs {
+		sb.WriteString(fmt.Sprintf("%s=%s\n", env.Name, formatEnvValue(env.Value)))
+	}
+	return sb.String()
+}
+
+// formatEnvValue double quotes value with Go escaping only if needed, which is understood by
+// dotenv loaders such as docker and IDE run configurations
+func formatEnvValue(value string) string {
+	if value == "" || strings.ContainsAny(value, "\n\r\"'#\\$`") || strings.TrimSpace(value) != value {
+		return strconv.Quote(value)
+	}
+	return value
+}
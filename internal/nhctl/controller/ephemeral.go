@@ -0,0 +1,316 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kblabels "k8s.io/apimachinery/pkg/labels"
+	_const "nocalhost/internal/nhctl/const"
+	"nocalhost/internal/nhctl/model"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/pkg/nhctl/log"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ephemeralDevEndSignal ephemeral containers can not be removed from a pod,
+// they keep running until this file is created
+const ephemeralDevEndSignal = "/tmp/nocalhost-dev-end"
+
+// EphemeralController attaches DevContainer and sidecar to a running pod as ephemeral containers,
+// neither the workload nor the pod will be restarted
+type EphemeralController struct {
+	*Controller
+}
+
+func (e *EphemeralController) ReplaceImage(ctx context.Context, ops *model.DevStartOptions) error {
+	e.Client.Context(ctx)
+
+	pod, err := e.findPodToAttach()
+	if err != nil {
+		return err
+	}
+
+	devContainer, sideCarContainer, err := e.genEphemeralContainers(&pod.Spec, ops.Container, ops.DevImage)
+	if err != nil {
+		return err
+	}
+
+	// there is no sidecar in exec sync, DevContainer is recorded as the sidecar to be waited for
+	containers := []corev1.EphemeralContainer{*devContainer}
+	sidecarName := devContainer.Name
+	if sideCarContainer != nil {
+		containers = append(containers, *sideCarContainer)
+		sidecarName = sideCarContainer.Name
+	}
+
+	log.Infof("Recording dev containers to pod %s...", pod.Name)
+	mBytes, _ := json.Marshal(
+		map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]string{
+					_const.NocalhostDevContainerAnnotations: devContainer.Name,
+					_const.NocalhostDevSidecarAnnotations:   sidecarName,
+				},
+			},
+		},
+	)
+	if err = e.Client.Patch("pod", pod.Name, string(mBytes), "merge"); err != nil {
+		return err
+	}
+
+	log.Infof("Attaching ephemeral containers to pod %s...", pod.Name)
+	if err = e.Client.AddEphemeralContainers(pod.Name, containers...); err != nil {
+		e.removeEphemeralAnnotations(pod.Name)
+		return err
+	}
+
+	e.devModePodLabels = kblabels.Set(pod.Labels)
+	return e.waitDevPodToBeReady()
+}
+
+// RollBack Ephemeral containers can not be removed, so we make them exit and leave the pod running
+func (e *EphemeralController) RollBack(reset bool) error {
+	pods, err := e.GetPodList()
+	if err != nil {
+		return err
+	}
+
+	var found bool
+	for _, pod := range pods {
+		if _, ok := pod.Annotations[_const.NocalhostDevSidecarAnnotations]; !ok {
+			continue
+		}
+		found = true
+
+		devContainerName, sidecarName := devContainerNamesOfPod(&pod)
+		names := []string{devContainerName}
+		if sidecarName != devContainerName {
+			names = append(names, sidecarName)
+		}
+		for _, name := range names {
+			log.Infof("Terminating ephemeral container %s of pod %s", name, pod.Name)
+			if err = e.Client.ExecWithIO(
+				pod.Name, name, []string{"touch", ephemeralDevEndSignal}, nil, nil, nil,
+			); err != nil {
+				log.WarnE(err, fmt.Sprintf("Failed to terminate ephemeral container %s", name))
+			}
+		}
+		e.removeEphemeralAnnotations(pod.Name)
+	}
+
+	if !found && !reset {
+		return errors.New("No pod with ephemeral DevContainer found")
+	}
+	return nil
+}
+
+func (e *EphemeralController) removeEphemeralAnnotations(podName string) {
+	jsonPatches := make([]jsonPatch, 0)
+	for _, key := range []string{_const.NocalhostDevContainerAnnotations, _const.NocalhostDevSidecarAnnotations} {
+		jsonPatches = append(
+			jsonPatches, jsonPatch{
+				Op:   "remove",
+				Path: "/metadata/annotations/" + strings.ReplaceAll(key, "/", "~1"),
+			},
+		)
+	}
+	bys, _ := json.Marshal(jsonPatches)
+	if err := e.Client.Patch("pod", podName, string(bys), "json"); err != nil {
+		log.WarnE(err, fmt.Sprintf("Failed to remove dev annotations of pod %s", podName))
+	}
+}
+
+// findPodToAttach find a running pod of the workload which has not been attached yet
+func (e *EphemeralController) findPodToAttach() (*corev1.Pod, error) {
+	pods, err := e.GetPodList()
+	if err != nil {
+		return nil, err
+	}
+	for i, pod := range pods {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if _, ok := pod.Annotations[_const.NocalhostDevSidecarAnnotations]; ok {
+			continue
+		}
+		return &pods[i], nil
+	}
+	return nil, errors.New(fmt.Sprintf("No running pod of %s can be attached", e.Name))
+}
+
+// genEphemeralContainers generates DevContainer and sidecar as ephemeral containers, sidecar is nil in
+// exec sync. Ephemeral containers are not allowed to add volumes to a pod, so syncthing secret is
+// passed by env, and workDir must be backed by a volume already mounted in the container
+func (e *EphemeralController) genEphemeralContainers(podSpec *corev1.PodSpec, containerName, devImage string) (
+	*corev1.EphemeralContainer, *corev1.EphemeralContainer, error) {
+
+	targetContainer, err := findDevContainerInPodSpec(podSpec, containerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	workDir := e.GetWorkDir(containerName)
+	var workDirVolumeMount *corev1.VolumeMount
+	for i, mount := range targetContainer.VolumeMounts {
+		if workDir == mount.MountPath || strings.HasPrefix(workDir, strings.TrimSuffix(mount.MountPath, "/")+"/") {
+			workDirVolumeMount = &targetContainer.VolumeMounts[i]
+			break
+		}
+	}
+	if workDirVolumeMount == nil {
+		return nil, nil, errors.New(
+			fmt.Sprintf(
+				"Ephemeral DevMode needs workDir %s to reside in a volume mounted by container %s",
+				workDir, targetContainer.Name,
+			),
+		)
+	}
+
+	if len(e.GetPersistentVolumeDirs(containerName)) > 0 {
+		log.Warn("PersistentVolumeDirs are ignored in ephemeral DevMode")
+	}
+	if e.genResourceReq(containerName) != nil {
+		log.Warn("DevContainer resources are ignored in ephemeral DevMode")
+	}
+
+	if devImage == "" {
+		devImage = e.GetDevImage(containerName)
+	}
+	if cfg := e.Config().GetContainerDevConfigOrDefault(containerName); cfg != nil && cfg.Sync != nil &&
+		len(cfg.Sync.Folders) > 0 {
+		log.Warn("Sync folders out of WorkDir are not shared with DevContainer in ephemeral DevMode")
+	}
+
+	suffix, err := utils.GetShortUuid()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	devContainer := corev1.Container{
+		Name:       fmt.Sprintf("%s-%s", e.GetDevContainerName(containerName), suffix),
+		Image:      devImage,
+		Command:    []string{"/bin/sh", "-c", fmt.Sprintf("while [ ! -f %s ]; do sleep 1; done", ephemeralDevEndSignal)},
+		WorkingDir: workDir,
+		Env:        targetContainer.Env,
+		EnvFrom:    targetContainer.EnvFrom,
+		// DevContainer sees what the original container sees
+		VolumeMounts: targetContainer.VolumeMounts,
+	}
+	for _, v := range e.GetDevContainerEnv(containerName).DevEnv {
+		devContainer.Env = append(devContainer.Env, corev1.EnvVar{Name: v.Name, Value: v.Value})
+	}
+
+	pullPolicy := e.GetImagePullPolicy(containerName)
+	devContainer.ImagePullPolicy = pullPolicy
+
+	devEphemeralContainer := &corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon(devContainer),
+		// Share process namespace with original container, so its processes can be debugged
+		TargetContainerName: targetContainer.Name,
+	}
+	if e.IsExecSync(containerName) {
+		return devEphemeralContainer, nil, nil
+	}
+
+	syncthingVolumes, syncthingVolumeMounts := e.generateSyncVolumesAndMounts(false)
+	secretEnv, restoreSecret := secretVolumesToEnv(syncthingVolumes, syncthingVolumeMounts)
+
+	sideCarContainer := generateSideCarContainer(
+		e.GetDevSidecarImage(containerName), workDir,
+		e.sidecarContainerSSHUsed(e.GetDevSidecarLanguage(containerName), devImage),
+	)
+	sideCarContainer.Name = fmt.Sprintf("%s-%s", _const.NocalhostDefaultDevSidecarName, suffix)
+	sideCarContainer.Args = []string{ephemeralSidecarCommand(restoreSecret, strings.Join(sideCarContainer.Args, " "))}
+	sideCarContainer.Env = append(sideCarContainer.Env, secretEnv...)
+	sideCarContainer.VolumeMounts = []corev1.VolumeMount{*workDirVolumeMount}
+	sideCarContainer.ImagePullPolicy = pullPolicy
+
+	sideCarEphemeralContainer := &corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon(sideCarContainer),
+	}
+	return devEphemeralContainer, sideCarEphemeralContainer, nil
+}
+
+// ephemeralSidecarCommand restores secret files, runs command in background, and keeps the sidecar
+// running until ephemeralDevEndSignal is created, restore and command are skipped if they are empty
+func ephemeralSidecarCommand(restore, command string) string {
+	parts := make([]string, 0)
+	if restore != "" {
+		parts = append(parts, fmt.Sprintf("%s || exit 1;", restore))
+	}
+	if command != "" {
+		parts = append(parts, fmt.Sprintf("(%s) &", command))
+	}
+	parts = append(parts, fmt.Sprintf("while [ ! -f %s ]; do sleep 1; done", ephemeralDevEndSignal))
+	return strings.Join(parts, " ")
+}
+
+// terminatedEphemeralDevContainer returns an error if DevContainer or sidecar attached as ephemeral
+// containers exited, which are never restarted
+func terminatedEphemeralDevContainer(pod *corev1.Pod) error {
+	devContainerName, sidecarName := devContainerNamesOfPod(pod)
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.Name != devContainerName && status.Name != sidecarName {
+			continue
+		}
+		if terminated := status.State.Terminated; terminated != nil {
+			return errors.New(
+				fmt.Sprintf(
+					"Ephemeral container %s of pod %s exited with code %d: %s %s",
+					status.Name, pod.Name, terminated.ExitCode, terminated.Reason, terminated.Message,
+				),
+			)
+		}
+	}
+	return nil
+}
+
+var envNameRegexp = regexp.MustCompile("[^A-Z0-9_]")
+
+// secretVolumesToEnv converts secret volumes to env which references the secret keys,
+// and a shell command to restore the files where the volumes should be mounted
+func secretVolumesToEnv(volumes []corev1.Volume, mounts []corev1.VolumeMount) ([]corev1.EnvVar, string) {
+	envs := make([]corev1.EnvVar, 0)
+	cmds := make([]string, 0)
+	for _, volume := range volumes {
+		if volume.Secret == nil {
+			continue
+		}
+		for _, mount := range mounts {
+			if mount.Name != volume.Name {
+				continue
+			}
+			cmds = append(cmds, fmt.Sprintf("mkdir -p %s", mount.MountPath))
+			for _, item := range volume.Secret.Items {
+				envName := "NOCALHOST_" + envNameRegexp.ReplaceAllString(
+					strings.ToUpper(volume.Name+"_"+item.Key), "_",
+				)
+				envs = append(
+					envs, corev1.EnvVar{
+						Name: envName,
+						ValueFrom: &corev1.EnvVarSource{
+							SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: volume.Secret.SecretName},
+								Key:                  item.Key,
+							},
+						},
+					},
+				)
+				// printenv appends a newline which breaks pem and xml files
+				cmds = append(
+					cmds, fmt.Sprintf(`printf '%%s' "$%s" > %s`, envName, path.Join(mount.MountPath, item.Path)),
+				)
+			}
+		}
+	}
+	return envs, strings.Join(cmds, " && ")
+}
@@ -32,7 +32,12 @@ func (c *Controller) EnterPodTerminal(podName, container, shell, banner string)
 		}
 
 		if c.IsInDevMode() {
-			if cfg.DevContainerName != "" {
+			if c.DevModeType.IsEphemeralDevMode() {
+				// ephemeral DevContainer's name is generated, and recorded in pod's annotation
+				if p, err := c.Client.GetPod(podName); err == nil {
+					devContainerName, _ = devContainerNamesOfPod(p)
+				}
+			} else if cfg.DevContainerName != "" {
 				devContainerName = cfg.DevContainerName
 			} else {
 				devContainerName = _const.NocalhostDefaultDevContainerName
@@ -44,5 +49,9 @@ func (c *Controller) EnterPodTerminal(podName, container, shell, banner string)
 	if shell != "" {
 		cmd = fmt.Sprintf("(%s || zsh || bash || sh)", shell)
 	}
+	// DevMode is kept alive by daemon while terminal is open
+	if c.IsInReplaceDevMode() {
+		defer c.markTerminalOpen()()
+	}
 	return c.Client.ExecShell(pod, devContainerName, cmd, banner)
 }
@@ -0,0 +1,180 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"github.com/mitchellh/go-ps"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	_const "nocalhost/internal/nhctl/const"
+	"nocalhost/internal/nhctl/execsync"
+	"nocalhost/internal/nhctl/syncthing"
+	"nocalhost/internal/nhctl/syncthing/daemon"
+	"nocalhost/internal/nhctl/syncthing/network/req"
+	"nocalhost/internal/nhctl/syncthing/terminate"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// IsExecSync returns if files of container are synced over the exec api of k8s instead of syncthing
+func (c *Controller) IsExecSync(container string) bool {
+	devConfig := c.Config().GetContainerDevConfigOrDefault(container)
+	return devConfig != nil && devConfig.Sync != nil && devConfig.Sync.Type == _const.ExecSyncType
+}
+
+// StartExecSyncProcess starts a background nhctl process running the exec sync engine,
+// whose logs are written to exec-sync.log of sync dir
+func (c *Controller) StartExecSyncProcess(container, kubeconfig string) error {
+	nhctlPath, err := utils.GetNhctlPath()
+	if err != nil {
+		return err
+	}
+	args := []string{
+		"sync", c.AppName, "-d", c.Name, "-t", c.Type.String(), "-n", c.NameSpace,
+		"--kubeconfig", kubeconfig, "--container", container, "--exec-engine",
+	}
+	logFile, err := os.OpenFile(c.GetExecSyncLogFile(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(nhctlPath, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = daemon.NewSysProcAttr()
+	if err = cmd.Start(); err != nil {
+		return errors.Wrap(err, "Failed to start exec sync process")
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// RunExecSync sends files of local sync dir to DevContainer until the process is terminated
+func (c *Controller) RunExecSync(container string) error {
+	svcProfile, err := c.GetProfile()
+	if err != nil {
+		return err
+	}
+	if len(svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin) == 0 {
+		return errors.New("No local sync dir is associated")
+	}
+	if err = ioutil.WriteFile(
+		c.GetExecSyncPidFile(), []byte(strconv.Itoa(os.Getpid())), _const.DefaultNewFilePermission,
+	); err != nil {
+		return errors.Wrap(err, "")
+	}
+	defer os.Remove(c.GetExecSyncPidFile())
+	_ = os.Remove(c.GetExecSyncStatusFile())
+
+	s := &execsync.Syncer{
+		Exec: func(command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+			// dev pod is looked up each time, in case it is recreated
+			podName, err := c.GetDevModePodName()
+			if err != nil {
+				return err
+			}
+			return c.Client.ExecWithIO(podName, c.execSyncContainerName(podName, container), command, stdin, stdout, stderr)
+		},
+		LocalDir:   svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin[0],
+		RemoteDir:  c.GetWorkDir(container),
+		Matcher:    execsync.NewMatcher(nil, nil),
+		StatusFile: c.GetExecSyncStatusFile(),
+		Delay:      syncthing.DefaultFileWatcherDelay * time.Second,
+	}
+	if devConfig := c.Config().GetContainerDevConfigOrDefault(container); devConfig != nil && devConfig.Sync != nil {
+		// enable delete protection by default, the same as syncthing
+		s.IgnoreDelete = devConfig.Sync.DeleteProtection == nil || *devConfig.Sync.DeleteProtection
+		s.Matcher = execsync.NewMatcher(devConfig.Sync.FilePattern, devConfig.Sync.IgnoreFilePattern)
+		if len(devConfig.Sync.Folders) > 0 {
+			log.Warn("Sync folders are not supported by exec sync, only the dir associated is synced")
+		}
+		if devConfig.Sync.UseGitIgnore {
+			log.Warn(".gitignore files are not translated by exec sync, use ignoreFilePattern instead")
+		}
+	}
+
+	stopCh := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stopCh)
+	}()
+
+	log.Infof("Syncing %s to %s over exec", s.LocalDir, s.RemoteDir)
+	return s.Run(stopCh)
+}
+
+// execSyncContainerName returns name of DevContainer, ephemeral DevContainer's name is generated
+func (c *Controller) execSyncContainerName(podName, container string) string {
+	if c.DevModeType.IsEphemeralDevMode() {
+		if p, err := c.Client.GetPod(podName); err == nil {
+			devContainerName, _ := devContainerNamesOfPod(p)
+			return devContainerName
+		}
+	}
+	return c.GetDevContainerName(container)
+}
+
+// FindOutExecSyncProcess returns pid of the running exec sync process, 0 if not found
+func (c *Controller) FindOutExecSyncProcess() int {
+	bys, err := ioutil.ReadFile(c.GetExecSyncPidFile())
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(string(bys))
+	if err != nil {
+		return 0
+	}
+	// pid may be reused by other process after exec sync process exits
+	if p, err := ps.FindProcess(pid); err != nil || p == nil || !strings.Contains(p.Executable(), "nhctl") {
+		return 0
+	}
+	return pid
+}
+
+// StopExecSyncProcess terminates the exec sync process if it is running
+func (c *Controller) StopExecSyncProcess() error {
+	pid := c.FindOutExecSyncProcess()
+	if pid == 0 {
+		return nil
+	}
+	log.Infof("Stopping exec sync process(pid: %d)", pid)
+	if err := terminate.Terminate(pid, false); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Failed to terminate exec sync process(pid: %d)", pid))
+	}
+	_ = os.Remove(c.GetExecSyncPidFile())
+	return nil
+}
+
+// ExecSyncStatus returns status reported by the exec sync process
+func (c *Controller) ExecSyncStatus() *req.SyncthingStatus {
+	if c.FindOutExecSyncProcess() == 0 {
+		return &req.SyncthingStatus{
+			Status: req.Disconnected,
+			Msg:    "No exec sync process found",
+			Tips:   req.Identifier + "No exec sync process found, please resume file sync.",
+		}
+	}
+	status, err := execsync.ReadStatus(c.GetExecSyncStatusFile())
+	if err != nil {
+		return &req.SyncthingStatus{Status: req.Scanning, Msg: "Scanning local changed..."}
+	}
+	if status.Tips != "" {
+		status.Tips = req.Identifier + status.Tips
+	}
+	return status
+}
@@ -18,6 +18,18 @@ func (c *Controller) GetSyncThingPidFile() string {
 	return filepath.Join(c.GetSyncDir(), _const.DefaultApplicationSyncPidFile)
 }
 
+func (c *Controller) GetExecSyncPidFile() string {
+	return filepath.Join(c.GetSyncDir(), _const.DefaultExecSyncPidFile)
+}
+
+func (c *Controller) GetExecSyncStatusFile() string {
+	return filepath.Join(c.GetSyncDir(), _const.DefaultExecSyncStatusFile)
+}
+
+func (c *Controller) GetExecSyncLogFile() string {
+	return filepath.Join(c.GetSyncDir(), _const.DefaultExecSyncLogFile)
+}
+
 func (c *Controller) GetSyncDir() string {
 	dirPath := ""
 	if c.Type == base.Deployment {
@@ -6,7 +6,14 @@
 package controller
 
 import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	_const "nocalhost/internal/nhctl/const"
+	"nocalhost/internal/nhctl/profile"
+	"nocalhost/pkg/nhctl/log"
+	"strconv"
 )
 
 func (c *Controller) ListHPA() ([]autoscalingv1.HorizontalPodAutoscaler, error) {
@@ -28,3 +35,159 @@ func (c *Controller) ListHPA() ([]autoscalingv1.HorizontalPodAutoscaler, error)
 	}
 	return result, nil
 }
+
+// SuspendHPA pins all the hpa targeting the workload to 1 replica, so they won't fight the
+// scale patches of DevMode. Original replicas are recorded in the workload's annotations
+func (c *Controller) SuspendHPA() error {
+	log.Info("Suspending HPA...")
+	hl, err := c.ListHPA()
+	if err != nil {
+		return err
+	}
+	if len(hl) == 0 {
+		log.Info("No HPA found")
+		return nil
+	}
+
+	recorded, err := c.GetSuspendedHPA()
+	if err != nil {
+		return err
+	}
+	// Replicas recorded by previous DevMode which was not ended properly are the original ones
+	if len(recorded) == 0 {
+		// Record before pinning, or replicas can not be restored if pinning is interrupted
+		if err = c.recordSuspendedHPA(suspendedHPAOf(hl)); err != nil {
+			return err
+		}
+	}
+
+	// hpa are not in the result of dry run, only the record in the workload is
+	if c.dryRun != nil {
+		return nil
+	}
+	for _, h := range hl {
+		var one int32 = 1
+		h.Spec.MinReplicas = &one
+		h.Spec.MaxReplicas = 1
+		if _, err = c.Client.UpdateHPA(&h); err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to suspend hpa %s", h.Name))
+		} else {
+			log.Infof("HPA %s has been suspended", h.Name)
+		}
+	}
+	return nil
+}
+
+func suspendedHPAOf(hl []autoscalingv1.HorizontalPodAutoscaler) []*profile.SuspendedHPA {
+	suspended := make([]*profile.SuspendedHPA, 0)
+	for _, h := range hl {
+		suspended = append(
+			suspended, &profile.SuspendedHPA{
+				Name:        h.Name,
+				MinReplicas: h.Spec.MinReplicas,
+				MaxReplicas: h.Spec.MaxReplicas,
+			},
+		)
+	}
+	return suspended
+}
+
+// GetSuspendedHPA returns hpa suspended by DevMode, which are recorded in the workload's annotations
+func (c *Controller) GetSuspendedHPA() ([]*profile.SuspendedHPA, error) {
+	um, err := c.GetUnstructured()
+	if err != nil {
+		return nil, err
+	}
+	suspended := make([]*profile.SuspendedHPA, 0)
+	value, ok := um.GetAnnotations()[_const.NocalhostSuspendedHPAAnnotation]
+	if !ok {
+		return suspended, nil
+	}
+	if err = json.Unmarshal([]byte(value), &suspended); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Invalid annotation %s", _const.NocalhostSuspendedHPAAnnotation))
+	}
+	return suspended, nil
+}
+
+// RestoreHPA restores hpa to the replicas recorded while suspending,
+// and then removes the record from the workload if it still exists
+func (c *Controller) RestoreHPA(suspended []*profile.SuspendedHPA) {
+	if len(suspended) == 0 {
+		c.restoreDeprecatedHPA()
+		return
+	}
+
+	log.Info("Restoring HPA...")
+	for _, s := range suspended {
+		h, err := c.Client.GetHPA(s.Name)
+		if err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to get hpa %s", s.Name))
+			continue
+		}
+		h.Spec.MinReplicas = s.MinReplicas
+		h.Spec.MaxReplicas = s.MaxReplicas
+		if _, err = c.Client.UpdateHPA(h); err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to restore hpa %s", h.Name))
+		} else {
+			log.Infof("HPA %s has been restored", h.Name)
+		}
+	}
+
+	if err := c.recordSuspendedHPA(nil); err != nil {
+		log.WarnE(err, "Failed to remove suspended hpa record")
+	}
+}
+
+// restoreDeprecatedHPA restores hpa whose replicas are recorded in its own annotations by earlier nhctl
+func (c *Controller) restoreDeprecatedHPA() {
+	hl, err := c.ListHPA()
+	if err != nil {
+		log.WarnE(err, "Failed to find HPA")
+		return
+	}
+	for _, h := range hl {
+		max, ok := h.Annotations[_const.HPAOriginalMaxReplicasKey]
+		if !ok {
+			continue
+		}
+		maxInt, err := strconv.ParseInt(max, 0, 0)
+		if err != nil {
+			log.WarnE(err, "")
+			continue
+		}
+		h.Spec.MaxReplicas = int32(maxInt)
+		if min, ok := h.Annotations[_const.HPAOriginalMinReplicasKey]; ok {
+			minInt, err := strconv.ParseInt(min, 0, 0)
+			if err != nil {
+				log.WarnE(err, "")
+				continue
+			}
+			minInt32 := int32(minInt)
+			h.Spec.MinReplicas = &minInt32
+		}
+		delete(h.Annotations, _const.HPAOriginalMaxReplicasKey)
+		delete(h.Annotations, _const.HPAOriginalMinReplicasKey)
+		if _, err = c.Client.UpdateHPA(&h); err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to update hpa %s", h.Name))
+		} else {
+			log.Infof("HPA %s has been recovered", h.Name)
+		}
+	}
+}
+
+// recordSuspendedHPA records suspended hpa in the workload's annotations, the record is removed if suspended is nil
+func (c *Controller) recordSuspendedHPA(suspended []*profile.SuspendedHPA) error {
+	var value interface{}
+	if suspended != nil {
+		bys, _ := json.Marshal(suspended)
+		value = string(bys)
+	}
+	mBytes, _ := json.Marshal(
+		map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{_const.NocalhostSuspendedHPAAnnotation: value},
+			},
+		},
+	)
+	return c.patchResource(c.Type.String(), c.Name, string(mBytes), "merge")
+}
@@ -0,0 +1,113 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	secret_config "nocalhost/internal/nhctl/syncthing/secret-config"
+	"nocalhost/pkg/nhctl/log"
+)
+
+const (
+	// initDevDoneSignal DevContainer replacing an init container keeps running until this file is created,
+	// app containers of the pod are held until then
+	initDevDoneSignal = "/tmp/nocalhost-init-done"
+
+	// init containers run one by one, so syncthing can not run in a sidecar while DevContainer is running,
+	// it is copied from sidecar image and runs in DevContainer
+	syncthingBinVolume = "nocalhost-syncthing-bin"
+	syncthingBinDir    = "/nocalhost/bin"
+)
+
+// findInitContainerInPodSpec returns the init container named containerName, nil if not found
+func findInitContainerInPodSpec(podSpec *corev1.PodSpec, containerName string) *corev1.Container {
+	if containerName == "" {
+		return nil
+	}
+	for index, c := range podSpec.InitContainers {
+		if c.Name == containerName {
+			return &podSpec.InitContainers[index]
+		}
+	}
+	return nil
+}
+
+// convertToInitDevContainers makes DevContainer run syncthing itself and wait for initDevDoneSignal,
+// sidecar only copies syncthing to a volume shared with DevContainer
+func convertToInitDevContainers(devContainer, sidecarContainer *corev1.Container) corev1.Volume {
+	binVolume := corev1.Volume{
+		Name:         syncthingBinVolume,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}
+	binMount := corev1.VolumeMount{Name: syncthingBinVolume, MountPath: syncthingBinDir}
+
+	sidecarContainer.Command = []string{"/bin/sh", "-c"}
+	sidecarContainer.Args = []string{fmt.Sprintf("cp /bin/syncthing %s/", syncthingBinDir)}
+	sidecarContainer.SecurityContext = nil
+	sidecarContainer.VolumeMounts = append(sidecarContainer.VolumeMounts, binMount)
+
+	devContainer.Command = []string{
+		"/bin/sh", "-c", fmt.Sprintf(
+			"unset STGUIADDRESS && cp %s/* %s/ && (%s/syncthing -home %s &) ; while [ ! -f %s ]; do sleep 1; done",
+			secret_config.DefaultSyncthingSecretHome, secret_config.DefaultSyncthingHome,
+			syncthingBinDir, secret_config.DefaultSyncthingHome, initDevDoneSignal,
+		),
+	}
+	devContainer.Args = nil
+	devContainer.VolumeMounts = append(devContainer.VolumeMounts, binMount)
+	return binVolume
+}
+
+// holdInitDevContainer makes DevContainer wait for initDevDoneSignal without syncthing, which is used
+// while files are synced over the exec api
+func holdInitDevContainer(devContainer *corev1.Container) {
+	devContainer.Command = []string{
+		"/bin/sh", "-c", fmt.Sprintf("while [ ! -f %s ]; do sleep 1; done", initDevDoneSignal),
+	}
+	devContainer.Args = nil
+}
+
+// patchInitDevContainerToPodSpec replaces the init container with sidecar and DevContainer
+func patchInitDevContainerToPodSpec(podSpec *corev1.PodSpec, containerName string, devContainer,
+	sidecarContainer *corev1.Container) bool {
+	for index, c := range podSpec.InitContainers {
+		if c.Name != containerName {
+			continue
+		}
+		initContainers := make([]corev1.Container, 0, len(podSpec.InitContainers)+1)
+		initContainers = append(initContainers, podSpec.InitContainers[:index]...)
+		if sidecarContainer != nil {
+			initContainers = append(initContainers, *sidecarContainer)
+		}
+		initContainers = append(initContainers, *devContainer)
+		initContainers = append(initContainers, podSpec.InitContainers[index+1:]...)
+		podSpec.InitContainers = initContainers
+		return true
+	}
+	return false
+}
+
+// CompleteInitDevContainer makes DevContainer replacing an init container exit,
+// so that app containers of the dev pod can start
+func (c *Controller) CompleteInitDevContainer() error {
+	podName, err := c.GetDevModePodName()
+	if err != nil {
+		return err
+	}
+	pod, err := c.Client.GetPod(podName)
+	if err != nil {
+		return err
+	}
+	devContainerName, _ := devContainerNamesOfPod(pod)
+	if findInitContainerInPodSpec(&pod.Spec, devContainerName) == nil {
+		return errors.New(fmt.Sprintf("DevContainer of pod %s does not replace an init container", podName))
+	}
+
+	log.Infof("Completing init DevContainer %s of pod %s...", devContainerName, podName)
+	return c.Client.ExecWithIO(podName, devContainerName, []string{"touch", initDevDoneSignal}, nil, nil, nil)
+}
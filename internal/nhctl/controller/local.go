@@ -0,0 +1,159 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"nocalhost/internal/nhctl/daemon_client"
+	"nocalhost/internal/nhctl/daemon_common"
+	"nocalhost/internal/nhctl/daemon_server/command"
+	"nocalhost/internal/nhctl/model"
+	"nocalhost/internal/nhctl/profile"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localEnvFileHeader marks .env generated by nhctl, env files without it are never overwritten or removed
+const localEnvFileHeader = "# Generated by nhctl local DevMode, it will be removed while ending DevMode"
+
+// LocalController runs the service as a process in the developer's machine instead of a DevContainer,
+// traffic to the workload is reversed to the local process through vpn
+type LocalController struct {
+	*Controller
+}
+
+func (l *LocalController) ReplaceImage(ctx context.Context, ops *model.DevStartOptions) error {
+	l.Client.Context(ctx)
+
+	if len(ops.LocalSyncDir) == 0 {
+		return errors.New("Local DevMode needs a local directory to export env")
+	}
+
+	// Env must be resolved before reversing, pods of the workload are replaced by vpn
+	// volumeDir is owned by nhctl, volumes exported by the last local DevMode are cleaned up
+	volumeDir := l.localDevVolumeDir()
+	if err := os.RemoveAll(volumeDir); err != nil {
+		return errors.WithStack(err)
+	}
+	envs, mounts, err := l.ExportContainerEnv(ops.Container, volumeDir, false)
+	if err != nil {
+		return err
+	}
+	// Env in ContainerDevConfig has the highest priority
+	envMap := make(map[string]string, len(envs))
+	for _, env := range envs {
+		envMap[env.Name] = env.Value
+	}
+	for _, env := range l.GetDevContainerEnv(ops.Container).DevEnv {
+		envMap[env.Name] = env.Value
+	}
+
+	envFile := filepath.Join(ops.LocalSyncDir[0], ".env")
+	if err = writeLocalEnvFile(envFile, sortedEnv(envMap), mounts); err != nil {
+		return err
+	}
+	if err = l.UpdateSvcProfile(
+		func(svcProfile *profile.SvcProfileV2) error {
+			svcProfile.LocalDevEnvFile = envFile
+			svcProfile.LocalDevVolumeDir = volumeDir
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+	log.Infof("Env of %s %s has been exported to %s", l.Type, l.Name, envFile)
+
+	log.Infof("Reversing traffic of %s %s to local...", l.Type, l.Name)
+	return l.operateReverse(command.Connect)
+}
+
+// RollBack stops reversing traffic and removes files exported while starting DevMode
+func (l *LocalController) RollBack(reset bool) error {
+	err := l.operateReverse(command.DisConnect)
+	l.removeExportedFiles()
+	return err
+}
+
+// localDevVolumeDir returns the temp dir configmaps and secrets mounted by the workload are exported to
+func (l *LocalController) localDevVolumeDir() string {
+	return filepath.Join(os.TempDir(), "nocalhost", l.NameSpace, l.AppName, string(l.Type)+"-"+l.Name)
+}
+
+func (l *LocalController) operateReverse(action command.VPNOperation) error {
+	client, err := daemon_client.GetDaemonClient(false)
+	if err != nil {
+		return err
+	}
+	return client.SendVPNOperateCommand(
+		l.Client.KubeConfigFilePath(), l.NameSpace, action, fmt.Sprintf("%s/%s", l.Type, l.Name),
+		func(reader io.Reader) error {
+			stream := bufio.NewReader(reader)
+			for {
+				line, _, err := stream.ReadLine()
+				if errors.Is(err, io.EOF) {
+					return nil
+				} else if err != nil {
+					return errors.WithStack(err)
+				}
+				if strings.Contains(string(line), daemon_common.EndSignOK) {
+					return nil
+				} else if strings.Contains(string(line), daemon_common.EndSignFailed) {
+					return errors.New(fmt.Sprintf("Vpn %s of %s %s failed", action, l.Type, l.Name))
+				}
+				if len(line) > 0 {
+					log.Info(string(line))
+				}
+			}
+		},
+	)
+}
+
+func (l *LocalController) removeExportedFiles() {
+	svcProfile, err := l.GetProfile()
+	if err != nil {
+		log.WarnE(err, "")
+		return
+	}
+	if svcProfile.LocalDevEnvFile != "" {
+		if content, err := ioutil.ReadFile(svcProfile.LocalDevEnvFile); err == nil &&
+			strings.HasPrefix(string(content), localEnvFileHeader) {
+			if err = os.Remove(svcProfile.LocalDevEnvFile); err != nil {
+				log.WarnE(errors.WithStack(err), "Failed to remove exported env file")
+			}
+		}
+	}
+	if svcProfile.LocalDevVolumeDir != "" {
+		if err = os.RemoveAll(svcProfile.LocalDevVolumeDir); err != nil {
+			log.WarnE(errors.WithStack(err), "Failed to remove exported volumes")
+		}
+	}
+	_ = l.UpdateSvcProfile(
+		func(svcProfile *profile.SvcProfileV2) error {
+			svcProfile.LocalDevEnvFile = ""
+			svcProfile.LocalDevVolumeDir = ""
+			return nil
+		},
+	)
+}
+
+// writeLocalEnvFile writes envs in dotenv format, mount paths are listed as comments
+func writeLocalEnvFile(file string, envs []*profile.Env, mounts map[string]string) error {
+	if content, err := ioutil.ReadFile(file); err == nil {
+		if !strings.HasPrefix(string(content), localEnvFileHeader) {
+			return errors.New(fmt.Sprintf("%s already exists and is not generated by nhctl, please move it", file))
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return WriteEnvFile(&profile.EnvFile{Path: file}, localEnvFileHeader, envs, mounts)
+}
@@ -11,6 +11,12 @@ import (
 )
 
 func (c *Controller) BuildPodController() pod_controller.PodController {
+	if c.DevModeType.IsEphemeralDevMode() {
+		return &EphemeralController{Controller: c}
+	}
+	if c.DevModeType.IsLocalDevMode() {
+		return &LocalController{Controller: c}
+	}
 	if c.Type == base.Pod {
 		if c.DevModeType.IsDuplicateDevMode() {
 			return &DuplicateRawPodController{Controller: c}
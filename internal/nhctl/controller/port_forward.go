@@ -12,6 +12,7 @@ import (
 	"nocalhost/internal/nhctl/model"
 	"nocalhost/internal/nhctl/profile"
 	"nocalhost/internal/nhctl/utils"
+	k8sutil "nocalhost/pkg/nhctl/k8sutils"
 	"nocalhost/pkg/nhctl/log"
 	"strconv"
 	"strings"
@@ -181,6 +182,56 @@ func (c *Controller) PortForward(podName string, localPort, remotePort int, role
 	}
 }
 
+// PortForwardToService forwards localPort to a ready pod behind target svc/<name>:<port>,
+// another ready pod is selected by daemon while the pod goes away
+func (c *Controller) PortForwardToService(target string, localPort int) error {
+	name, port, err := utils.ParseServiceTarget(target)
+	if err != nil {
+		return err
+	}
+	svc, err := c.Client.GetService(name)
+	if err != nil {
+		return err
+	}
+	svcPort, err := k8sutil.FindServicePort(svc, port)
+	if err != nil {
+		return err
+	}
+
+	client, err := daemon_client.GetDaemonClient(utils.IsSudoUser())
+	if err != nil {
+		return err
+	}
+	nhResource := &model.NocalHostResource{
+		NameSpace:   c.NameSpace,
+		Application: c.AppName,
+		Service:     c.Name,
+		ServiceType: c.Type.String(),
+	}
+	if err = client.SendStartServicePortForwardCommand(
+		nhResource, localPort, int(svcPort.Port), target, c.AppMeta.NamespaceId,
+	); err != nil {
+		return err
+	}
+	return c.SetPortForwardedStatus(true)
+}
+
+// UpdatePortForwardPod records the pod port-forward is backed by currently
+func (c *Controller) UpdatePortForwardPod(localPort, remotePort int, podName string) error {
+	return c.UpdateSvcProfile(
+		func(svcProfile *profile.SvcProfileV2) error {
+			for _, portForward := range svcProfile.DevPortForwardList {
+				if portForward.LocalPort == localPort && portForward.RemotePort == remotePort {
+					portForward.PodName = podName
+					portForward.Updated = time.Now().Format("2006-01-02 15:04:05")
+					break
+				}
+			}
+			return nil
+		},
+	)
+}
+
 func (c *Controller) CheckIfPortForwardExists(localPort, remotePort int) (bool, error) {
 	svcProfile, err := c.GetProfile()
 	if err != nil {
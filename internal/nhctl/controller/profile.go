@@ -137,6 +137,7 @@ func (c *Controller) setSyncthingProfileEndStatus() error {
 			svcProfile.LocalSyncthingGUIPort = 0
 			svcProfile.PortForwarded = false
 			svcProfile.Syncing = false
+			svcProfile.SyncPaused = false
 			svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin = []string{}
 			return nil
 		},
@@ -0,0 +1,197 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	_const "nocalhost/internal/nhctl/const"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/pkg/nhctl/log"
+	"time"
+)
+
+const volumeSnapshotReadyTimeout = 10 * time.Minute
+
+// SnapshotPVCs takes a VolumeSnapshot of every pvc created for persistentVolumeDirs of the service,
+// snapshots are grouped by name and can be used to seed new pvcs or to restore pvcs
+func (c *Controller) SnapshotPVCs(name, snapshotClass string) ([]string, error) {
+	existing, err := c.Client.ListVolumeSnapshotsByLabels(map[string]string{_const.PersistentVolumeSnapshotLabel: name})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, errors.New(fmt.Sprintf("Snapshot %s already exists", name))
+	}
+
+	pvcs, err := c.persistentVolumeClaimsByDir()
+	if err != nil {
+		return nil, err
+	}
+	if len(pvcs) == 0 {
+		return nil, errors.New(fmt.Sprintf("No pvc of %s %s found", c.Type, c.Name))
+	}
+
+	snapshots := make([]string, 0, len(pvcs))
+	for dirHash, pvc := range pvcs {
+		labels := map[string]string{
+			_const.PersistentVolumeSnapshotLabel: name,
+			_const.AppLabel:                      c.AppName,
+			_const.ServiceLabel:                  c.Name,
+			_const.ServiceTypeLabel:              string(c.Type),
+			_const.PersistentVolumeDirLabel:      dirHash,
+		}
+		annotations := map[string]string{
+			_const.PersistentVolumeDirLabel:           pvc.Annotations[_const.PersistentVolumeDirLabel],
+			_const.PersistentVolumeCapacityAnnotation: pvc.Spec.Resources.Requests.Storage().String(),
+		}
+		if pvc.Spec.StorageClassName != nil {
+			annotations[_const.PersistentVolumeStorageClassAnnotation] = *pvc.Spec.StorageClassName
+		}
+
+		snapshotName := fmt.Sprintf("%s-%s", name, dirHash[:8])
+		log.Infof("Taking snapshot %s of pvc %s...", snapshotName, pvc.Name)
+		if _, err = c.Client.CreateVolumeSnapshot(snapshotName, pvc.Name, snapshotClass, labels, annotations); err != nil {
+			return snapshots, err
+		}
+		if err = c.Client.WaitVolumeSnapshotReady(snapshotName, volumeSnapshotReadyTimeout); err != nil {
+			return snapshots, err
+		}
+		snapshots = append(snapshots, snapshotName)
+	}
+	return snapshots, nil
+}
+
+// RestorePVCs replaces pvcs of the service with new ones populated from snapshots,
+// pvcs can not be restored while they are in use by DevMode
+func (c *Controller) RestorePVCs(name string) error {
+	if c.IsInReplaceDevMode() || c.IsInDuplicateDevMode() {
+		return errors.New(fmt.Sprintf("Pvcs of %s %s are in use, please end DevMode first", c.Type, c.Name))
+	}
+
+	snapshots, err := c.Client.ListVolumeSnapshotsByLabels(
+		map[string]string{
+			_const.PersistentVolumeSnapshotLabel: name,
+			_const.AppLabel:                      c.AppName,
+			_const.ServiceLabel:                  c.Name,
+			_const.ServiceTypeLabel:              string(c.Type),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return errors.New(fmt.Sprintf("Snapshot %s of %s %s not found", name, c.Type, c.Name))
+	}
+
+	pvcs, err := c.persistentVolumeClaimsByDir()
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots {
+		path := snapshot.GetAnnotations()[_const.PersistentVolumeDirLabel]
+		if path == "" {
+			log.Warnf("Snapshot %s has no persistent volume dir recorded, skipped", snapshot.GetName())
+			continue
+		}
+		if err = c.Client.WaitVolumeSnapshotReady(snapshot.GetName(), volumeSnapshotReadyTimeout); err != nil {
+			return err
+		}
+
+		labels := c.persistentVolumeLabels(path, c.DevModeType.IsDuplicateDevMode())
+		capacity, storageClass := snapshotPVCSpec(&snapshot)
+		pvc, err := c.Client.CreatePVCFromSnapshot(
+			fmt.Sprintf("%s-%d", c.AppName, time.Now().UnixNano()), labels,
+			map[string]string{_const.PersistentVolumeDirLabel: path}, capacity, storageClass, snapshot.GetName(),
+		)
+		if err != nil {
+			return err
+		}
+		log.Infof("Pvc %s of %s has been restored from snapshot %s", pvc.Name, path, snapshot.GetName())
+
+		if old, ok := pvcs[labels[_const.PersistentVolumeDirLabel]]; ok {
+			if err = c.Client.DeletePVC(old.Name); err != nil {
+				return err
+			}
+			log.Infof("Pvc %s replaced has been deleted", old.Name)
+		}
+	}
+	return nil
+}
+
+// findPersistentVolumeSnapshot returns the VolumeSnapshot of the dir in snapshot and capacity the pvc needs,
+// a pvc can not be smaller than the snapshot it is populated from
+func (c *Controller) findPersistentVolumeSnapshot(snapshot, path, capacity string) (string, string, error) {
+	snapshots, err := c.Client.ListVolumeSnapshotsByLabels(
+		map[string]string{
+			_const.PersistentVolumeSnapshotLabel: snapshot,
+			_const.PersistentVolumeDirLabel:      utils.Sha1ToString(path),
+		},
+	)
+	if err != nil {
+		return "", capacity, err
+	}
+	if len(snapshots) == 0 {
+		return "", capacity, errors.New(fmt.Sprintf("Snapshot %s has no VolumeSnapshot for %s", snapshot, path))
+	}
+
+	snapshotCapacity, _ := snapshotPVCSpec(&snapshots[0])
+	if q, err := resource.ParseQuantity(capacity); err == nil {
+		if sq, err := resource.ParseQuantity(snapshotCapacity); err == nil && sq.Cmp(q) > 0 {
+			capacity = snapshotCapacity
+		}
+	}
+	log.Infof("Pvc for %s will be populated from snapshot %s", path, snapshots[0].GetName())
+	return snapshots[0].GetName(), capacity, nil
+}
+
+// persistentVolumeClaimsByDir returns pvcs of the service in current DevModeType, keyed by hash of their dirs
+func (c *Controller) persistentVolumeClaimsByDir() (map[string]corev1.PersistentVolumeClaim, error) {
+	pvcs, err := c.GetPVCsBySvc()
+	if err != nil {
+		return nil, err
+	}
+	duplicateDevMode := c.DevModeType.IsDuplicateDevMode()
+	result := make(map[string]corev1.PersistentVolumeClaim, 0)
+	for _, pvc := range pvcs {
+		dirHash := pvc.Labels[_const.PersistentVolumeDirLabel]
+		if dirHash == "" {
+			continue
+		}
+		// pvcs of duplicate DevMode belong to their own identifier
+		if identifier, ok := pvc.Labels[IdentifierKey]; ok != duplicateDevMode ||
+			(duplicateDevMode && identifier != c.Identifier) {
+			continue
+		}
+		result[dirHash] = pvc
+	}
+	return result, nil
+}
+
+func snapshotPVCSpec(snapshot *unstructured.Unstructured) (string, *string) {
+	annotations := snapshot.GetAnnotations()
+	capacity := annotations[_const.PersistentVolumeCapacityAnnotation]
+	if restoreSize, ok, _ := unstructured.NestedString(snapshot.Object, "status", "restoreSize"); ok {
+		if capacity == "" {
+			capacity = restoreSize
+		} else if q, err := resource.ParseQuantity(capacity); err == nil {
+			if rq, err := resource.ParseQuantity(restoreSize); err == nil && rq.Cmp(q) > 0 {
+				capacity = restoreSize
+			}
+		}
+	}
+	if capacity == "" {
+		capacity = "10Gi"
+	}
+	var storageClass *string
+	if sc, ok := annotations[_const.PersistentVolumeStorageClassAnnotation]; ok && sc != "" {
+		storageClass = &sc
+	}
+	return capacity, storageClass
+}
@@ -14,7 +14,6 @@ import (
 	_const "nocalhost/internal/nhctl/const"
 	"nocalhost/internal/nhctl/model"
 	"nocalhost/pkg/nhctl/log"
-	"time"
 )
 
 //const originalPodDefine = "nocalhost.dev.origin.pod.define"
@@ -45,12 +44,8 @@ func (r *RawPodController) ReplaceImage(ctx context.Context, ops *model.DevStart
 		return errors.WithStack(err)
 	}
 
-	if originalPod.Annotations == nil {
-		originalPod.Annotations = make(map[string]string, 0)
-	}
+	originalPod.Annotations = r.getDevContainerAnnotations(ops.Container, originalPod.Annotations)
 	originalPod.Annotations[_const.OriginWorkloadDefinition] = string(bys)
-	originalPod.Annotations[_const.NocalhostDevContainerAnnotations] =
-		r.GetDevContainerName(ops.Container)
 
 	devContainer, sideCarContainer, devModeVolumes, err :=
 		r.genContainersAndVolumes(&originalPod.Spec, ops.Container, ops.DevImage, ops.StorageClass, false)
@@ -61,21 +56,16 @@ func (r *RawPodController) ReplaceImage(ctx context.Context, ops *model.DevStart
 	patchDevContainerToPodSpec(&originalPod.Spec, ops.Container, devContainer, sideCarContainer, devModeVolumes)
 
 	log.Info("Delete original pod...")
-	if err = r.Client.DeletePodByName(r.Name, 0); err != nil {
-		return err
-	}
-
-	time.Sleep(1 * time.Second)
-
-	log.Info("Create dev pod...")
-	if _, err = r.Client.CreatePod(originalPod); err != nil {
+	if err = r.recreatePod(originalPod); err != nil {
 		return err
 	}
 
 	r.patchAfterDevContainerReplaced(ops.Container, originalPod.Kind, originalPod.Name)
 
-	r.waitDevPodToBeReady()
-	return nil
+	if r.dryRun != nil {
+		return nil
+	}
+	return r.waitDevPodToBeReady()
 }
 
 func (r *RawPodController) RollBack(reset bool) error {
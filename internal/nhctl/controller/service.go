@@ -28,6 +28,7 @@ import (
 	"nocalhost/pkg/nhctl/log"
 	"strings"
 	"sync/atomic"
+	"text/template"
 	"time"
 )
 
@@ -45,6 +46,9 @@ type Controller struct {
 	config           *profile.ServiceConfigV2
 	DevModeAction    base.DevModeAction
 	devModePodLabels kblabels.Set
+
+	// changes to the cluster are applied to it instead while dry running
+	dryRun *DevModeDryRunResult
 }
 
 type jsonPatch struct {
@@ -93,19 +97,38 @@ func (c *Controller) IsInDuplicateDevModeStarting() bool {
 	return c.AppMeta.CheckIfSvcDeveloping(c.Name, c.Identifier, c.Type, profile.DuplicateDevMode) == appmeta.STARTING
 }
 
+func (c *Controller) IsInEphemeralDevMode() bool {
+	return c.AppMeta.CheckIfSvcDeveloping(c.Name, c.Identifier, c.Type, profile.EphemeralDevMode) != appmeta.NONE
+}
+
+func (c *Controller) IsInEphemeralDevModeStarting() bool {
+	return c.AppMeta.CheckIfSvcDeveloping(c.Name, c.Identifier, c.Type, profile.EphemeralDevMode) == appmeta.STARTING
+}
+
+func (c *Controller) IsInLocalDevMode() bool {
+	return c.AppMeta.CheckIfSvcDeveloping(c.Name, c.Identifier, c.Type, profile.LocalDevMode) != appmeta.NONE
+}
+
+func (c *Controller) IsInLocalDevModeStarting() bool {
+	return c.AppMeta.CheckIfSvcDeveloping(c.Name, c.Identifier, c.Type, profile.LocalDevMode) == appmeta.STARTING
+}
+
 func (c *Controller) IsInDevMode() bool {
-	return c.IsInDuplicateDevMode() || c.IsInReplaceDevMode()
+	return c.IsInDuplicateDevMode() || c.IsInReplaceDevMode() || c.IsInEphemeralDevMode() || c.IsInLocalDevMode()
 }
 
 func (c *Controller) IsInDevModeStarting() bool {
-	return c.IsInDuplicateDevModeStarting() || c.IsInReplaceDevModeStarting()
+	return c.IsInDuplicateDevModeStarting() || c.IsInReplaceDevModeStarting() ||
+		c.IsInEphemeralDevModeStarting() || c.IsInLocalDevModeStarting()
 }
 
 // IsProcessor Check if service is developing in this device
 func (c *Controller) IsProcessor() bool {
 	return c.AppMeta.SvcDevModePossessor(
 		c.Name, c.Type, c.Identifier, profile.DuplicateDevMode,
-	) || c.AppMeta.SvcDevModePossessor(c.Name, c.Type, c.Identifier, profile.ReplaceDevMode)
+	) || c.AppMeta.SvcDevModePossessor(c.Name, c.Type, c.Identifier, profile.ReplaceDevMode) ||
+		c.AppMeta.SvcDevModePossessor(c.Name, c.Type, c.Identifier, profile.EphemeralDevMode) ||
+		c.AppMeta.SvcDevModePossessor(c.Name, c.Type, c.Identifier, profile.LocalDevMode)
 }
 
 func (c *Controller) GetCurrentDevModeType() profile.DevModeType {
@@ -201,6 +224,11 @@ func (c *Controller) GetDescription() *profile.SvcProfileV2 {
 	svcProfile := appProfile.SvcProfileV2(c.Name, string(c.Type))
 	if svcProfile != nil {
 		appmeta.FillingExtField(svcProfile, c.AppMeta, c.AppName, c.NameSpace, appProfile.Identifier)
+		if svcProfile.Developing {
+			if suspended, err := c.GetSuspendedHPA(); err == nil && len(suspended) > 0 {
+				svcProfile.SuspendedHPA = suspended
+			}
+		}
 		return svcProfile
 	}
 	return nil
@@ -237,6 +265,10 @@ func (c *Controller) getDevContainerAnnotations(devContainer string, originAnnos
 	}
 
 	originAnnos[_const.NocalhostDevContainerAnnotations] = c.GetDevContainerName(devContainer)
+	// there is no sidecar in exec sync, DevContainer is waited for instead
+	if c.IsExecSync(devContainer) {
+		originAnnos[_const.NocalhostDevSidecarAnnotations] = c.GetDevContainerName(devContainer)
+	}
 	return originAnnos
 }
 
@@ -248,11 +280,13 @@ func (c *Controller) getDuplicateResourceName() string {
 func (c *Controller) patchAfterDevContainerReplaced(containerName, resourceType, resourceName string) {
 	for _, patch := range c.config.GetContainerDevConfigOrDefault(containerName).Patches {
 		log.Infof("Patching %s", patch.Patch)
-		if err := c.Client.Patch(resourceType, resourceName, patch.Patch, patch.Type); err != nil {
+		if err := c.patchResource(resourceType, resourceName, patch.Patch, patch.Type); err != nil {
 			log.WarnE(err, "")
 		}
 	}
-	<-time.Tick(time.Second)
+	if c.dryRun == nil {
+		<-time.Tick(time.Second)
+	}
 }
 
 func (c *Controller) getGeneratedDeploymentName() string {
@@ -276,6 +310,7 @@ func (c *Controller) PatchDevModeManifest(ctx context.Context, ops *model.DevSta
 		return err
 	}
 
+	live := unstructuredObj.DeepCopy()
 	RemoveUselessInfo(unstructuredObj)
 
 	var originalSpecJson []byte
@@ -297,15 +332,19 @@ func (c *Controller) PatchDevModeManifest(ctx context.Context, ops *model.DevSta
 
 	mBytes, _ := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": map[string]string{_const.OriginWorkloadDefinition: string(originalSpecJson)}}})
 
-	if err = c.Client.Patch(c.Type.String(), c.Name, string(mBytes), "merge"); err != nil {
+	if err = c.patchResource(c.Type.String(), c.Name, string(mBytes), "merge"); err != nil {
 		return err
 	}
 	log.Info("Original manifest recorded")
 
 	log.Info("Executing ScalePatches...")
 	for _, item := range c.DevModeAction.ScalePatches {
-		log.Infof("Patching %s(%s)", item.Patch, item.Type)
-		if err := c.Client.Patch(c.Type.String(), c.Name, item.Patch, item.Type); err != nil {
+		patch, err := renderPatch(item.Patch, live)
+		if err != nil {
+			return err
+		}
+		log.Infof("Patching %s(%s)", patch, item.Type)
+		if err := c.patchResource(c.Type.String(), c.Name, patch, item.Type); err != nil {
 			return err
 		}
 	}
@@ -323,81 +362,31 @@ func (c *Controller) PatchDevModeManifest(ctx context.Context, ops *model.DevSta
 	if !c.DevModeAction.Create {
 
 		log.Info("Update strategy to RECREATE")
-		specPath := "/spec/strategy"
-		strategy := &appsv1.DeploymentStrategy{
-			Type:          appsv1.RecreateDeploymentStrategyType,
-			RollingUpdate: nil,
-		}
-		jsonPatches := make([]jsonPatch, 0)
-		jsonPatches = append(
-			jsonPatches, jsonPatch{
-				Op:    "replace",
-				Path:  specPath,
-				Value: strategy,
-			},
-		)
-		bys, _ := json.Marshal(jsonPatches)
-		if err = c.Client.Patch(c.Type.String(), c.Name, string(bys), "json"); err != nil {
+		if err = c.patchResource(c.Type.String(), c.Name, recreateStrategyPatch(), "json"); err != nil {
 			log.WarnE(err, "")
 		}
 
 		log.Info("Patching development container...")
-		specPath = c.DevModeAction.PodTemplatePath + "/spec"
-		jsonPatches = make([]jsonPatch, 0)
-		jsonPatches = append(
-			jsonPatches, jsonPatch{
-				Op:    "replace",
-				Path:  specPath,
-				Value: podSpec,
-			},
-		)
-		bys, _ = json.Marshal(jsonPatches)
-
-		if err = c.Client.Patch(c.Type.String(), c.Name, string(bys), "json"); err != nil {
+		if err = c.patchResource(
+			c.Type.String(), c.Name, replacePatch(c.DevModeAction.PodTemplatePath+"/spec", podSpec), "json",
+		); err != nil {
 			return err
 		}
 
 		log.Info("Patching development annotations...")
-		specPath = c.DevModeAction.PodTemplatePath + "/metadata/annotations"
-		jsonPatches = make([]jsonPatch, 0)
-		jsonPatches = append(
-			jsonPatches, jsonPatch{
-				Op:    "replace",
-				Path:  specPath,
-				Value: c.getDevContainerAnnotations(ops.Container, podTemplate.GetAnnotations()),
-			},
-		)
-		bys, _ = json.Marshal(jsonPatches)
-		patchContent := string(bys)
-
-		if err = c.Client.Patch(c.Type.String(), c.Name, patchContent, "json"); err != nil {
+		if err = c.patchResource(
+			c.Type.String(), c.Name, replacePatch(
+				c.DevModeAction.PodTemplatePath+"/metadata/annotations",
+				c.getDevContainerAnnotations(ops.Container, podTemplate.GetAnnotations()),
+			), "json",
+		); err != nil {
 			return err
 		}
 
 		c.patchAfterDevContainerReplaced(ops.Container, c.Type.String(), c.Name)
 	} else {
-		// Some workload's pod may not have labels, such as cronjob, we need to give it one
-		if len(podTemplate.Labels) == 0 {
-			podTemplate.Labels = c.getGeneratedDeploymentLabels()
-		}
-
-		podTemplate.Annotations = c.getDevContainerAnnotations(ops.Container, podTemplate.Annotations)
-		generatedDeployment := &appsv1.Deployment{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:   c.getGeneratedDeploymentName(),
-				Labels: c.getGeneratedDeploymentLabels(),
-			},
-			Spec: appsv1.DeploymentSpec{
-				Selector: &metav1.LabelSelector{
-					MatchLabels: podTemplate.Labels,
-				},
-				Template: *podTemplate,
-			},
-		}
-		generatedDeployment.Spec.Template.Spec.RestartPolicy = v1.RestartPolicyAlways
-		generatedDeployment.Spec.Strategy.RollingUpdate = nil
-		generatedDeployment.Spec.Strategy.Type = appsv1.RecreateDeploymentStrategyType
-		if _, err = c.Client.CreateDeployment(generatedDeployment); err != nil {
+		generatedDeployment := c.genGeneratedDeployment(podTemplate, ops.Container)
+		if err = c.createDeployment(generatedDeployment); err != nil {
 			return err
 		}
 		c.patchAfterDevContainerReplaced(ops.Container, "deployment", generatedDeployment.Name)
@@ -406,11 +395,55 @@ func (c *Controller) PatchDevModeManifest(ctx context.Context, ops *model.DevSta
 	delete(podTemplate.Labels, "pod-template-hash")
 	c.devModePodLabels = podTemplate.Labels
 
-	c.waitDevPodToBeReady()
-	return nil
+	if c.dryRun != nil {
+		return nil
+	}
+	return c.waitDevPodToBeReady()
 }
 
-func (c *Controller) waitDevPodToBeReady() {
+// genGeneratedDeployment generates a deployment to run DevContainer instead of patching the workload
+func (c *Controller) genGeneratedDeployment(podTemplate *v1.PodTemplateSpec, container string) *appsv1.Deployment {
+	// Some workload's pod may not have labels, such as cronjob, we need to give it one
+	if len(podTemplate.Labels) == 0 {
+		podTemplate.Labels = c.getGeneratedDeploymentLabels()
+	}
+
+	podTemplate.Annotations = c.getDevContainerAnnotations(container, podTemplate.Annotations)
+	generatedDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   c.getGeneratedDeploymentName(),
+			Labels: c.getGeneratedDeploymentLabels(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: podTemplate.Labels,
+			},
+			Template: *podTemplate,
+		},
+	}
+	generatedDeployment.Spec.Template.Spec.RestartPolicy = v1.RestartPolicyAlways
+	generatedDeployment.Spec.Strategy.RollingUpdate = nil
+	generatedDeployment.Spec.Strategy.Type = appsv1.RecreateDeploymentStrategyType
+	return generatedDeployment
+}
+
+func recreateStrategyPatch() string {
+	return replacePatch(
+		"/spec/strategy", &appsv1.DeploymentStrategy{
+			Type:          appsv1.RecreateDeploymentStrategyType,
+			RollingUpdate: nil,
+		},
+	)
+}
+
+func replacePatch(path string, value interface{}) string {
+	bys, _ := json.Marshal([]jsonPatch{{Op: "replace", Path: path, Value: value}})
+	return string(bys)
+}
+
+// waitDevPodToBeReady waits until DevContainer and sidecar of the dev pod are ready, an error is
+// returned if ephemeral DevContainer exits, which will never be ready
+func (c *Controller) waitDevPodToBeReady() error {
 	gvr := c.Client.ResourceFor("pod", false)
 	gvk, gvkErr := c.Client.KindFor(gvr)
 	if gvkErr != nil {
@@ -439,6 +472,7 @@ func (c *Controller) waitDevPodToBeReady() {
 	var currentPod atomic.Value
 
 	readyChan := make(chan struct{}, 0)
+	errChan := make(chan error, 1)
 	stopChan := make(chan struct{}, 0)
 	defer close(stopChan)
 
@@ -466,6 +500,11 @@ func (c *Controller) waitDevPodToBeReady() {
 
 					if _, err := findDevPodName(pod); err == nil {
 						readyChan <- struct{}{}
+					} else if err = terminatedEphemeralDevContainer(&pod); err != nil {
+						select {
+						case errChan <- err:
+						default:
+						}
 					}
 					return
 				}
@@ -517,7 +556,10 @@ func (c *Controller) waitDevPodToBeReady() {
 	select {
 	case _, _ = <-stopChan:
 	case <-readyChan:
+	case err := <-errChan:
+		return err
 	}
+	return nil
 }
 
 func (c *Controller) CheckDevModePodIsRunning() (string, error) {
@@ -601,3 +643,20 @@ func GetDefaultPodName(ctx context.Context, p *Controller) (string, error) {
 		}
 	}
 }
+
+// renderPatch fills the fields of the workload referenced in patch,
+// such as {{ .status.latestReadyRevisionName }}
+func renderPatch(patch string, u *unstructured.Unstructured) (string, error) {
+	if !strings.Contains(patch, "{{") {
+		return patch, nil
+	}
+	tpl, err := template.New("patch").Option("missingkey=error").Parse(patch)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("Unable to parse patch %s", patch))
+	}
+	var sb strings.Builder
+	if err = tpl.Execute(&sb, u.Object); err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("Unable to render patch %s", patch))
+	}
+	return sb.String(), nil
+}
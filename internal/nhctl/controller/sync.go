@@ -20,6 +20,8 @@ import (
 
 func (c *Controller) StopFileSyncOnly() error {
 
+	utils.Should(c.StopExecSyncProcess())
+
 	pf, err := c.GetPortForwardForSync()
 	utils.Should(err)
 	if pf != nil {
@@ -0,0 +1,191 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"nocalhost/internal/nhctl/execsync"
+	"nocalhost/internal/nhctl/syncthing"
+	"nocalhost/internal/nhctl/syncthing/network/req"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ListSyncConflicts lists conflict copies left by syncthing in sync dirs and the remote,
+// files ignored by sync are not walked
+func (c *Controller) ListSyncConflicts() ([]*req.SyncConflict, error) {
+	svcProfile, err := c.GetProfile()
+	if err != nil {
+		return nil, err
+	}
+	folders, err := c.syncFolders(svcProfile.OriginDevContainer, svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin)
+	if err != nil {
+		return nil, err
+	}
+	ignores := c.syncIgnores(svcProfile.OriginDevContainer, svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin)
+	client := c.NewSyncthingHttpClient(2)
+	conflicts := make([]*req.SyncConflict, 0)
+	for _, folder := range folders {
+		found, err := client.ForFolder(folder.ID()).Conflicts(folder.LocalPath, ignores[filepath.Clean(folder.LocalPath)])
+		conflicts = append(conflicts, found...)
+		if err != nil {
+			return conflicts, err
+		}
+	}
+	return conflicts, nil
+}
+
+// ResolveSyncConflict keeps the local or remote version of file, file can be the conflicting
+// file or one of its conflict copies. The version kept is restored from a copy if it lost,
+// then copies of file are removed and synced to the remote
+func (c *Controller) ResolveSyncConflict(file string, keepLocal bool) error {
+	svcProfile, err := c.GetProfile()
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	if original, _, _, ok := req.ParseConflictCopy(abs); ok {
+		abs = filepath.FromSlash(original)
+	}
+
+	folders, err := c.syncFolders(svcProfile.OriginDevContainer, svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin)
+	if err != nil {
+		return err
+	}
+	var folder *syncthing.Folder
+	for _, f := range folders {
+		if rel, err := filepath.Rel(f.LocalPath, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			folder = f
+			break
+		}
+	}
+	if folder == nil {
+		return errors.New(fmt.Sprintf("%s is not in sync dirs", file))
+	}
+	localDir := folder.LocalPath
+
+	copies, err := conflictCopiesOf(abs)
+	if err != nil {
+		return err
+	}
+	client := c.NewSyncthingHttpClient(2).ForFolder(folder.ID())
+	if len(copies) == 0 {
+		rel, _ := filepath.Rel(localDir, abs)
+		if conflicts, _ := client.Conflicts("", nil); len(conflicts) > 0 {
+			for _, conflict := range conflicts {
+				if conflict.File == filepath.ToSlash(rel) {
+					return errors.New(
+						fmt.Sprintf(
+							"Conflict copies of %s are only in the remote, "+
+								"use `nhctl sync-status --override` to keep local files", file,
+						),
+					)
+				}
+			}
+		}
+		return errors.New(fmt.Sprintf("No conflict found for %s", file))
+	}
+
+	keep := req.ConflictModifiedByRemote
+	if keepLocal {
+		keep = req.ConflictModifiedByLocal
+	}
+	remoteDevice := req.ShortDeviceID(syncthing.DefaultRemoteDeviceID)
+
+	// copies are sorted by time, the latest copy of the side kept wins
+	restore := ""
+	for _, copyPath := range copies {
+		_, device, _, _ := req.ParseConflictCopy(copyPath)
+		modifiedBy := req.ConflictModifiedByLocal
+		if device == remoteDevice {
+			modifiedBy = req.ConflictModifiedByRemote
+		}
+		if modifiedBy == keep {
+			restore = copyPath
+		}
+	}
+
+	for _, copyPath := range copies {
+		if copyPath == restore {
+			log.Infof("Restoring %s from %s", abs, copyPath)
+			if err = os.Rename(copyPath, abs); err != nil {
+				return errors.Wrap(err, "")
+			}
+			continue
+		}
+		log.Infof("Removing %s", copyPath)
+		if err = os.Remove(copyPath); err != nil {
+			return errors.Wrap(err, "")
+		}
+	}
+
+	// sync the resolution to remote now
+	if err = client.Scan(); err != nil {
+		log.WarnE(err, "Failed to rescan files after resolving conflicts")
+	}
+	return nil
+}
+
+// syncIgnores returns funcs deciding if a path relative to a local sync dir is ignored by sync,
+// which are keyed by the local dir
+func (c *Controller) syncIgnores(container string, localSyncDir []string) map[string]func(string) bool {
+	ignores := make(map[string]func(string) bool, 0)
+	if len(localSyncDir) == 0 {
+		return ignores
+	}
+	for _, target := range c.pullTargets(container, localSyncDir[0]) {
+		ignores[filepath.Clean(target.localDir)] = target.matcher.Ignored
+	}
+
+	devConfig := c.Config().GetContainerDevConfigOrDefault(container)
+	if devConfig == nil || devConfig.Sync == nil || !devConfig.Sync.UseGitIgnore {
+		return ignores
+	}
+	// negated rules of .gitignore are dropped, files they match are walked as other files of ignored dirs are not
+	rules := make([]string, 0)
+	for _, rule := range syncthing.GitIgnoreRules(localSyncDir[0]) {
+		if !strings.HasPrefix(rule, "!") {
+			rules = append(rules, rule)
+		}
+	}
+	dir := filepath.Clean(localSyncDir[0])
+	configured, gitIgnored := ignores[dir], execsync.NewMatcher(nil, rules)
+	ignores[dir] = func(rel string) bool {
+		return configured(rel) || gitIgnored.Ignored(rel)
+	}
+	return ignores
+}
+
+// conflictCopiesOf returns conflict copies of file sorted by time
+func conflictCopiesOf(file string) ([]string, error) {
+	infos, err := ioutil.ReadDir(filepath.Dir(file))
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	copies := make([]string, 0)
+	times := map[string]string{}
+	for _, info := range infos {
+		copyPath := filepath.Join(filepath.Dir(file), info.Name())
+		if original, _, time, ok := req.ParseConflictCopy(copyPath); ok && filepath.FromSlash(original) == file {
+			copies = append(copies, copyPath)
+			times[copyPath] = time
+		}
+	}
+	sort.Slice(
+		copies, func(i, j int) bool {
+			return times[copies[i]] < times[copies[j]]
+		},
+	)
+	return copies, nil
+}
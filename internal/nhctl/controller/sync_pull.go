@@ -0,0 +1,99 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"nocalhost/internal/nhctl/execsync"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// pullTarget is a remote dir synced with a local dir, and patterns of it
+type pullTarget struct {
+	remoteDir string
+	localDir  string
+	matcher   *execsync.Matcher
+}
+
+// PullSyncFiles copies remotePath of DevContainer to localPath, a relative remotePath is relative to
+// the work dir. remotePath must be in a synced dir, and localPath defaults to the same path in the
+// local dir synced with it. Files ignored by sync patterns are skipped
+func (c *Controller) PullSyncFiles(container, remotePath, localPath string) (int, error) {
+	svcProfile, err := c.GetProfile()
+	if err != nil {
+		return 0, err
+	}
+	if len(svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin) == 0 {
+		return 0, errors.New("No local sync dir is associated")
+	}
+	if !path.IsAbs(remotePath) {
+		remotePath = path.Join(c.GetWorkDir(container), remotePath)
+	}
+	remotePath = path.Clean(remotePath)
+
+	var target *pullTarget
+	for _, t := range c.pullTargets(container, svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin[0]) {
+		// the deepest synced dir wins
+		if isSubPath(t.remoteDir, remotePath) && (target == nil || len(t.remoteDir) > len(target.remoteDir)) {
+			target = t
+		}
+	}
+	if target == nil {
+		return 0, errors.New(fmt.Sprintf("%s is not in a synced dir", remotePath))
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(remotePath, target.remoteDir), "/")
+	if rel != "" && target.matcher.Ignored(rel) {
+		return 0, errors.New(fmt.Sprintf("%s is ignored by sync patterns", remotePath))
+	}
+	if localPath == "" {
+		localPath = filepath.Join(target.localDir, filepath.FromSlash(rel))
+	}
+
+	podName, err := c.GetDevModePodName()
+	if err != nil {
+		return 0, err
+	}
+	devContainer := c.execSyncContainerName(podName, container)
+	return execsync.Pull(
+		func(command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+			return c.Client.ExecWithIO(podName, devContainer, command, stdin, stdout, stderr)
+		}, remotePath, localPath, rel, target.matcher,
+	)
+}
+
+// pullTargets returns the work dir synced with localSyncDir, and sync folders of config
+func (c *Controller) pullTargets(container, localSyncDir string) []*pullTarget {
+	targets := []*pullTarget{
+		{remoteDir: path.Clean(c.GetWorkDir(container)), localDir: localSyncDir, matcher: execsync.NewMatcher(nil, nil)},
+	}
+	devConfig := c.Config().GetContainerDevConfigOrDefault(container)
+	if devConfig == nil || devConfig.Sync == nil {
+		return targets
+	}
+	targets[0].matcher = execsync.NewMatcher(devConfig.Sync.FilePattern, devConfig.Sync.IgnoreFilePattern)
+	for _, f := range devConfig.Sync.Folders {
+		localPath := f.LocalPath
+		if !filepath.IsAbs(localPath) {
+			localPath = filepath.Join(localSyncDir, localPath)
+		}
+		targets = append(
+			targets, &pullTarget{
+				remoteDir: path.Clean(f.RemotePath),
+				localDir:  localPath,
+				matcher:   execsync.NewMatcher(f.FilePattern, f.IgnoreFilePattern),
+			},
+		)
+	}
+	return targets
+}
+
+func isSubPath(dir, p string) bool {
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
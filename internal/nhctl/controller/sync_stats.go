@@ -0,0 +1,78 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"nocalhost/internal/nhctl/nocalhost"
+	"nocalhost/internal/nhctl/profile"
+	"nocalhost/internal/nhctl/syncthing/network/req"
+)
+
+// PauseSync pauses or resumes file sync of all folders, the state is kept while syncthing is restarted
+func (c *Controller) PauseSync(paused bool) error {
+	svcProfile, err := c.GetProfile()
+	if err != nil {
+		return err
+	}
+	if !svcProfile.Syncing {
+		return errors.New("File sync is not running")
+	}
+	if c.IsExecSync(svcProfile.OriginDevContainer) {
+		return errors.New("Pausing file sync is not supported by sync type exec")
+	}
+
+	client := c.NewSyncthingHttpClient(2)
+	for _, folder := range c.SyncFolderIDs() {
+		if err = client.ForFolder(folder).PauseFolder(paused); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to set paused of folder %s to %v", folder, paused))
+		}
+	}
+	return c.UpdateSvcProfile(
+		func(svcProfile *profile.SvcProfileV2) error {
+			svcProfile.SyncPaused = paused
+			return nil
+		},
+	)
+}
+
+func (c *Controller) syncStatsKey() string {
+	return fmt.Sprintf("%s.%s.%s.%s.sync.stats", c.NameSpace, c.AppName, c.Type, c.Name)
+}
+
+// SyncStats returns statistics of the current file sync session recorded
+func (c *Controller) SyncStats() (*req.SyncStats, error) {
+	value, err := nocalhost.GetKey(c.NameSpace, c.AppName, c.AppMeta.NamespaceId, c.syncStatsKey())
+	if err != nil {
+		return nil, err
+	}
+	stats := &req.SyncStats{}
+	if value == "" {
+		return stats, nil
+	}
+	if err = json.Unmarshal([]byte(value), stats); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	return stats, nil
+}
+
+// CollectSyncStats updates statistics of the current session from syncthing and records them
+func (c *Controller) CollectSyncStats() error {
+	stats, err := c.SyncStats()
+	if err != nil {
+		return err
+	}
+	if err = c.NewSyncthingHttpClient(2).CollectStats(stats); err != nil {
+		return errors.Wrap(err, "Failed to collect sync stats")
+	}
+	bys, err := json.Marshal(stats)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	return nocalhost.UpdateKey(c.NameSpace, c.AppName, c.AppMeta.NamespaceId, c.syncStatsKey(), string(bys))
+}
@@ -18,6 +18,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"nocalhost/internal/nhctl/syncthing"
 	"nocalhost/pkg/nhctl/log"
@@ -27,7 +28,6 @@ func (c *Controller) NewSyncthing(container string, localSyncDir []string, syncD
 	*syncthing.Syncthing, error,
 ) {
 	var err error
-	remotePath := c.GetWorkDir(container)
 	appProfile, err := c.GetProfileForUpdate()
 	if err != nil {
 		return nil, err
@@ -103,6 +103,7 @@ func (c *Controller) NewSyncthing(container string, localSyncDir []string, syncD
 		Type:             sendMode, // sendonly mode
 		Folders:          []*syncthing.Folder{},
 		RescanInterval:   "300",
+		Paused:           svcProfile.SyncPaused,
 	}
 	svcConfig := c.Config()
 	devConfig := svcConfig.GetContainerDevConfigOrDefault(container)
@@ -111,10 +112,23 @@ func (c *Controller) NewSyncthing(container string, localSyncDir []string, syncD
 		// or use the val user specify
 		s.IgnoreDelete = devConfig.Sync.DeleteProtection == nil || *devConfig.Sync.DeleteProtection
 		s.EnableParseFromGitIgnore = devConfig.Sync.Mode == _const.GitIgnoreMode
+		s.TranslateGitIgnore = devConfig.Sync.UseGitIgnore
 		s.SyncedPattern = devConfig.Sync.FilePattern
 		s.IgnoredPattern = devConfig.Sync.IgnoreFilePattern
 	}
 
+	if s.Folders, err = c.syncFolders(container, localSyncDir); err != nil {
+		return nil, err
+	}
+	_ = appProfile.Save()
+	return s, nil
+}
+
+// syncFolders returns folders synced, local sync dirs are followed by folders of sync config
+func (c *Controller) syncFolders(container string, localSyncDir []string) ([]*syncthing.Folder, error) {
+	folders := make([]*syncthing.Folder, 0)
+	remotePath := c.GetWorkDir(container)
+
 	// TODO, warn: multi local sync dir is Deprecated, now it's implement by IgnoreFiles
 	// before creating syncthing sidecar, it need to know how many directories it should sync
 	index := 1
@@ -125,8 +139,8 @@ func (c *Controller) NewSyncthing(container string, localSyncDir []string, syncD
 			return nil, err
 		}
 		if !result {
-			s.Folders = append(
-				s.Folders,
+			folders = append(
+				folders,
 				&syncthing.Folder{
 					Name:       strconv.Itoa(index),
 					LocalPath:  sync,
@@ -136,8 +150,76 @@ func (c *Controller) NewSyncthing(container string, localSyncDir []string, syncD
 			index++
 		}
 	}
-	_ = appProfile.Save()
-	return s, nil
+
+	devConfig := c.Config().GetContainerDevConfigOrDefault(container)
+	if devConfig == nil || devConfig.Sync == nil {
+		return folders, nil
+	}
+	for _, f := range devConfig.Sync.Folders {
+		localPath := f.LocalPath
+		if !filepath.IsAbs(localPath) && len(localSyncDir) > 0 {
+			localPath = filepath.Join(localSyncDir[0], localPath)
+		}
+		if _, err := os.Stat(localPath); err != nil {
+			log.Warnf("Sync folder %s is skipped: %v", localPath, err)
+			continue
+		}
+		folder := &syncthing.Folder{
+			Name:       strconv.Itoa(index),
+			LocalPath:  localPath,
+			RemotePath: f.RemotePath,
+			Ignores:    syncthing.FolderIgnores(f.FilePattern, f.IgnoreFilePattern),
+		}
+		switch f.Type {
+		case _const.DefaultSyncType:
+			folder.Type = _const.DefaultSyncType
+		case _const.SendOnlySyncType, _const.SendOnlySyncTypeAlias:
+			folder.Type = _const.SendOnlySyncType
+		}
+		folders = append(folders, folder)
+		index++
+	}
+	return folders, nil
+}
+
+// SyncFolderIDs returns ids of syncthing folders of the service in DevMode
+func (c *Controller) SyncFolderIDs() []string {
+	svcProfile, err := c.GetProfile()
+	if err != nil {
+		return []string{syncthing.DefaultFolderName}
+	}
+	folders, err := c.syncFolders(svcProfile.OriginDevContainer, svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin)
+	if err != nil || len(folders) == 0 {
+		return []string{syncthing.DefaultFolderName}
+	}
+	ids := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		ids = append(ids, folder.ID())
+	}
+	return ids
+}
+
+// ApplySyncFolderIgnores sets ignores of folders in sync config after local syncthing starts,
+// other folders use .nhignore
+func (c *Controller) ApplySyncFolderIgnores(s *syncthing.Syncthing) error {
+	client := c.NewSyncthingHttpClient(2)
+	for _, folder := range s.Folders {
+		if folder.Ignores == nil {
+			continue
+		}
+		var err error
+		// local syncthing may be not ready
+		for i := 0; i < 10; i++ {
+			if err = client.ForFolder(folder.ID()).SetIgnores(folder.Ignores); err == nil {
+				break
+			}
+			time.Sleep(time.Second)
+		}
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to set ignores of %s", folder.LocalPath))
+		}
+	}
+	return nil
 }
 
 func (c *Controller) NewSyncthingHttpClient(reqTimeoutSecond int) *req.SyncthingHttpClient {
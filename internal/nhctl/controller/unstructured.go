@@ -233,6 +233,13 @@ func (c *Controller) RollbackFromAnnotation(reset bool) error {
 			ans[_const.NocalhostApplicationNamespace] = c.NameSpace
 			um.SetAnnotations(ans)
 		}
+	} else {
+		for _, item := range c.DevModeAction.RollbackPatches {
+			log.Infof("Patching %s(%s)", item.Patch, item.Type)
+			if err = c.Client.Patch(c.Type.String(), c.Name, item.Patch, item.Type); err != nil {
+				return err
+			}
+		}
 	}
 
 	return c.Client.ApplyResourceInfo(originalWorkload[0], nil)
@@ -358,6 +365,9 @@ func RemoveUselessInfo(u *unstructured.Unstructured) {
 		return
 	}
 	delete(a, _const.OriginWorkloadDefinition)
+	delete(a, _const.NocalhostSuspendedHPAAnnotation)
+	delete(a, _const.DevModeTTLAnnotation)
+	delete(a, _const.DevModeHeartbeatAnnotation)
 	delete(a, "kubectl.kubernetes.io/last-applied-configuration")
 	delete(a, OriginSpecJson) // remove deprecated annotation
 	u.SetAnnotations(a)
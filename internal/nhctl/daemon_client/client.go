@@ -308,6 +308,30 @@ func (d *DaemonClient) SendGetApplicationMetaCommand(ns, appName, kubeConfigCont
 	return meta, nil
 }
 
+// SendGetDevSessionCommand returns all the dev sessions of the application if session is empty
+func (d *DaemonClient) SendGetDevSessionCommand(ns, appName, kubeConfigContent, session string) (interface{}, error) {
+	gdsCmd := &command.GetDevSessionCommand{
+		CommandType: command.GetDevSession,
+		ClientStack: string(debug.Stack()),
+
+		NameSpace:         ns,
+		AppName:           appName,
+		KubeConfigContent: kubeConfigContent,
+		Session:           session,
+	}
+
+	bys, err := json.Marshal(gdsCmd)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var result interface{}
+	if err = d.sendAndWaitForResponse(bys, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // the reason why return a interface array is applicationMeta needs to using this client,
 // otherwise it will cause cycle import
 func (d *DaemonClient) SendGetApplicationMetasCommand(ns, kubeConfig string) ([]interface{}, error) {
@@ -355,6 +379,34 @@ func (d *DaemonClient) SendStartPortForwardCommand(
 	return d.sendAndWaitForResponse(bys, nil)
 }
 
+// SendStartServicePortForwardCommand starts port-forward to a ready pod behind target svc/<name>:<port>,
+// remotePort is the number of the service port
+func (d *DaemonClient) SendStartServicePortForwardCommand(
+	nhSvc *model.NocalHostResource, localPort, remotePort int, target, nid string,
+) error {
+
+	startPFCmd := &command.PortForwardCommand{
+		CommandType: command.StartPortForward,
+		ClientStack: string(debug.Stack()),
+
+		NameSpace:   nhSvc.NameSpace,
+		AppName:     nhSvc.Application,
+		Service:     nhSvc.Service,
+		ServiceType: nhSvc.ServiceType,
+		LocalPort:   localPort,
+		RemotePort:  remotePort,
+		Nid:         nid,
+		Target:      target,
+	}
+
+	bys, err := json.Marshal(startPFCmd)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	return d.sendAndWaitForResponse(bys, nil)
+}
+
 // SendStopPortForwardCommand send port forward to daemon
 func (d *DaemonClient) SendStopPortForwardCommand(nhSvc *model.NocalHostResource, localPort, remotePort int) error {
 
@@ -21,6 +21,12 @@ const (
 	SudoDaemonHttpPort = 30126
 )
 
+// the last line of streaming response of vpn operations
+const (
+	EndSignOK     = "EndSignOk"
+	EndSignFailed = "EndSignFailed"
+)
+
 var (
 	Version  = "1.0"
 	CommitId = ""
@@ -0,0 +1,38 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package daemon_handler
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"nocalhost/internal/nhctl/appmeta"
+	"nocalhost/internal/nhctl/appmeta_manager"
+	"nocalhost/internal/nhctl/daemon_server/command"
+)
+
+// HandleGetDevSessionRequest returns the named dev session, or all the dev sessions
+// of the application if session name is not specified
+func HandleGetDevSessionRequest(request *command.GetDevSessionCommand) (interface{}, error) {
+	meta := appmeta_manager.GetApplicationMeta(
+		request.NameSpace, request.AppName, []byte(request.KubeConfigContent),
+	)
+	if meta.ApplicationState == appmeta.UNKNOWN || meta.ApplicationState == appmeta.UNINSTALLED {
+		return nil, errors.New(fmt.Sprintf("Application %s is not found", request.AppName))
+	}
+
+	if request.Session == "" {
+		if meta.DevSessions == nil {
+			return appmeta.ApplicationDevSessions{}, nil
+		}
+		return meta.DevSessions, nil
+	}
+
+	session := meta.GetDevSession(request.Session)
+	if session == nil {
+		return nil, errors.New(fmt.Sprintf("Dev session %s not found", request.Session))
+	}
+	return session, nil
+}
@@ -104,9 +104,9 @@ func HandleVPNOperate(cmd *command.VPNOperateCommand, writer io.WriteCloser) (er
 			_ = updateReverseConfigMap(cmd.KubeConfig, cmd.Namespace, []string{cmd.Resource}, add)
 			if err = connect.DoReverse(logCtx); err != nil {
 				logger.Infof("reverse resource: %s occours error, err: %v", cmd.Resource, err)
-			} else {
-				logger.Infof("reverse resource: %s successfully", cmd.Resource)
+				return
 			}
+			logger.Infof("reverse resource: %s successfully", cmd.Resource)
 		}
 		return
 	case command.Reconnect:
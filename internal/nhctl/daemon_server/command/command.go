@@ -22,6 +22,7 @@ const (
 	GetDaemonServerStatus DaemonCommandType = "GetDaemonServerStatus"
 	GetApplicationMeta    DaemonCommandType = "GetApplicationMeta"
 	GetApplicationMetas   DaemonCommandType = "GetApplicationMetas"
+	GetDevSession         DaemonCommandType = "GetDevSession"
 	GetResourceInfo       DaemonCommandType = "GetResourceInfo"
 	UpdateApplicationMeta DaemonCommandType = "UpdateApplicationMeta"
 	KubeconfigOperation   DaemonCommandType = "KubeconfigOperationCommand"
@@ -69,6 +70,8 @@ type PortForwardCommand struct {
 	OwnerKind       string            `json:"ownerKind"`
 	OwnerApiVersion string            `json:"ownerApiVersion"`
 	OwnerName       string            `json:"ownerName"`
+	// Target svc/<name>:<port> forwarded to, a ready pod behind it is selected
+	Target string `json:"target,omitempty"`
 }
 
 type GetApplicationMetaCommand struct {
@@ -80,6 +83,17 @@ type GetApplicationMetaCommand struct {
 	KubeConfigContent string `json:"kubeConfig"`
 }
 
+type GetDevSessionCommand struct {
+	CommandType DaemonCommandType
+	ClientStack string
+
+	NameSpace         string `json:"nameSpace"`
+	AppName           string `json:"appName"`
+	KubeConfigContent string `json:"kubeConfig"`
+	// return all the sessions of the application if empty
+	Session string `json:"session"`
+}
+
 type CheckClusterStatusCommand struct {
 	CommandType DaemonCommandType
 	ClientStack string
@@ -100,8 +100,9 @@ func StartDaemon(isSudoUser bool, v string, c string) error {
 				}
 
 				// Only replace DevMode's DEV_END event needs to handling
-				// Because duplicate DevMode will not be affected by other user
-				if nhController.IsInDuplicateDevMode() {
+				// Because duplicate, ephemeral and local DevMode will not be affected by other user
+				if nhController.IsInDuplicateDevMode() || nhController.IsInEphemeralDevMode() ||
+					nhController.IsInLocalDevMode() {
 					return nil
 				}
 
@@ -151,6 +152,14 @@ func StartDaemon(isSudoUser bool, v string, c string) error {
 
 		go reconnectSyncthingIfNeededWithPeriod(time.Second * 30)
 
+		go heartbeatDevModeWithPeriod(time.Minute)
+
+		go watchDevConfigWithPeriod(time.Minute)
+
+		go watchGitIgnoreWithPeriod(time.Minute)
+
+		go collectSyncStatsWithPeriod(time.Second * 10)
+
 		go func() {
 			time.Sleep(30 * time.Second)
 			if err := nocalhost_cleanup.CleanUp(false); err != nil {
@@ -393,6 +402,17 @@ func handleCommand(conn net.Conn, bys []byte, cmdType command.DaemonCommandType,
 			},
 		)
 
+	case command.GetDevSession:
+		err = Process(
+			conn, func(conn net.Conn) (interface{}, error) {
+				gdsCmd := &command.GetDevSessionCommand{}
+				if err = json.Unmarshal(bys, gdsCmd); err != nil {
+					return nil, errors.Wrap(err, "")
+				}
+				return daemon_handler.HandleGetDevSessionRequest(gdsCmd)
+			},
+		)
+
 	case command.GetResourceInfo:
 		err = Process(
 			conn, func(conn net.Conn) (interface{}, error) {
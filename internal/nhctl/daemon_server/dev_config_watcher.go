@@ -0,0 +1,149 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package daemon_server
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"nocalhost/internal/nhctl/app"
+	"nocalhost/internal/nhctl/dev_dir"
+	"nocalhost/internal/nhctl/fp"
+	"nocalhost/internal/nhctl/profile"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/internal/nhctl/watcher"
+	k8sutil "nocalhost/pkg/nhctl/k8sutils"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	devConfigLock sync.Mutex
+	// config file -> services associated with the dir of it
+	devConfigPacks = map[string][]*dev_dir.SvcPack{}
+)
+
+// watchDevConfigWithPeriod watches .nocalhost/config.yaml of dirs associated with services,
+// files watched are refreshed from dev_dir mappings with period
+func watchDevConfigWithPeriod(duration time.Duration) {
+	w, err := watcher.NewFileWatcher(time.Second, reloadDevConfig)
+	if err != nil {
+		log.WarnE(err, "Failed to watch config of associated dirs")
+		return
+	}
+	go w.Run(daemonCtx.Done())
+
+	refreshDevConfigWatcher(w)
+	tick := time.NewTicker(duration)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			refreshDevConfigWatcher(w)
+		case <-daemonCtx.Done():
+			return
+		}
+	}
+}
+
+func refreshDevConfigWatcher(w *watcher.FileWatcher) {
+	defer utils.RecoverFromPanic()
+
+	packs := map[string][]*dev_dir.SvcPack{}
+	if err := dev_dir.Get(
+		func(dirMapping *dev_dir.DevDirMapping, pathToPack map[dev_dir.DevPath][]*dev_dir.SvcPack) error {
+			for path, svcPacks := range pathToPack {
+				file := fp.NewFilePath(string(path)).
+					RelOrAbs(app.DefaultGitNocalhostDir).
+					RelOrAbs(app.DefaultConfigNameInGitNocalhostDir).Abs()
+				packs[file] = append(packs[file], svcPacks...)
+			}
+			return nil
+		},
+	); err != nil {
+		log.WarnE(err, "Failed to get associated dirs")
+		return
+	}
+
+	devConfigLock.Lock()
+	devConfigPacks = packs
+	devConfigLock.Unlock()
+
+	for file := range packs {
+		// .nocalhost may be created later, it will be watched in the next period
+		if _, err := os.Stat(filepath.Dir(file)); err != nil {
+			continue
+		}
+		if err := w.Add(file); err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to watch %s", file))
+		}
+	}
+	for _, file := range w.Files() {
+		if _, ok := packs[file]; !ok {
+			w.Remove(file)
+		}
+	}
+}
+
+// reloadDevConfig reloads config of services associated with the dir of file
+func reloadDevConfig(file string) {
+	defer utils.RecoverFromPanic()
+
+	devConfigLock.Lock()
+	packs := devConfigPacks[file]
+	devConfigLock.Unlock()
+
+	reloaded := map[dev_dir.SvcPackKey]bool{}
+	for _, pack := range packs {
+		// a service may be associated with the dir by several containers
+		key := pack.KeyWithoutContainer()
+		if reloaded[key] {
+			continue
+		}
+		reloaded[key] = true
+		if err := reloadDevConfigForSvc(pack); err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to reload %s for %s-%s-%s", file, pack.Ns, pack.App, pack.Svc))
+		}
+	}
+}
+
+// reloadDevConfigForSvc updates config of the service in app meta, port-forwards and sync patterns are
+// applied to the running DevMode, and an event with fields changed is recorded to the workload
+func reloadDevConfigForSvc(pack *dev_dir.SvcPack) error {
+	kubeconfigBytes, _ := pack.GetKubeConfigBytesAndServer()
+	if kubeconfigBytes == "" {
+		return errors.New("Kubeconfig of the associated dir not found")
+	}
+	nhApp, err := app.NewApplication(pack.App, pack.Ns, k8sutil.GetOrGenKubeConfigPath(kubeconfigBytes), true)
+	if err != nil {
+		return err
+	}
+
+	svcType := pack.SvcType.Origin()
+	from := nhApp.GetAppMeta().Config.GetSvcConfigS(pack.Svc, svcType)
+	if !nhApp.ReloadSvcCfgFromLocal(pack.Svc, svcType) {
+		return errors.New("Config is missing or invalid, previous config is kept")
+	}
+
+	c, err := nhApp.Controller(pack.Svc, svcType)
+	if err != nil {
+		return err
+	}
+	changes := profile.DiffSvcConfig(&from, c.Config(), c.LiveConfigContainers()...)
+	if changes.IsEmpty() {
+		return nil
+	}
+	log.Infof("Config of %s-%s-%s reloaded, %s", pack.Ns, pack.App, pack.Svc, changes)
+
+	if len(changes.Live) > 0 {
+		if err = c.ApplyLiveConfigChanges(&from); err != nil {
+			log.WarnE(err, "Failed to apply config changes to DevMode")
+		}
+	}
+	return c.RecordConfigReloadedEvent(changes)
+}
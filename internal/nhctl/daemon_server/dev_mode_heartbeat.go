@@ -0,0 +1,73 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package daemon_server
+
+import (
+	"nocalhost/internal/nhctl/appmeta"
+	"nocalhost/internal/nhctl/appmeta_manager"
+	"nocalhost/internal/nhctl/controller"
+	"nocalhost/internal/nhctl/nocalhost"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/pkg/nhctl/clientgoutils"
+	"nocalhost/pkg/nhctl/log"
+	"time"
+)
+
+// heartbeatDevModeWithPeriod keeps replace DevMode started in this device from being ended by nocalhost-dep
+// while file sync or terminal is active
+func heartbeatDevModeWithPeriod(duration time.Duration) {
+	tick := time.NewTicker(duration)
+	for {
+		select {
+		case <-tick.C:
+			heartbeatDevMode()
+		}
+	}
+}
+
+func heartbeatDevMode() {
+	defer utils.RecoverFromPanic()
+
+	for _, meta := range appmeta_manager.GetAllApplicationMetas() {
+		if meta == nil || meta.DevMeta == nil {
+			continue
+		}
+		appProfile, err := nocalhost.GetProfileV2(meta.Ns, meta.Application, meta.NamespaceId)
+		if err != nil {
+			continue
+		}
+		for _, svcProfile := range appProfile.SvcProfile {
+			if svcProfile == nil || appmeta.HasDevStartingSuffix(svcProfile.Name) {
+				continue
+			}
+			svcType, err := nocalhost.SvcTypeOfMutate(svcProfile.GetType())
+			if err != nil {
+				continue
+			}
+
+			svc, err := controller.NewController(
+				meta.Ns, svcProfile.GetName(), meta.Application, appProfile.Identifier, svcType, nil, meta,
+			)
+			if err != nil {
+				continue
+			}
+			if !svc.IsInReplaceDevMode() || !svc.IsProcessor() {
+				continue
+			}
+			if !svc.IsSyncActive() && !svc.IsTerminalActive() {
+				continue
+			}
+
+			if svc.Client, err = clientgoutils.NewClientGoUtils(appProfile.Kubeconfig, svc.NameSpace); err != nil {
+				log.WarnE(err, "")
+				continue
+			}
+			if err = svc.DevModeHeartbeat(); err != nil {
+				log.WarnE(err, "Failed to send heartbeat of DevMode")
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package daemon_server
+
+import (
+	"fmt"
+	"nocalhost/internal/nhctl/appmeta"
+	"nocalhost/internal/nhctl/appmeta_manager"
+	"nocalhost/internal/nhctl/controller"
+	"nocalhost/internal/nhctl/nocalhost"
+	"nocalhost/internal/nhctl/syncthing"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/internal/nhctl/watcher"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type gitIgnoreSvc struct {
+	controller   *controller.Controller
+	container    string
+	localSyncDir []string
+}
+
+var (
+	gitIgnoreLock sync.Mutex
+	// .gitignore file -> syncing services translating it
+	gitIgnoreSvcs = map[string][]*gitIgnoreSvc{}
+)
+
+// watchGitIgnoreWithPeriod watches .gitignore files of services syncing with sync.useGitIgnore,
+// ignored patterns are regenerated while they are changed, files watched are refreshed with period
+func watchGitIgnoreWithPeriod(duration time.Duration) {
+	w, err := watcher.NewFileWatcher(time.Second, reloadGitIgnore)
+	if err != nil {
+		log.WarnE(err, "Failed to watch .gitignore files")
+		return
+	}
+	go w.Run(daemonCtx.Done())
+
+	refreshGitIgnoreWatcher(w)
+	tick := time.NewTicker(duration)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			refreshGitIgnoreWatcher(w)
+		case <-daemonCtx.Done():
+			return
+		}
+	}
+}
+
+func refreshGitIgnoreWatcher(w *watcher.FileWatcher) {
+	defer utils.RecoverFromPanic()
+
+	svcs := map[string][]*gitIgnoreSvc{}
+	for _, meta := range appmeta_manager.GetAllApplicationMetas() {
+		if meta == nil || meta.DevMeta == nil {
+			continue
+		}
+		appProfile, err := nocalhost.GetProfileV2(meta.Ns, meta.Application, meta.NamespaceId)
+		if err != nil {
+			continue
+		}
+		for _, svcProfile := range appProfile.SvcProfile {
+			if svcProfile == nil || !svcProfile.Syncing || appmeta.HasDevStartingSuffix(svcProfile.Name) ||
+				len(svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin) == 0 {
+				continue
+			}
+			svcType, err := nocalhost.SvcTypeOfMutate(svcProfile.GetType())
+			if err != nil {
+				continue
+			}
+			c, err := controller.NewController(
+				meta.Ns, svcProfile.GetName(), meta.Application, appProfile.Identifier, svcType, nil, meta,
+			)
+			// .gitignore files are not translated by exec sync
+			if err != nil || !c.IsProcessor() || c.IsExecSync(svcProfile.OriginDevContainer) {
+				continue
+			}
+			devConfig := c.Config().GetContainerDevConfigOrDefault(svcProfile.OriginDevContainer)
+			if devConfig == nil || devConfig.Sync == nil || !devConfig.Sync.UseGitIgnore {
+				continue
+			}
+
+			svc := &gitIgnoreSvc{
+				controller:   c,
+				container:    svcProfile.OriginDevContainer,
+				localSyncDir: svcProfile.LocalAbsoluteSyncDirFromDevStartPlugin,
+			}
+			dir := svc.localSyncDir[0]
+			// files of the root may be created later
+			files := []string{filepath.Join(dir, ".gitignore"), filepath.Join(dir, ".git", "info", "exclude")}
+			for _, file := range append(files, syncthing.GitIgnoreFiles(dir)...) {
+				file = filepath.Clean(file)
+				if containsGitIgnoreSvc(svcs[file], svc) {
+					continue
+				}
+				svcs[file] = append(svcs[file], svc)
+			}
+		}
+	}
+
+	gitIgnoreLock.Lock()
+	gitIgnoreSvcs = svcs
+	gitIgnoreLock.Unlock()
+
+	for file := range svcs {
+		if _, err := os.Stat(filepath.Dir(file)); err != nil {
+			continue
+		}
+		if err := w.Add(file); err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to watch %s", file))
+		}
+	}
+	for _, file := range w.Files() {
+		if _, ok := svcs[file]; !ok {
+			w.Remove(file)
+		}
+	}
+}
+
+// reloadGitIgnore regenerates ignored patterns of services translating file
+func reloadGitIgnore(file string) {
+	defer utils.RecoverFromPanic()
+
+	gitIgnoreLock.Lock()
+	svcs := gitIgnoreSvcs[file]
+	gitIgnoreLock.Unlock()
+
+	for _, svc := range svcs {
+		log.Infof("%s changed, reloading ignored patterns of %s", file, svc.controller.Name)
+		if err := svc.controller.ReloadSyncIgnores(svc.container, svc.localSyncDir); err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to reload ignored patterns of %s", svc.controller.Name))
+		}
+	}
+}
+
+func containsGitIgnoreSvc(svcs []*gitIgnoreSvc, svc *gitIgnoreSvc) bool {
+	for _, s := range svcs {
+		if s.controller.NameSpace == svc.controller.NameSpace && s.controller.AppName == svc.controller.AppName &&
+			s.controller.Name == svc.controller.Name && s.controller.Type == svc.controller.Type {
+			return true
+		}
+	}
+	return false
+}
@@ -131,6 +131,7 @@ func (p *PortForwardManager) RecoverPortForwardForApplication(ns, appName, nid s
 						OwnerKind:       pf.OwnerKind,
 						OwnerApiVersion: pf.OwnerApiVersion,
 						Labels:          pf.Labels,
+						Target:          pf.Target,
 					}, false,
 				)
 				if err != nil {
@@ -263,7 +264,18 @@ func (p *PortForwardManager) StartPortForwardGoRoutine(startCmd *command.PortFor
 
 	_ = p.recordPortForward(startCmd.NameSpace, startCmd.Nid, startCmd.AppName, func() bool { return true })
 
+	// port of the pod forwarded to, it is mapped from the service port for service target
+	podPort := remotePort
 	howToGetCurrentPod := func() (*corev1.Pod, error) {
+		if startCmd.Target != "" {
+			pod, port, err := resolveServiceTarget(nhController.Client, startCmd.Target, startCmd.PodName)
+			if err != nil {
+				return nil, err
+			}
+			podPort = port
+			return pod, nil
+		}
+
 		// first find the pod should be port-forward
 		var currentPod *corev1.Pod
 
@@ -314,6 +326,7 @@ func (p *PortForwardManager) StartPortForwardGoRoutine(startCmd *command.PortFor
 			Sudo:            isSudo,
 			DaemonServerPid: os.Getpid(),
 			ServiceType:     startCmd.ServiceType,
+			Target:          startCmd.Target,
 		}
 
 		if currentPod, err = howToGetCurrentPod(); err != nil {
@@ -343,6 +356,11 @@ func (p *PortForwardManager) StartPortForwardGoRoutine(startCmd *command.PortFor
 	}
 
 	startCmd.PodName = currentPod.Name
+	if startCmd.Target != "" {
+		p.lock.Lock()
+		_ = nhController.UpdatePortForwardPod(localPort, remotePort, currentPod.Name)
+		p.lock.Unlock()
+	}
 
 	ctx, cancel := context.WithCancel(context.TODO())
 	p.pfList[key] = &daemon_common.PortForwardProfile{
@@ -419,6 +437,10 @@ func (p *PortForwardManager) StartPortForwardGoRoutine(startCmd *command.PortFor
 					}
 				},
 			)
+			if startCmd.Target != "" {
+				// pod may be not ready before it is deleted, select another one immediately
+				watchServiceTarget(nhController.Client, startCmd.Target, startCmd.PodName, stopCh, errCh)
+			}
 
 			go func() {
 				defer utils.RecoverFromPanic()
@@ -435,7 +457,7 @@ func (p *PortForwardManager) StartPortForwardGoRoutine(startCmd *command.PortFor
 
 			go func() {
 				defer utils.RecoverFromPanic()
-				errCh <- nocalhostApp.PortForward(startCmd.PodName, localPort, remotePort, readyCh, stopCh, stream)
+				errCh <- nocalhostApp.PortForward(startCmd.PodName, localPort, podPort, readyCh, stopCh, stream)
 				log.Logf("Port-forward %d:%d occurs errors", localPort, remotePort)
 			}()
 
@@ -470,6 +492,11 @@ func (p *PortForwardManager) StartPortForwardGoRoutine(startCmd *command.PortFor
 						log.Logf("New pod %s for port-forward found", pod.Name)
 						startCmd.PodName = pod.Name
 						block = false
+						if startCmd.Target != "" {
+							p.lock.Lock()
+							_ = nhController.UpdatePortForwardPod(localPort, remotePort, pod.Name)
+							p.lock.Unlock()
+						}
 					}
 
 				} else if errs != nil && strings.Contains(errs.Error(), "failed to find socat") {
@@ -0,0 +1,87 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package daemon_server
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/internal/nhctl/watcher"
+	"nocalhost/pkg/nhctl/clientgoutils"
+	k8sutil "nocalhost/pkg/nhctl/k8sutils"
+)
+
+// resolveServiceTarget returns a ready pod behind target svc/<name>:<port> and the port of it,
+// current is kept if it is still ready
+func resolveServiceTarget(client *clientgoutils.ClientGoUtils, target, current string) (*corev1.Pod, int, error) {
+	name, port, err := utils.ParseServiceTarget(target)
+	if err != nil {
+		return nil, 0, err
+	}
+	svc, err := client.GetService(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	ep, err := client.GetEndpoints(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	podName, podPort, err := k8sutil.SelectServiceEndpoint(svc, ep, port, current)
+	if err != nil {
+		return nil, 0, err
+	}
+	pod, err := client.GetPod(podName)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pod, podPort, nil
+}
+
+// watchServiceTarget sends a not found error to errCh while podName is not a ready endpoint of target
+// any more, until stopCh is closed. Endpoints sent by the watcher are checked, errors of the api server
+// never stop the port-forward
+func watchServiceTarget(
+	client *clientgoutils.ClientGoUtils, target, podName string, stopCh <-chan struct{}, errCh chan<- error,
+) {
+	name, _, err := utils.ParseServiceTarget(target)
+	if err != nil {
+		return
+	}
+	gone := func() {
+		defer utils.RecoverFromPanic()
+		select {
+		case errCh <- k8serrors.NewNotFound(schema.GroupResource{Resource: "pods"}, podName):
+		default:
+		}
+	}
+	watcher.NewSimpleWatcher(
+		client,
+		"endpoints",
+		v1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String()},
+		stopCh,
+		func(key string, object interface{}, quitChan <-chan struct{}) {
+			us, ok := object.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			var ep corev1.Endpoints
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(us.UnstructuredContent(), &ep); err != nil {
+				return
+			}
+			if !k8sutil.IsReadyEndpoint(&ep, podName) {
+				gone()
+			}
+		},
+		func(key string, quitChan <-chan struct{}) {
+			gone()
+		},
+	)
+}
@@ -0,0 +1,64 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package daemon_server
+
+import (
+	"nocalhost/internal/nhctl/appmeta"
+	"nocalhost/internal/nhctl/appmeta_manager"
+	"nocalhost/internal/nhctl/controller"
+	"nocalhost/internal/nhctl/nocalhost"
+	"nocalhost/internal/nhctl/utils"
+	"nocalhost/pkg/nhctl/log"
+	"time"
+)
+
+// collectSyncStatsWithPeriod records statistics of file sync sessions of this device with period,
+// they are listed by `nhctl sync-status --stats`
+func collectSyncStatsWithPeriod(duration time.Duration) {
+	tick := time.NewTicker(duration)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			collectSyncStats()
+		case <-daemonCtx.Done():
+			return
+		}
+	}
+}
+
+func collectSyncStats() {
+	defer utils.RecoverFromPanic()
+
+	for _, meta := range appmeta_manager.GetAllApplicationMetas() {
+		if meta == nil || meta.DevMeta == nil {
+			continue
+		}
+		appProfile, err := nocalhost.GetProfileV2(meta.Ns, meta.Application, meta.NamespaceId)
+		if err != nil {
+			continue
+		}
+		for _, svcProfile := range appProfile.SvcProfile {
+			if svcProfile == nil || !svcProfile.Syncing || appmeta.HasDevStartingSuffix(svcProfile.Name) {
+				continue
+			}
+			svcType, err := nocalhost.SvcTypeOfMutate(svcProfile.GetType())
+			if err != nil {
+				continue
+			}
+			svc, err := controller.NewController(
+				meta.Ns, svcProfile.GetName(), meta.Application, appProfile.Identifier, svcType, nil, meta,
+			)
+			// there is no syncthing for sync type exec
+			if err != nil || !svc.IsProcessor() || svc.IsExecSync(svcProfile.OriginDevContainer) {
+				continue
+			}
+			if err = svc.CollectSyncStats(); err != nil {
+				log.Logf("Failed to collect sync stats of %s: %v", svc.Name, err)
+			}
+		}
+	}
+}
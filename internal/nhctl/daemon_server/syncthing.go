@@ -150,6 +150,16 @@ func reconnectedSyncthingIfNeeded() {
 			if !svc.IsProcessor() {
 				continue
 			}
+			// there is no syncthing for sync type exec, restart exec sync process if it exits
+			if svc.IsExecSync(svcProfile.OriginDevContainer) {
+				if svcProfile.Syncing && svc.FindOutExecSyncProcess() == 0 {
+					log.Logf("Restarting exec sync process of %s", svc.Name)
+					if err = svc.StartExecSyncProcess(svcProfile.OriginDevContainer, appProfile.Kubeconfig); err != nil {
+						log.LogE(err)
+					}
+				}
+				continue
+			}
 			// reconnect two times:
 			// pre each time, check syncthing connections, if remote device connection is connected, no needs to recover
 			// if remote device connection is not connected, but have this connection, just to do port-forward
@@ -243,6 +253,9 @@ func doReconnectSyncthing(svc *controller.Controller, container string, kubeconf
 	if err = newSyncthing.Run(context.TODO()); err != nil {
 		return err
 	}
+	if err = svc.ApplySyncFolderIgnores(newSyncthing); err != nil {
+		log.LogE(err)
+	}
 	return svc.SetSyncingStatus(true)
 }
 
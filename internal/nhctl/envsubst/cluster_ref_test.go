@@ -0,0 +1,125 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package envsubst
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"nocalhost/internal/nhctl/envsubst/parse"
+	"nocalhost/pkg/nhctl/log"
+	"testing"
+)
+
+// rendered content starts with the location of text, which is empty
+const renderedTextPrefix = parse.AbsSign + "\n"
+
+type fakeClusterRefResolver map[string]string
+
+func (f fakeClusterRefResolver) GetSecretData(namespace, name, key string) (string, error) {
+	return f.get("secret", namespace, name, key)
+}
+
+func (f fakeClusterRefResolver) GetConfigMapData(namespace, name, key string) (string, error) {
+	return f.get("configmap", namespace, name, key)
+}
+
+func (f fakeClusterRefResolver) get(kind, namespace, name, key string) (string, error) {
+	if v, ok := f[fmt.Sprintf("%s:%s/%s#%s", kind, namespace, name, key)]; ok {
+		return v, nil
+	}
+	return "", errors.New("not found")
+}
+
+func TestRenderWithClusterRefs(t *testing.T) {
+	resolver := fakeClusterRefResolver{
+		"secret:db/mysql#password":  "s3cr3t-pass",
+		"configmap:/app-config#url": "http://api:8080",
+	}
+	config := `env:
+  - name: DB_PASSWORD
+    value: ${secret:db/mysql#password}
+  - name: API_URL
+    value: ${configmap:app-config#url}
+  - name: PROFILE
+    value: ${PROFILE:-dev}
+`
+
+	result, err := RenderWithClusterRefs(TextRenderItem(config), nil, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := renderedTextPrefix + `env:
+  - name: DB_PASSWORD
+    value: s3cr3t-pass
+  - name: API_URL
+    value: http://api:8080
+  - name: PROFILE
+    value: dev
+`
+	if result != expected {
+		t.Errorf("got >>>>\n%s\nexpected >>>>\n%s", result, expected)
+	}
+	if masked := log.Mask("password is s3cr3t-pass, url is http://api:8080"); masked !=
+		"password is ******, url is http://api:8080" {
+		t.Errorf("secret is not masked: %s", masked)
+	}
+
+	// references are kept without resolver
+	if result, err = Render(TextRenderItem(config), nil); err != nil {
+		t.Fatal(err)
+	}
+	if result != renderedTextPrefix+`env:
+  - name: DB_PASSWORD
+    value: ${secret:db/mysql#password}
+  - name: API_URL
+    value: ${configmap:app-config#url}
+  - name: PROFILE
+    value: dev
+` {
+		t.Errorf("references should be kept, got %s", result)
+	}
+
+	for _, invalid := range []string{"${secret:db/mysql}", "${secret:db/missing#password}", "${configmap:a/b/c#k}"} {
+		if _, err = RenderWithClusterRefs(TextRenderItem(invalid), nil, resolver); err == nil {
+			t.Errorf("%s should fail to render", invalid)
+		}
+	}
+}
+
+func TestMaskSecretRefs(t *testing.T) {
+	resolver := fakeClusterRefResolver{
+		"secret:db/mysql#root-password": "r00t-n3ver-rendered",
+		"secret:/redis#auth":            "redis-n3ver-rendered",
+	}
+
+	recorder := &SecretRefRecorder{ClusterRefResolver: resolver}
+	if _, err := recorder.GetSecretData("db", "mysql", "root-password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorder.GetSecretData("", "redis", "auth"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorder.GetSecretData("db", "mysql", "root-password"); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Refs) != 2 || recorder.Refs[0] != "db/mysql#root-password" || recorder.Refs[1] != "redis#auth" {
+		t.Fatalf("unexpected refs recorded: %v", recorder.Refs)
+	}
+
+	// config get runs in a process which never renders the config, values are stored in plain text
+	stored := "env:\n  - name: ROOT_PASSWORD\n    value: r00t-n3ver-rendered\n  - name: REDIS_AUTH\n" +
+		"    value: redis-n3ver-rendered\n"
+	if masked := log.Mask(stored); masked != stored {
+		t.Fatalf("values should be unknown before refs are resolved: %s", masked)
+	}
+	if err := MaskSecretRefs(resolver, recorder.Refs); err != nil {
+		t.Fatal(err)
+	}
+	expected := "env:\n  - name: ROOT_PASSWORD\n    value: ******\n  - name: REDIS_AUTH\n    value: ******\n"
+	if masked := log.Mask(stored); masked != expected {
+		t.Errorf("secrets are not masked in config get output: %s", masked)
+	}
+}
@@ -6,9 +6,13 @@
 package envsubst
 
 import (
+	"fmt"
+	"github.com/pkg/errors"
 	"nocalhost/internal/nhctl/envsubst/parse"
 	"nocalhost/internal/nhctl/fp"
+	"nocalhost/pkg/nhctl/log"
 	"os"
+	"strings"
 )
 
 type RenderItem interface {
@@ -40,13 +44,110 @@ func (tri TextRenderItem) GetLocation() string {
 	return ""
 }
 
+// ClusterRefResolver reads data of secrets and configmaps referred by configs,
+// namespace is empty if it is not specified in the reference
+type ClusterRefResolver interface {
+	GetSecretData(namespace, name, key string) (string, error)
+	GetConfigMapData(namespace, name, key string) (string, error)
+}
+
+// Render renders with the process environment and the env file,
+// references to the cluster are kept as they are
 func Render(fp RenderItem, envFile *fp.FilePathEnhance) (string, error) {
+	return RenderWithClusterRefs(fp, envFile, nil)
+}
+
+// RenderWithClusterRefs renders like Render, and resolves ${secret:ns/name#key} and
+// ${configmap:ns/name#key} with resolver, ns is optional. Values of secrets are masked in logs
+func RenderWithClusterRefs(fp RenderItem, envFile *fp.FilePathEnhance, resolver ClusterRefResolver) (
+	string, error,
+) {
 	envs := [][]string{os.Environ()}
 	envs = append(envs, envFile.ReadEnvFile()[:])
 
-	return parse.New(
+	parser := parse.New(
 		"string", envs,
 		&parse.Restrictions{NoUnset: false, NoEmpty: false},
-	).
-		Parse(fp.GetContent(), fp.GetLocation(), []string{})
+	)
+	if resolver != nil {
+		parser.Refs = clusterRefResolveFunc(resolver)
+	}
+	return parser.Parse(fp.GetContent(), fp.GetLocation(), []string{})
+}
+
+func clusterRefResolveFunc(resolver ClusterRefResolver) parse.RefResolver {
+	return func(kind, ref string) (string, error) {
+		namespace, name, key, err := parseClusterRef(ref)
+		if err != nil {
+			return "", err
+		}
+		if kind == parse.RefConfigMap {
+			return resolver.GetConfigMapData(namespace, name, key)
+		}
+		value, err := resolver.GetSecretData(namespace, name, key)
+		if err != nil {
+			return "", err
+		}
+		log.AddSensitive(value)
+		return value, nil
+	}
+}
+
+// SecretRefRecorder records references to secrets resolved by ClusterRefResolver, configs are stored
+// with values of secrets, references are needed to mask them in processes which never render the config
+type SecretRefRecorder struct {
+	ClusterRefResolver
+	Refs []string
+}
+
+func (r *SecretRefRecorder) GetSecretData(namespace, name, key string) (string, error) {
+	value, err := r.ClusterRefResolver.GetSecretData(namespace, name, key)
+	if err != nil {
+		return "", err
+	}
+	ref := name + "#" + key
+	if namespace != "" {
+		ref = namespace + "/" + ref
+	}
+	for _, r := range r.Refs {
+		if r == ref {
+			return value, nil
+		}
+	}
+	r.Refs = append(r.Refs, ref)
+	return value, nil
+}
+
+// MaskSecretRefs resolves references recorded by SecretRefRecorder again, values of the secrets are
+// masked in logs and outputs passed to log.Mask
+func MaskSecretRefs(resolver ClusterRefResolver, refs []string) error {
+	for _, ref := range refs {
+		namespace, name, key, err := parseClusterRef(ref)
+		if err != nil {
+			return err
+		}
+		value, err := resolver.GetSecretData(namespace, name, key)
+		if err != nil {
+			return err
+		}
+		log.AddSensitive(value)
+	}
+	return nil
+}
+
+// parseClusterRef parses reference such as ns/name#key or name#key
+func parseClusterRef(ref string) (string, string, string, error) {
+	i := strings.LastIndex(ref, "#")
+	if i < 0 {
+		return "", "", "", errors.New(fmt.Sprintf("Key is missing in reference %s, such as name#key", ref))
+	}
+	path, key := strings.TrimSpace(ref[:i]), strings.TrimSpace(ref[i+1:])
+	namespace, name := "", path
+	if j := strings.Index(path, "/"); j >= 0 {
+		namespace, name = path[:j], path[j+1:]
+	}
+	if name == "" || key == "" || strings.Contains(name, "/") {
+		return "", "", "", errors.New(fmt.Sprintf("Invalid reference %s, it should be like ns/name#key", ref))
+	}
+	return namespace, name, key, nil
 }
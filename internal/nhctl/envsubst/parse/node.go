@@ -28,6 +28,7 @@ const (
 	NodeText NodeType = iota
 	NodeSubstitution
 	NodeVariable
+	NodeRef
 )
 
 type TextNode struct {
@@ -140,3 +141,29 @@ func (t *SubstitutionNode) StringWithDefault() (k string, v string, err error) {
 	_, v, err = t.Default.String()
 	return
 }
+
+// RefResolver resolves a reference such as ${secret:ns/name#key} to data in the cluster
+type RefResolver func(kind, ref string) (string, error)
+
+// RefNode refers to data in the cluster, it is kept as is if no resolver provided
+type RefNode struct {
+	NodeType
+	Kind    string
+	Ref     string
+	Resolve RefResolver
+}
+
+func NewRef(kind, ref string, resolve RefResolver) *RefNode {
+	return &RefNode{NodeRef, kind, ref, resolve}
+}
+
+func (t *RefNode) String() (string, string, error) {
+	if t.Resolve == nil {
+		return t.Kind, fmt.Sprintf("${%s:%s}", t.Kind, t.Ref), nil
+	}
+	value, err := t.Resolve(t.Kind, t.Ref)
+	if err != nil {
+		return t.Kind, "", errors.Wrap(err, fmt.Sprintf("Failed to resolve ${%s:%s}", t.Kind, t.Ref))
+	}
+	return t.Kind, value, nil
+}
@@ -26,6 +26,8 @@ const (
 	Include          = "_INCLUDE_"
 	Indent           = "nindent"
 	AbsSign          = "#/---//---//Nocalhost//---//---/"
+	RefSecret        = "secret"
+	RefConfigMap     = "configmap"
 
 	ErrTmpl = `
 #  ======    WARN    ======
@@ -58,6 +60,8 @@ type Parser struct {
 	Env      []Env
 	Restrict *Restrictions
 	Mode     Mode
+	// Refs resolves references to the cluster, such as ${secret:ns/name#key}
+	Refs RefResolver
 	// parsing state;
 	lex       *lexer
 	token     [3]item // three-token lookahead
@@ -393,6 +397,11 @@ Loop:
 			expType = t.typ
 		}
 	}
+	// ${secret:ns/name#key} is lexed as variable `secret` followed by text `:ns/name#key`
+	if text, ok := defaultNode.(*TextNode); ok && expType == 0 && strings.HasPrefix(text.Text, ":") &&
+		(varNode.Ident == RefSecret || varNode.Ident == RefConfigMap) {
+		return NewRef(varNode.Ident, strings.TrimPrefix(text.Text, ":"), p.Refs), nil
+	}
 	return &SubstitutionNode{NodeSubstitution, expType, varNode, defaultNode}, nil
 }
 
@@ -0,0 +1,95 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package execsync
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matcher decides which files are synced by FilePattern and IgnoreFilePattern,
+// patterns are resolved the same as .nhignore of syncthing:
+// ignored patterns win, a file is synced if it matches one of synced patterns,
+// or synced patterns are empty
+type Matcher struct {
+	synced  []*regexp.Regexp
+	ignored []*regexp.Regexp
+}
+
+func NewMatcher(syncedPattern, ignoredPattern []string) *Matcher {
+	m := &Matcher{}
+	for _, p := range syncedPattern {
+		m.synced = append(m.synced, compilePattern(p))
+	}
+	for _, p := range ignoredPattern {
+		m.ignored = append(m.ignored, compilePattern(p))
+	}
+	return m
+}
+
+// Match returns if rel, a path relative to the sync dir, should be synced
+func (m *Matcher) Match(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if m.Ignored(rel) {
+		return false
+	}
+	if len(m.synced) == 0 {
+		return true
+	}
+	for _, re := range m.synced {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ignored returns if rel is ignored, dirs ignored need not to be walked
+func (m *Matcher) Ignored(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, re := range m.ignored {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePattern translates a pattern to regexp, a pattern starts with / or ./ matches from
+// the sync dir only, otherwise it matches in any dir, contents of dirs matched are matched too
+func compilePattern(pattern string) *regexp.Regexp {
+	// previews version support such this syntax
+	if pattern == "." {
+		pattern = "**"
+	}
+	if strings.HasPrefix(pattern, "./") {
+		pattern = pattern[1:]
+	}
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "/"), "/")
+
+	var sb strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			sb.WriteString("[^/]*")
+		case pattern[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	prefix := "^(.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+	return regexp.MustCompile(prefix + sb.String() + "(/.*)?$")
+}
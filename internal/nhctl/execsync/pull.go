@@ -0,0 +1,155 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package execsync
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Pull copies remotePath of DevContainer to localPath by tar, rel is the path of remotePath relative
+// to the remote sync dir, files ignored by m are skipped. Files unchanged are not written and files
+// changed are replaced with the remote mtime, so that they are not synced back as local changes.
+// It returns the number of files written
+func Pull(exec Exec, remotePath, localPath, rel string, m *Matcher) (int, error) {
+	remotePath = path.Clean(remotePath)
+	base := path.Base(remotePath)
+	reader, writer := io.Pipe()
+	stderr := &bytes.Buffer{}
+	execErr := make(chan error, 1)
+	go func() {
+		err := exec([]string{"tar", "-cf", "-", "-C", path.Dir(remotePath), base}, nil, writer, stderr)
+		_ = writer.CloseWithError(err)
+		execErr <- err
+	}()
+
+	pulled, err := extract(reader, base, localPath, rel, m)
+	_ = reader.CloseWithError(err)
+	if e := <-execErr; e != nil && err == nil {
+		err = errors.Wrap(e, strings.TrimSpace(stderr.String()))
+	}
+	return pulled, err
+}
+
+// extract writes entries of tar under base to localPath
+func extract(r io.Reader, base, localPath, rel string, m *Matcher) (int, error) {
+	pulled := 0
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return pulled, nil
+		}
+		if err != nil {
+			return pulled, errors.Wrap(err, "")
+		}
+
+		name := path.Clean(header.Name)
+		if name != base && !strings.HasPrefix(name, base+"/") {
+			continue
+		}
+		sub := strings.TrimPrefix(strings.TrimPrefix(name, base), "/")
+		fileRel := path.Join(rel, sub)
+		dst := filepath.Join(localPath, filepath.FromSlash(sub))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if fileRel != "." && fileRel != "" && m.Ignored(fileRel) {
+				continue
+			}
+			if err = os.MkdirAll(dst, 0755); err != nil {
+				return pulled, errors.Wrap(err, "")
+			}
+		case tar.TypeReg:
+			if !m.Match(fileRel) {
+				continue
+			}
+			changed, err := writeFile(dst, tr, header)
+			if err != nil {
+				return pulled, err
+			}
+			if changed {
+				pulled++
+			}
+		case tar.TypeSymlink:
+			if !m.Match(fileRel) {
+				continue
+			}
+			if link, err := os.Readlink(dst); err == nil && link == header.Linkname {
+				continue
+			}
+			_ = os.Remove(dst)
+			if err = os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return pulled, errors.Wrap(err, "")
+			}
+			if err = os.Symlink(header.Linkname, dst); err != nil {
+				return pulled, errors.Wrap(err, "")
+			}
+			pulled++
+		}
+	}
+}
+
+// writeFile writes content of header to dst if it is changed. The content is written to a temp file
+// named as syncthing's, which is ignored by syncthing, and renamed to dst after it is completed
+func writeFile(dst string, r io.Reader, header *tar.Header) (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return false, errors.Wrap(err, "")
+	}
+	tmp := filepath.Join(filepath.Dir(dst), fmt.Sprintf(".syncthing.%s.tmp", filepath.Base(dst)))
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+	if err != nil {
+		return false, errors.Wrap(err, "")
+	}
+	_, err = io.Copy(f, r)
+	_ = f.Close()
+	if err != nil {
+		_ = os.Remove(tmp)
+		return false, errors.Wrap(err, "")
+	}
+
+	if equal, _ := sameContent(tmp, dst); equal {
+		_ = os.Remove(tmp)
+		return false, nil
+	}
+	_ = os.Chtimes(tmp, header.ModTime, header.ModTime)
+	if err = os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return false, errors.Wrap(err, "")
+	}
+	return true, nil
+}
+
+func sameContent(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+	bysA, err := ioutil.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	bysB, err := ioutil.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(bysA, bysB), nil
+}
@@ -0,0 +1,82 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package execsync
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "execsync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = os.MkdirAll(filepath.Join(dir, "gen"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "gen", "same.go"), []byte("same"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var command []string
+	exec := func(cmd []string, stdin io.Reader, stdout, stderr io.Writer) error {
+		command = cmd
+		tw := tar.NewWriter(stdout)
+		_ = tw.WriteHeader(&tar.Header{Name: "gen/", Typeflag: tar.TypeDir, Mode: 0755})
+		for name, content := range map[string]string{
+			"gen/a.go":       "a",
+			"gen/same.go":    "same",
+			"gen/debug.log":  "log",
+			"gen/tmp/b.go":   "b",
+			"other/c.go":     "c",
+			"gen/sub/d.go":   "d",
+			"gen/sub/e.json": "e",
+		} {
+			_ = tw.WriteHeader(
+				&tar.Header{
+					Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content)), ModTime: modTime,
+				},
+			)
+			_, _ = tw.Write([]byte(content))
+		}
+		return tw.Close()
+	}
+
+	pulled, err := Pull(
+		exec, "/home/nocalhost-dev/gen/", filepath.Join(dir, "gen"), "gen",
+		NewMatcher(nil, []string{"*.log", "./gen/tmp"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(command, []string{"tar", "-cf", "-", "-C", "/home/nocalhost-dev", "gen"}) {
+		t.Errorf("unexpected command %v", command)
+	}
+	if pulled != 3 {
+		t.Errorf("3 files should be pulled, but got %d", pulled)
+	}
+	for _, f := range []string{"gen/debug.log", "gen/tmp/b.go", "other/c.go", "gen/.syncthing.a.go.tmp"} {
+		if _, err = os.Stat(filepath.Join(dir, f)); err == nil {
+			t.Errorf("%s should not be pulled", f)
+		}
+	}
+	info, err := os.Stat(filepath.Join(dir, "gen", "sub", "d.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("mtime of pulled files should be the remote one")
+	}
+}
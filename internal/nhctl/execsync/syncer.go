@@ -0,0 +1,297 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package execsync
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"nocalhost/internal/nhctl/syncthing/network/req"
+	"nocalhost/pkg/nhctl/log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// syncRetryInterval how much to wait before syncing files failed to sync again
+const syncRetryInterval = 3 * time.Second
+
+// Exec runs command in DevContainer, such as k8s exec api
+type Exec func(command []string, stdin io.Reader, stdout, stderr io.Writer) error
+
+// Syncer sends files changed in LocalDir to RemoteDir of DevContainer by tar,
+// it is an alternative of syncthing while syncthing can not run
+type Syncer struct {
+	Exec      Exec
+	LocalDir  string
+	RemoteDir string
+	Matcher   *Matcher
+	// IgnoreDelete files deleted locally are kept in the remote
+	IgnoreDelete bool
+	// StatusFile status is written to, in the format of sync-status
+	StatusFile string
+	// Delay how much to wait before syncing after a file change
+	Delay time.Duration
+}
+
+// Run sends all files to the remote, then sends files changed until stopCh is closed
+func (s *Syncer) Run(stopCh <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	defer watcher.Close()
+
+	// watch dirs before the first sync, so that changes during it are not missed
+	files, err := s.walk(s.LocalDir, watcher)
+	if err != nil {
+		return err
+	}
+
+	pending := map[string]bool{}
+	timer := time.NewTimer(s.Delay)
+	timer.Stop()
+	defer timer.Stop()
+	// files failed to sync are kept pending and retried later
+	retry := func(files []string) {
+		for _, f := range files {
+			pending[f] = true
+		}
+		timer.Reset(syncRetryInterval)
+	}
+	if err = s.sync(files); err != nil {
+		retry(files)
+	}
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			rel, err := filepath.Rel(s.LocalDir, event.Name)
+			if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					created, _ := s.walk(event.Name, watcher)
+					for _, f := range created {
+						pending[f] = true
+					}
+				}
+			}
+			pending[filepath.ToSlash(rel)] = true
+			timer.Reset(s.Delay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.WarnE(errors.Wrap(err, ""), "Error occurs while watching files")
+		case <-timer.C:
+			changed := make([]string, 0, len(pending))
+			for f := range pending {
+				changed = append(changed, f)
+			}
+			pending = map[string]bool{}
+			if err = s.sync(changed); err != nil {
+				retry(changed)
+			}
+		}
+	}
+}
+
+// walk watches dirs under dir and returns files to sync in it
+func (s *Syncer) walk(dir string, watcher *fsnotify.Watcher) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.Walk(
+		dir, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				// file may be removed while walking
+				return nil
+			}
+			rel, err := filepath.Rel(s.LocalDir, file)
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				if rel != "." && s.Matcher.Ignored(rel) {
+					return filepath.SkipDir
+				}
+				if err = watcher.Add(file); err != nil {
+					log.WarnE(errors.Wrap(err, ""), fmt.Sprintf("Failed to watch %s", file))
+				}
+				return nil
+			}
+			if s.Matcher.Match(rel) {
+				files = append(files, filepath.ToSlash(rel))
+			}
+			return nil
+		},
+	)
+	return files, errors.Wrap(err, "")
+}
+
+// sync sends changed files and removes files deleted, status is reported before and after it
+func (s *Syncer) sync(changed []string) error {
+	sort.Strings(changed)
+	sent := make([]string, 0)
+	removed := make([]string, 0)
+	for _, rel := range changed {
+		if !s.Matcher.Match(rel) {
+			continue
+		}
+		info, err := os.Lstat(filepath.Join(s.LocalDir, filepath.FromSlash(rel)))
+		switch {
+		case os.IsNotExist(err):
+			removed = append(removed, rel)
+		case err == nil && !info.IsDir():
+			sent = append(sent, rel)
+		}
+	}
+	if len(sent) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	s.writeStatus(
+		&req.SyncthingStatus{
+			Status: req.Syncing,
+			Msg:    fmt.Sprintf("Syncing %d files...", len(sent)+len(removed)),
+		},
+	)
+	if err := s.send(sent); err != nil {
+		log.WarnE(err, "Failed to send files")
+		s.writeStatus(&req.SyncthingStatus{Status: req.Error, Msg: "Error", Tips: err.Error()})
+		return err
+	}
+	if err := s.remove(removed); err != nil {
+		log.WarnE(err, "Failed to remove files")
+		s.writeStatus(&req.SyncthingStatus{Status: req.Error, Msg: "Error", Tips: err.Error()})
+		return err
+	}
+	log.Infof("%d files sent, %d files removed", len(sent), len(removed))
+	s.writeStatus(
+		&req.SyncthingStatus{
+			Status: req.Idle,
+			Msg:    fmt.Sprintf("Last synced at %s", time.Now().Format("2006-01-02 15:04:05")),
+			Tips:   fmt.Sprintf("%d files sent, %d files removed", len(sent), len(removed)),
+		},
+	)
+	return nil
+}
+
+// send streams files in a tar to the remote, which is extracted by tar of DevContainer
+func (s *Syncer) send(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(s.writeTar(writer, files))
+	}()
+
+	stderr := &bytes.Buffer{}
+	if err := s.Exec([]string{"tar", "-xmf", "-", "-C", s.RemoteDir}, reader, nil, stderr); err != nil {
+		_ = reader.CloseWithError(err)
+		return errors.Wrap(err, stderr.String())
+	}
+	return nil
+}
+
+func (s *Syncer) writeTar(w io.Writer, files []string) error {
+	tw := tar.NewWriter(w)
+	for _, rel := range files {
+		file := filepath.Join(s.LocalDir, filepath.FromSlash(rel))
+		info, err := os.Lstat(file)
+		if err != nil {
+			// removed after changed, it will be removed by the next sync
+			continue
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				continue
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return errors.Wrap(err, "")
+		}
+		header.Name = rel
+		if err = tw.WriteHeader(header); err != nil {
+			return errors.Wrap(err, "")
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.Wrap(err, "")
+		}
+		_, err = io.CopyN(tw, f, header.Size)
+		_ = f.Close()
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("%s is changed while sending", rel))
+		}
+	}
+	return errors.Wrap(tw.Close(), "")
+}
+
+func (s *Syncer) remove(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	if s.IgnoreDelete {
+		log.Infof("Delete protection is enabled, %d files removed locally are kept in the remote", len(files))
+		return nil
+	}
+	command := []string{"rm", "-rf", "--"}
+	for _, rel := range files {
+		command = append(command, path.Join(s.RemoteDir, rel))
+	}
+	stderr := &bytes.Buffer{}
+	if err := s.Exec(command, nil, nil, stderr); err != nil {
+		return errors.Wrap(err, stderr.String())
+	}
+	return nil
+}
+
+func (s *Syncer) writeStatus(status *req.SyncthingStatus) {
+	if s.StatusFile == "" {
+		return
+	}
+	bys, _ := json.Marshal(status)
+	tmp := s.StatusFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, bys, 0644); err != nil {
+		log.WarnE(errors.Wrap(err, ""), "Failed to write sync status")
+		return
+	}
+	if err := os.Rename(tmp, s.StatusFile); err != nil {
+		log.WarnE(errors.Wrap(err, ""), "Failed to write sync status")
+	}
+}
+
+// ReadStatus reads the status reported by Syncer
+func ReadStatus(statusFile string) (*req.SyncthingStatus, error) {
+	bys, err := ioutil.ReadFile(statusFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	status := &req.SyncthingStatus{}
+	if err = json.Unmarshal(bys, status); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	return status, nil
+}
@@ -0,0 +1,140 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package execsync
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"io/ioutil"
+	"nocalhost/internal/nhctl/syncthing/network/req"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher([]string{"./src", "*.go"}, []string{"vendor", "./src/tmp", "*.log"})
+	for rel, expected := range map[string]bool{
+		"src/main.py":        true,
+		"src/a/b.py":         true,
+		"main.go":            true,
+		"pkg/util/util.go":   true,
+		"README.md":          false,
+		"vendor/a/a.go":      false,
+		"pkg/vendor/a.go":    false,
+		"src/tmp/a.py":       false,
+		"pkg/src/tmp/a.go":   true,
+		"src/debug.log":      false,
+		"src/debug.log.back": true,
+	} {
+		if m.Match(rel) != expected {
+			t.Errorf("match %s should be %v", rel, expected)
+		}
+	}
+
+	if !NewMatcher([]string{"."}, nil).Match("a/b/c") {
+		t.Error(". should match all files")
+	}
+	if !NewMatcher(nil, nil).Match("a/b/c") {
+		t.Error("empty synced pattern should match all files")
+	}
+}
+
+func TestSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "execsync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, f := range []string{"src/main.go", "src/debug.log"} {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755)
+		if err = ioutil.WriteFile(filepath.Join(dir, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	received := map[string]string{}
+	var removed []string
+	s := &Syncer{
+		Exec: func(command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+			if command[0] == "rm" {
+				removed = command[3:]
+				return nil
+			}
+			tr := tar.NewReader(stdin)
+			for {
+				header, err := tr.Next()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				bys, _ := ioutil.ReadAll(tr)
+				received[header.Name] = string(bys)
+			}
+		},
+		LocalDir:   dir,
+		RemoteDir:  "/home/nocalhost-dev",
+		Matcher:    NewMatcher(nil, []string{"*.log"}),
+		StatusFile: filepath.Join(dir, "status.json"),
+	}
+	s.sync([]string{"src/main.go", "src/debug.log", "src/deleted.go"})
+
+	if !reflect.DeepEqual(received, map[string]string{"src/main.go": "src/main.go"}) {
+		t.Errorf("unexpected files received %v", received)
+	}
+	if !reflect.DeepEqual(removed, []string{"/home/nocalhost-dev/src/deleted.go"}) {
+		t.Errorf("unexpected files removed %v", removed)
+	}
+	status, err := ReadStatus(s.StatusFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != req.Idle || status.Tips != "1 files sent, 1 files removed" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	removed = nil
+	s.IgnoreDelete = true
+	s.sync([]string{"src/deleted.go"})
+	if removed != nil {
+		t.Errorf("files should not be removed while delete protection is enabled")
+	}
+}
+
+func TestSyncFailed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "execsync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Syncer{
+		Exec: func(command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+			return errors.New("connection refused")
+		},
+		LocalDir:   dir,
+		RemoteDir:  "/home/nocalhost-dev",
+		Matcher:    NewMatcher(nil, nil),
+		StatusFile: filepath.Join(dir, "status.json"),
+	}
+	if err = s.sync([]string{"main.go"}); err == nil {
+		t.Fatal("files failed to send should be reported to be retried")
+	}
+	status, err := ReadStatus(s.StatusFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != req.Error {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
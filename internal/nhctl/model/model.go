@@ -5,6 +5,8 @@
 
 package model
 
+import "time"
+
 type NocalHostResource struct {
 	NameSpace   string
 	Nid         string
@@ -32,4 +34,10 @@ type DevStartOptions struct {
 
 	DevModeType string
 	MeshHeader  map[string]string
+
+	// Show what DevMode would apply to the cluster without changing it
+	DryRun bool
+
+	// DevMode is ended in cluster if neither sync nor terminal is active for TTL
+	TTL time.Duration
 }
@@ -5,7 +5,26 @@
 
 package nocalhost
 
-import nocalhostdb "nocalhost/internal/nhctl/nocalhost/db"
+import (
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	nocalhostdb "nocalhost/internal/nhctl/nocalhost/db"
+)
+
+// GetKey returns value of key in the application db, it is empty if key does not exist
+func GetKey(ns, app, nid string, key string) (string, error) {
+	db, err := nocalhostdb.OpenApplicationLevelDB(ns, app, nid, true)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	bys, err := db.Get([]byte(key))
+	if err == leveldb.ErrNotFound {
+		return "", nil
+	}
+	return string(bys), errors.Wrap(err, "")
+}
 
 func UpdateKey(ns, app, nid string, key string, value string) error {
 	db, err := nocalhostdb.OpenApplicationLevelDB(ns, app, nid, false)
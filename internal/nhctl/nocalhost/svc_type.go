@@ -12,6 +12,7 @@ import (
 	"nocalhost/internal/nhctl/nocalhost_path"
 	"nocalhost/pkg/nhctl/log"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
@@ -56,6 +57,12 @@ func init() {
 	supportedSvcType["advancedcronjobs.v1alpha1.apps.kruise.io"] = KruiseCronJobDevModeAction
 	supportedSvcType["broadcastjobs.v1alpha1.apps.kruise.io"] = JobDevModeAction
 
+	// Argo Rollouts
+	supportedSvcType["rollouts.v1alpha1.argoproj.io"] = ArgoRolloutDevModeAction
+
+	// Knative
+	supportedSvcType["services.v1.serving.knative.dev"] = KnativeServiceDevModeAction
+
 	buildInGvkList = []*schema.GroupVersionKind{
 		{Group: "apps", Version: "v1", Kind: "Deployment"},
 		{Group: "apps", Version: "v1", Kind: "StatefulSet"},
@@ -88,6 +95,21 @@ func GetDevModeActionBySvcType(svcType base.SvcType) (*base.DevModeAction, error
 	return nil, errors.New(fmt.Sprintf("Workload Type %s is unsupported", svcType))
 }
 
+// CrdSvcTypes returns custom resources which can enter DevMode, svc types of them are
+// resource.version.group, such as rollouts.v1alpha1.argoproj.io
+func CrdSvcTypes() map[base.SvcType]schema.GroupVersionResource {
+	crds := make(map[base.SvcType]schema.GroupVersionResource, 0)
+	for svcType := range supportedSvcType {
+		parts := strings.SplitN(string(svcType), ".", 3)
+		// groups of custom resources are domain names, while groups of built-in ones are not
+		if len(parts) != 3 || !strings.Contains(parts[2], ".") {
+			continue
+		}
+		crds[svcType] = schema.GroupVersionResource{Group: parts[2], Version: parts[1], Resource: parts[0]}
+	}
+	return crds
+}
+
 func CheckIfResourceTypeIsSupported(svcType base.SvcType) bool {
 	if _, ok := supportedSvcType[svcType]; ok {
 		return true
@@ -142,4 +164,36 @@ var (
 		PodTemplatePath: "/spec/template/broadcastJobTemplate/spec/template",
 		Create:          true,
 	}
+
+	// ArgoRolloutDevModeAction DevContainer runs in a generated deployment, so it won't go through
+	// canary steps and analysis. The rollout is paused and scaled down until DevMode ends,
+	// and resumed before the original manifest is applied
+	ArgoRolloutDevModeAction = base.DevModeAction{
+		ScalePatches: []base.PatchItem{{
+			Patch: `{"spec":{"paused":true,"replicas":0}}`,
+			Type:  "merge",
+		}},
+		PodTemplatePath: "/spec/template",
+		Create:          true,
+		RollbackPatches: []base.PatchItem{{
+			Patch: `{"spec":{"paused":false}}`,
+			Type:  "merge",
+		}},
+	}
+
+	// KnativeServiceDevModeAction DevContainer runs in a generated deployment. Traffic is pinned
+	// to the latest ready revision until DevMode ends, so revisions created meanwhile get none
+	KnativeServiceDevModeAction = base.DevModeAction{
+		ScalePatches: []base.PatchItem{{
+			Patch: `{"spec":{"traffic":[{"revisionName":"{{ .status.latestReadyRevisionName }}",` +
+				`"latestRevision":false,"percent":100}]}}`,
+			Type: "merge",
+		}},
+		PodTemplatePath: "/spec/template",
+		Create:          true,
+		RollbackPatches: []base.PatchItem{{
+			Patch: `{"spec":{"traffic":null}}`,
+			Type:  "merge",
+		}},
+	}
 )
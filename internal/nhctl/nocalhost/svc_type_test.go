@@ -0,0 +1,28 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package nocalhost
+
+import (
+	"nocalhost/internal/nhctl/common/base"
+	"testing"
+)
+
+func TestCrdSvcTypes(t *testing.T) {
+	crds := CrdSvcTypes()
+
+	rollout, ok := crds["rollouts.v1alpha1.argoproj.io"]
+	if !ok || rollout.Group != "argoproj.io" || rollout.Version != "v1alpha1" || rollout.Resource != "rollouts" {
+		t.Fatalf("unexpected gvr of argo rollout: %v", rollout)
+	}
+	if _, ok = crds["clonesets.v1alpha1.apps.kruise.io"]; !ok {
+		t.Fatal("kruise cloneset should be listed")
+	}
+	for _, builtIn := range []base.SvcType{base.Deployment, "deployments.v1.apps", "deployments.v1beta1.extensions", "pods.v1."} {
+		if _, ok = crds[builtIn]; ok {
+			t.Fatalf("%s is not a custom resource", builtIn)
+		}
+	}
+}
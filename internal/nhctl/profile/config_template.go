@@ -0,0 +1,261 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"nocalhost/pkg/nhctl/log"
+	"reflect"
+)
+
+// Resolved returns a copy of the config whose containers are merged with templates they extend
+func (s *ServiceConfigV2) Resolved() *ServiceConfigV2 {
+	result := *s
+	result.ContainerConfigs = make([]*ContainerConfig, 0, len(s.ContainerConfigs))
+	for _, c := range s.ContainerConfigs {
+		result.ContainerConfigs = append(result.ContainerConfigs, s.resolveContainerConfig(c))
+	}
+	return &result
+}
+
+// resolveContainerConfig deep merges c over the template it extends, fields written in c
+// have a higher priority even if they are zero. c is returned as it is if it extends nothing
+func (s *ServiceConfigV2) resolveContainerConfig(c *ContainerConfig) *ContainerConfig {
+	if c == nil || c.Extends == "" {
+		return c
+	}
+
+	result := copyContainerConfig(c)
+	visited := map[string]bool{}
+	for name := c.Extends; name != ""; {
+		if visited[name] {
+			log.Warnf("Templates extended by container %s are circular: %s", c.Name, name)
+			break
+		}
+		visited[name] = true
+
+		template, ok := s.templates[name]
+		if !ok || template == nil {
+			log.Warnf("Template %s extended by container %s not found", name, c.Name)
+			break
+		}
+		merged, err := mergeContainerConfig(result, template)
+		if err != nil {
+			log.WarnE(err, fmt.Sprintf("Failed to merge container %s with template %s", c.Name, name))
+			break
+		}
+		result = merged
+		name = template.Extends
+	}
+	result.Extends = ""
+	result.written = nil
+	return result
+}
+
+// mergeContainerConfig merges dst over the template it extends by fields written in them,
+// mappings are merged recursively, env is merged by name and other lists are replaced
+func mergeContainerConfig(dst, template *ContainerConfig) (*ContainerConfig, error) {
+	fields, err := dst.writtenFields()
+	if err != nil {
+		return nil, err
+	}
+	templateFields, err := template.writtenFields()
+	if err != nil {
+		return nil, err
+	}
+	bys, err := json.Marshal(mergeFields(templateFields, fields))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	result := &ContainerConfig{}
+	if err = json.Unmarshal(bys, result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if dst.Dev != nil && template.Dev != nil && len(dst.Dev.Env) > 0 {
+		result.Dev.Env = mergeEnv(template.Dev.Env, dst.Dev.Env)
+	}
+	return result, nil
+}
+
+// mergeFields merges fields over fields of template, fields whose values are null are not set
+func mergeFields(template, fields map[string]interface{}) map[string]interface{} {
+	for key, value := range fields {
+		if value == nil {
+			continue
+		}
+		m, ok := value.(map[string]interface{})
+		tm, tok := template[key].(map[string]interface{})
+		if ok && tok {
+			template[key] = mergeFields(tm, m)
+			continue
+		}
+		template[key] = value
+	}
+	return template
+}
+
+// containerConfigFields has the fields of ContainerConfig without its methods
+type containerConfigFields ContainerConfig
+
+// UnmarshalJSON records fields written in a container extending a template, which override
+// the template even if they are zero, such as `hotReload: false`
+func (c *ContainerConfig) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*containerConfigFields)(c)); err != nil {
+		return err
+	}
+	c.written = nil
+	if c.Extends != "" {
+		written := map[string]interface{}{}
+		if err := json.Unmarshal(data, &written); err == nil {
+			c.written = written
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML is the same as UnmarshalJSON, it works for both yaml.v3 and custom_yaml_v3
+func (c *ContainerConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal((*containerConfigFields)(c)); err != nil {
+		return err
+	}
+	c.written = nil
+	if c.Extends != "" {
+		written := map[string]interface{}{}
+		if err := unmarshal(&written); err == nil {
+			c.written = written
+		}
+	}
+	return nil
+}
+
+// MarshalJSON omits zero fields which are not written of a container extending a template,
+// so that they still don't override the template after the config is saved and loaded
+func (c ContainerConfig) MarshalJSON() ([]byte, error) {
+	if c.Extends == "" {
+		return json.Marshal(containerConfigFields(c))
+	}
+	fields, err := c.writtenFields()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// MarshalYAML is the same as MarshalJSON, it works for both yaml.v3 and custom_yaml_v3
+func (c ContainerConfig) MarshalYAML() (interface{}, error) {
+	if c.Extends == "" {
+		return containerConfigFields(c), nil
+	}
+	return c.writtenFields()
+}
+
+// writtenFields returns fields of c by json names, zero fields are omitted unless they are written
+func (c *ContainerConfig) writtenFields() (map[string]interface{}, error) {
+	bys, err := json.Marshal(containerConfigFields(*c))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	fields := map[string]interface{}{}
+	if err = json.Unmarshal(bys, &fields); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return omitZeroFields(fields, c.written), nil
+}
+
+func omitZeroFields(fields, written map[string]interface{}) map[string]interface{} {
+	for key, value := range fields {
+		w, isWritten := written[key]
+		if m, ok := value.(map[string]interface{}); ok {
+			wm, _ := w.(map[string]interface{})
+			value = omitZeroFields(m, wm)
+			fields[key] = value
+		}
+		if !isWritten && isZeroField(value) {
+			delete(fields, key)
+		}
+	}
+	// fields with omitempty are omitted by json if they are zero, even if they are written
+	for key, w := range written {
+		if _, ok := fields[key]; !ok {
+			if zero, ok := zeroFieldOf(w); ok {
+				fields[key] = zero
+			}
+		}
+	}
+	return fields
+}
+
+// isZeroField lists set, even empty ones, are not zero
+func isZeroField(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return false
+	default:
+		return reflect.ValueOf(v).IsZero()
+	}
+}
+
+func zeroFieldOf(value interface{}) (interface{}, bool) {
+	switch value.(type) {
+	case nil, map[string]interface{}:
+		return nil, false
+	case []interface{}:
+		return []interface{}{}, true
+	default:
+		return reflect.Zero(reflect.TypeOf(value)).Interface(), true
+	}
+}
+
+// mergeEnv merges env by name, values of override have a higher priority
+func mergeEnv(base, override []*Env) []*Env {
+	result := make([]*Env, 0, len(base)+len(override))
+	overridden := map[string]bool{}
+	for _, env := range override {
+		overridden[env.Name] = true
+	}
+	for _, env := range base {
+		if !overridden[env.Name] {
+			result = append(result, env)
+		}
+	}
+	return append(result, override...)
+}
+
+// copyContainerConfig deep copies c, so resolving never changes templates and containers
+func copyContainerConfig(c *ContainerConfig) *ContainerConfig {
+	result := &ContainerConfig{}
+	bys, err := json.Marshal(c)
+	if err == nil {
+		err = json.Unmarshal(bys, result)
+	}
+	if err != nil {
+		log.WarnE(err, "Failed to copy container config")
+		copied := *c
+		return &copied
+	}
+	return result
+}
+
+// Resolved returns a copy of the config whose services are merged with templates
+func (a *ApplicationConfig) Resolved() *ApplicationConfig {
+	result := *a
+	result.ServiceConfigs = make([]*ServiceConfigV2, 0, len(a.ServiceConfigs))
+	for _, svcConfig := range a.ServiceConfigs {
+		copied := *svcConfig
+		copied.templates = a.Templates
+		result.ServiceConfigs = append(result.ServiceConfigs, copied.Resolved())
+	}
+	// templates have been merged into services
+	result.Templates = nil
+	return &result
+}
@@ -0,0 +1,182 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package profile
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	customyaml3 "nocalhost/pkg/nhctl/utils/custom_yaml_v3"
+	"reflect"
+	"testing"
+)
+
+func TestContainerExtendsTemplate(t *testing.T) {
+	config := `
+application:
+  name: bookinfo
+  templates:
+    java-base:
+      dev:
+        image: maven:3-jdk-8
+        shell: bash
+        env:
+          - name: JAVA_OPTS
+            value: -Xmx512m
+    java-default:
+      extends: java-base
+      dev:
+        debug:
+          remoteDebugPort: 5005
+        resources:
+          limits:
+            cpu: "2"
+        sync:
+          type: send
+          ignoreFilePattern: [".git", "target"]
+  services:
+    - name: reviews
+      serviceType: deployment
+      containers:
+        - name: reviews
+          extends: java-default
+          dev:
+            workDir: /home/reviews
+            command:
+              run: ["mvn", "spring-boot:run"]
+            env:
+              - name: PROFILE
+                value: dev
+              - name: JAVA_OPTS
+                value: -Xmx1g
+        - name: sidecar
+          dev:
+            image: busybox
+`
+	appConfig := &NocalHostAppConfigV2{}
+	if err := yaml.Unmarshal([]byte(config), appConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	svcConfig := appConfig.GetSvcConfigS("reviews", "deployment")
+	dev := svcConfig.GetContainerDevConfigOrDefault("reviews")
+	if dev.Image != "maven:3-jdk-8" || dev.Shell != "bash" || dev.WorkDir != "/home/reviews" {
+		t.Errorf("unexpected dev config %+v", dev)
+	}
+	if dev.DebugConfig == nil || dev.DebugConfig.RemoteDebugPort != 5005 {
+		t.Errorf("unexpected debug config %+v", dev.DebugConfig)
+	}
+	if dev.DevContainerResources == nil || dev.DevContainerResources.Limits.Cpu != "2" {
+		t.Errorf("unexpected resources %+v", dev.DevContainerResources)
+	}
+	if dev.Sync == nil || !reflect.DeepEqual(dev.Sync.IgnoreFilePattern, []string{".git", "target"}) {
+		t.Errorf("unexpected sync config %+v", dev.Sync)
+	}
+	if len(dev.Env) != 2 || dev.Env[0].Name != "PROFILE" || dev.Env[1].Value != "-Xmx1g" {
+		t.Errorf("unexpected env %v", dev.Env)
+	}
+	if !reflect.DeepEqual(dev.Command.Run, []string{"mvn", "spring-boot:run"}) {
+		t.Errorf("unexpected command %v", dev.Command)
+	}
+
+	// templates and the raw config are not changed
+	raw := appConfig.ApplicationConfig.ServiceConfigs[0].ContainerConfigs[0]
+	if raw.Extends != "java-default" || raw.Dev.Image != "" {
+		t.Errorf("raw config should not be changed, %+v", raw.Dev)
+	}
+	if appConfig.ApplicationConfig.Templates["java-default"].Dev.Image != "" {
+		t.Error("template should not be changed")
+	}
+	if sidecar := svcConfig.GetContainerDevConfig("sidecar"); sidecar.Image != "busybox" || sidecar.Shell != "" {
+		t.Errorf("unexpected sidecar config %+v", sidecar)
+	}
+
+	resolved := appConfig.ApplicationConfig.Resolved()
+	if resolved.Templates != nil || resolved.ServiceConfigs[0].ContainerConfigs[0].Extends != "" ||
+		resolved.ServiceConfigs[0].ContainerConfigs[0].Dev.Image != "maven:3-jdk-8" {
+		t.Errorf("unexpected resolved config %+v", resolved.ServiceConfigs[0].ContainerConfigs[0])
+	}
+
+	// circular templates do not hang
+	appConfig.ApplicationConfig.Templates["java-base"].Extends = "java-default"
+	svcConfig = appConfig.GetSvcConfigS("reviews", "deployment")
+	if dev = svcConfig.GetContainerDevConfig("reviews"); dev.Image != "maven:3-jdk-8" {
+		t.Errorf("unexpected dev config with circular templates %+v", dev)
+	}
+}
+
+func TestContainerOverridesTemplateWithZeroValues(t *testing.T) {
+	config := `
+application:
+  name: bookinfo
+  templates:
+    java-base:
+      dev:
+        image: maven:3-jdk-8
+        shell: bash
+        hotReload: true
+        sync:
+          useGitIgnore: true
+  services:
+    - name: reviews
+      serviceType: deployment
+      containers:
+        - name: reviews
+          extends: java-base
+          dev:
+            shell: ""
+            hotReload: false
+        - name: ratings
+          extends: java-base
+          dev:
+            sync:
+              useGitIgnore: false
+`
+	appConfig := &NocalHostAppConfigV2{}
+	if err := yaml.Unmarshal([]byte(config), appConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(appConfig *NocalHostAppConfigV2, from string) {
+		svcConfig := appConfig.GetSvcConfigS("reviews", "deployment")
+		reviews := svcConfig.GetContainerDevConfig("reviews")
+		if reviews.HotReload || reviews.Shell != "" || reviews.Image != "maven:3-jdk-8" ||
+			reviews.Sync == nil || !reviews.Sync.UseGitIgnore {
+			t.Errorf("zero values written should override the template (%s), got %+v", from, reviews)
+		}
+		ratings := svcConfig.GetContainerDevConfig("ratings")
+		if !ratings.HotReload || ratings.Shell != "bash" || ratings.Sync == nil || ratings.Sync.UseGitIgnore {
+			t.Errorf("zero values written should override the template (%s), got %+v", from, ratings)
+		}
+	}
+	check(appConfig, "yaml")
+
+	// config files are parsed by custom_yaml_v3
+	appConfig = &NocalHostAppConfigV2{}
+	if err := customyaml3.Unmarshal([]byte(config), appConfig); err != nil {
+		t.Fatal(err)
+	}
+	check(appConfig, "custom yaml")
+
+	// written fields are kept after the config is saved and loaded again
+	bys, err := yaml.Marshal(appConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := &NocalHostAppConfigV2{}
+	if err = yaml.Unmarshal(bys, reloaded); err != nil {
+		t.Fatal(err)
+	}
+	check(reloaded, "yaml saved")
+
+	if bys, err = json.Marshal(appConfig); err != nil {
+		t.Fatal(err)
+	}
+	reloaded = &NocalHostAppConfigV2{}
+	if err = json.Unmarshal(bys, reloaded); err != nil {
+		t.Fatal(err)
+	}
+	check(reloaded, "json saved")
+}
@@ -26,6 +26,8 @@ type NocalHostAppConfigV2 struct {
 type ConfigProperties struct {
 	Version string `json:"version" yaml:"version"`
 	EnvFile string `json:"envFile" yaml:"envFile"`
+	// Overlays named overlays which can be merged over application by --overlay
+	Overlays map[string]*ApplicationConfig `json:"overlays,omitempty" yaml:"overlays,omitempty"`
 }
 
 type ApplicationConfig struct {
@@ -47,6 +49,9 @@ type ApplicationConfig struct {
 	Env            []*Env             `json:"env" yaml:"env"`
 	EnvFrom        EnvFrom            `json:"envFrom,omitempty" yaml:"envFrom,omitempty"`
 	ServiceConfigs []*ServiceConfigV2 `json:"services" yaml:"services,omitempty"`
+
+	// Templates container configs which can be extended by containers of services
+	Templates map[string]*ContainerConfig `json:"templates,omitempty" yaml:"templates,omitempty"`
 }
 
 type HubConfig struct {
@@ -69,6 +74,7 @@ type ContainerDevConfig struct {
 	DevContainerName      string                 `json:"devContainerName,omitempty" yaml:"devContainerName,omitempty"`
 	DevContainerResources *ResourceQuota         `json:"resources" yaml:"resources"`
 	PersistentVolumeDirs  []*PersistentVolumeDir `validate:"dive" json:"persistentVolumeDirs" yaml:"persistentVolumeDirs"`
+	VolumeSnapshot        string                 `json:"volumeSnapshot,omitempty" yaml:"volumeSnapshot,omitempty"`
 	Command               *DevCommands           `json:"command" yaml:"command"`
 	DebugConfig           *DebugConfig           `json:"debug" yaml:"debug"`
 	HotReload             bool                   `json:"hotReload" yaml:"hotReload"`
@@ -95,6 +101,23 @@ type SyncConfig struct {
 	DeleteProtection  *bool    `json:"deleteProtection,omitempty" yaml:"deleteProtection,omitempty"`
 	FilePattern       []string `json:"filePattern" yaml:"filePattern"`
 	IgnoreFilePattern []string `json:"ignoreFilePattern" yaml:"ignoreFilePattern"`
+
+	// Folders synced besides the dir associated, each of them is synced independently
+	Folders []*SyncFolder `validate:"dive" json:"folders,omitempty" yaml:"folders,omitempty"`
+	// UseGitIgnore translates .gitignore files and .git/info/exclude of the dir associated to ignored
+	// patterns, unlike mode gitIgnore, FilePattern and IgnoreFilePattern still work
+	UseGitIgnore bool `json:"useGitIgnore,omitempty" yaml:"useGitIgnore,omitempty"`
+}
+
+// SyncFolder a local dir synced to RemotePath of DevContainer
+type SyncFolder struct {
+	// LocalPath is relative to the dir associated if it is not absolute
+	LocalPath  string `json:"localPath" yaml:"localPath"`
+	RemotePath string `json:"remotePath" yaml:"remotePath"`
+	// Type of the folder, type of sync is used if it is empty
+	Type              string   `validate:"SyncType" json:"type,omitempty" yaml:"type,omitempty"`
+	FilePattern       []string `json:"filePattern,omitempty" yaml:"filePattern,omitempty"`
+	IgnoreFilePattern []string `json:"ignoreFilePattern,omitempty" yaml:"ignoreFilePattern,omitempty"`
 }
 
 type DebugConfig struct {
@@ -102,11 +125,25 @@ type DebugConfig struct {
 	Language        string `validate:"Language" json:"language" yaml:"language"`
 }
 
+// DependencyTimeoutSuffix overrides the timeout of a dependency, such as `reviews:9080 timeout=30s`
+const DependencyTimeoutSuffix = " timeout="
+
+// DependLabelSelector dependencies waited by an init container before the service starts,
+// each of them fails after Timeout, which can be overridden by DependencyTimeoutSuffix of the item
 type DependLabelSelector struct {
-	Pods []string `json:"pods" yaml:"pods"`
-	Jobs []string `json:"jobs" yaml:"jobs"`
-	TCP  []string `json:"tcp" yaml:"tcp"`
-	HTTP []string `json:"http" yaml:"http"`
+	Pods []string `validate:"dive,Duration=dependency" json:"pods" yaml:"pods"`
+	Jobs []string `validate:"dive,Duration=dependency" json:"jobs" yaml:"jobs"`
+	TCP  []string `validate:"dive,Duration=dependency" json:"tcp" yaml:"tcp"`
+	HTTP []string `validate:"dive,Duration=dependency" json:"http" yaml:"http"`
+	// host:port, or host:port/service to check a service of grpc.health.v1
+	GRPC []string `validate:"dive,Duration=dependency" json:"grpc,omitempty" yaml:"grpc,omitempty"`
+	// host names to be resolved
+	DNS []string `validate:"dive,Duration=dependency" json:"dns,omitempty" yaml:"dns,omitempty"`
+	// broker:port/topic
+	Kafka []string `validate:"dive,Duration=dependency" json:"kafka,omitempty" yaml:"kafka,omitempty"`
+	// services with ready endpoints, such as name or namespace/name
+	Endpoints []string `validate:"dive,Duration=dependency" json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	Timeout   string   `validate:"Duration" json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 type HelmValue struct {
@@ -131,6 +168,7 @@ func (n *NocalHostAppConfigV2) GetSvcConfigV2(svcName string, svcType base.SvcTy
 	if n != nil {
 		for _, config := range n.ApplicationConfig.ServiceConfigs {
 			if config.Name == svcName && base.SvcType(config.Type) == svcType {
+				config.templates = n.ApplicationConfig.Templates
 				return config
 			}
 		}
@@ -142,16 +180,25 @@ func (n *NocalHostAppConfigV2) GetSvcConfigV2(svcName string, svcType base.SvcTy
 func (n *NocalHostAppConfigV2) GetSvcConfigS(svcName string, svcType base.SvcType) ServiceConfigV2 {
 	for _, config := range n.ApplicationConfig.ServiceConfigs {
 		if config.Name == svcName && base.SvcType(config.Type) == svcType {
-			return *config
+			result := *config
+			result.templates = n.ApplicationConfig.Templates
+			return result
 		}
 	}
-	return ServiceConfigV2{Name: svcName, Type: string(svcType)}
+	return ServiceConfigV2{Name: svcName, Type: string(svcType), templates: n.ApplicationConfig.Templates}
 }
 
 func (n *NocalHostAppConfigV2) SetSvcConfigV2(svcConfig ServiceConfigV2) {
 	if svcConfig.Name == "" || svcConfig.Type == "" {
 		return
 	}
+	// keep templates the config was loaded with, such as templates of local config
+	for name, template := range svcConfig.templates {
+		if n.ApplicationConfig.Templates == nil {
+			n.ApplicationConfig.Templates = map[string]*ContainerConfig{}
+		}
+		n.ApplicationConfig.Templates[name] = template
+	}
 	foundIndex := -1
 	for index, config := range n.ApplicationConfig.ServiceConfigs {
 		if config.Name == svcConfig.Name && config.Type == svcConfig.Type {
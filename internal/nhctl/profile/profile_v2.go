@@ -30,6 +30,8 @@ const (
 	DefaultWorkDir   = "/home/nocalhost-dev"
 	DuplicateDevMode = DevModeType("duplicate")
 	ReplaceDevMode   = DevModeType("replace")
+	EphemeralDevMode = DevModeType("ephemeral")
+	LocalDevMode     = DevModeType("local")
 	NoneDevMode      = DevModeType("")
 )
 
@@ -44,6 +46,18 @@ func (d DevModeType) IsDuplicateDevMode() bool {
 	return d == DuplicateDevMode
 }
 
+// IsEphemeralDevMode DevContainer is attached to a running pod as an ephemeral container,
+// the workload's pod template will not be modified
+func (d DevModeType) IsEphemeralDevMode() bool {
+	return d == EphemeralDevMode
+}
+
+// IsLocalDevMode the service runs in the developer's machine,
+// traffic to the workload is reversed to it through vpn
+func (d DevModeType) IsLocalDevMode() bool {
+	return d == LocalDevMode
+}
+
 func (d DevModeType) ToString() string {
 	if d == "" {
 		return string(ReplaceDevMode)
@@ -296,6 +310,26 @@ type SvcProfileV2 struct {
 	// recorded the container that enter the devmode
 	// notice: exit devmode will not set this value to null
 	OriginDevContainer string `json:"originDevContainer" yaml:"originDevContainer"`
+
+	// from workload annotation, hpa suspended while in DevMode
+	SuspendedHPA []*SuspendedHPA `json:"suspendedHPA,omitempty" yaml:"suspendedHPA,omitempty"`
+
+	// env file and dir of volumes exported by local DevMode, they are removed while ending DevMode
+	LocalDevEnvFile   string `json:"localDevEnvFile,omitempty" yaml:"localDevEnvFile,omitempty"`
+	LocalDevVolumeDir string `json:"localDevVolumeDir,omitempty" yaml:"localDevVolumeDir,omitempty"`
+
+	// file sync paused by `nhctl sync pause`, it is kept while syncthing is restarted
+	SyncPaused bool `json:"syncPaused,omitempty" yaml:"syncPaused,omitempty"`
+
+	// overlay selected by `dev start --overlay`, it is merged again while the svc config is reloaded
+	ConfigOverlay string `json:"configOverlay,omitempty" yaml:"configOverlay,omitempty"`
+}
+
+// SuspendedHPA records the replicas of a hpa before it is pinned to 1 in DevMode
+type SuspendedHPA struct {
+	Name        string `json:"name" yaml:"name"`
+	MinReplicas *int32 `json:"minReplicas,omitempty" yaml:"minReplicas,omitempty"`
+	MaxReplicas int32  `json:"maxReplicas" yaml:"maxReplicas"`
 }
 
 type ContainerProfileV2 struct {
@@ -317,6 +351,9 @@ type DevPortForward struct {
 	Sudo            bool              `json:"sudo" yaml:"sudo"`
 	DaemonServerPid int               `json:"daemonserverpid" yaml:"daemonserverpid"`
 	ServiceType     string            `json:"servicetype" yaml:"servicetype"`
+
+	// Target forwarded to instead of a pod, svc/<name>:<port>, PodName is the pod backing it currently
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
 }
 
 func (s *SvcProfileV2) GetName() string {
@@ -11,13 +11,21 @@ type ServiceConfigV2 struct {
 	PriorityClass       string               `json:"priorityClass,omitempty" yaml:"priorityClass,omitempty"`
 	DependLabelSelector *DependLabelSelector `json:"dependLabelSelector,omitempty" yaml:"dependLabelSelector,omitempty"`
 	ContainerConfigs    []*ContainerConfig   `validate:"dive" json:"containers" yaml:"containers"`
+
+	// templates of the application, containers are merged with templates they extend
+	templates map[string]*ContainerConfig
 }
 
 type ContainerConfig struct {
 	Name    string                  `validate:"Container" json:"name" yaml:"name"`
+	Extends string                  `json:"extends,omitempty" yaml:"extends,omitempty"`
 	Hub     *HubConfig              `json:"hub" yaml:"hub,omitempty"`
 	Install *ContainerInstallConfig `json:"install,omitempty" yaml:"install,omitempty"`
 	Dev     *ContainerDevConfig     `json:"dev" yaml:"dev"`
+
+	// written fields of a container extending a template while it is unmarshalled,
+	// by json names, they override the template even if they are zero
+	written map[string]interface{}
 }
 
 func (s *ServiceConfigV2) GetContainerConfig(container string) *ContainerConfig {
@@ -26,7 +34,7 @@ func (s *ServiceConfigV2) GetContainerConfig(container string) *ContainerConfig
 	}
 	for _, c := range s.ContainerConfigs {
 		if c.Name == container {
-			return c
+			return s.resolveContainerConfig(c)
 		}
 	}
 	return nil
@@ -36,7 +44,7 @@ func (s *ServiceConfigV2) GetDefaultContainerDevConfig() *ContainerDevConfig {
 	if len(s.ContainerConfigs) == 0 {
 		return nil
 	}
-	return s.ContainerConfigs[0].Dev
+	return s.resolveContainerConfig(s.ContainerConfigs[0]).Dev
 }
 
 // GetContainerDevConfigOrDefault Compatible for v1
@@ -52,10 +60,21 @@ func (s *ServiceConfigV2) GetContainerDevConfigOrDefault(containerName string) *
 	return config
 }
 
+// GetContainerConfigNameOrDefault returns name of the container config GetContainerDevConfigOrDefault finds
+func (s *ServiceConfigV2) GetContainerConfigNameOrDefault(containerName string) string {
+	if containerName != "" && s.GetContainerDevConfig(containerName) != nil {
+		return containerName
+	}
+	if len(s.ContainerConfigs) == 0 {
+		return containerName
+	}
+	return s.ContainerConfigs[0].Name
+}
+
 func (s *ServiceConfigV2) GetContainerDevConfig(containerName string) *ContainerDevConfig {
 	for _, devConfig := range s.ContainerConfigs {
 		if devConfig.Name == containerName {
-			return devConfig.Dev
+			return s.resolveContainerConfig(devConfig).Dev
 		}
 	}
 	return nil
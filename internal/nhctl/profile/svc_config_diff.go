@@ -0,0 +1,142 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package profile
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// liveDevFields fields of dev config which can be applied to the container in running DevMode
+var liveDevFields = map[string]bool{
+	"portForward":            true,
+	"sync.filePattern":       true,
+	"sync.ignoreFilePattern": true,
+	"sync.useGitIgnore":      true,
+}
+
+// SvcConfigChanges fields changed between two configs of a service,
+// fields of containers are in the form of containers[name].dev.portForward
+type SvcConfigChanges struct {
+	// Live changes are applied to the running DevMode, such as port-forwards and sync patterns
+	Live []string
+	// NeedRestart changes take effect after DevMode is restarted
+	NeedRestart []string
+}
+
+func (s *SvcConfigChanges) IsEmpty() bool {
+	return len(s.Live) == 0 && len(s.NeedRestart) == 0
+}
+
+func (s *SvcConfigChanges) String() string {
+	result := make([]string, 0)
+	if len(s.Live) > 0 {
+		result = append(result, "applied live: "+strings.Join(s.Live, ", "))
+	}
+	if len(s.NeedRestart) > 0 {
+		result = append(result, "need dev restart: "+strings.Join(s.NeedRestart, ", "))
+	}
+	return strings.Join(result, "; ")
+}
+
+func (s *SvcConfigChanges) add(field string, live bool) {
+	if live {
+		s.Live = append(s.Live, field)
+	} else {
+		s.NeedRestart = append(s.NeedRestart, field)
+	}
+}
+
+// DiffSvcConfig returns fields changed from `from` to `to`, install configs are ignored
+// as they have nothing to do with DevMode. Only changes of liveContainers, the containers
+// in running DevMode, can be applied live, the same changes of others need dev restart
+func DiffSvcConfig(from, to *ServiceConfigV2, liveContainers ...string) *SvcConfigChanges {
+	changes := &SvcConfigChanges{}
+	if from == nil {
+		from = &ServiceConfigV2{}
+	}
+	if to == nil {
+		to = &ServiceConfigV2{}
+	}
+	// containers are compared after merged with templates
+	from, to = from.Resolved(), to.Resolved()
+
+	if from.PriorityClass != to.PriorityClass {
+		changes.add("priorityClass", false)
+	}
+	if !reflect.DeepEqual(from.DependLabelSelector, to.DependLabelSelector) {
+		changes.add("dependLabelSelector", false)
+	}
+
+	fromContainers := containerConfigMap(from.ContainerConfigs)
+	toContainers := containerConfigMap(to.ContainerConfigs)
+	names := make([]string, 0)
+	for name := range fromContainers {
+		names = append(names, name)
+	}
+	for name := range toContainers {
+		if _, ok := fromContainers[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	live := make(map[string]bool, len(liveContainers))
+	for _, name := range liveContainers {
+		live[name] = true
+	}
+
+	for _, name := range names {
+		prefix := fmt.Sprintf("containers[%s]", name)
+		fromContainer, toContainer := fromContainers[name], toContainers[name]
+		if fromContainer == nil || toContainer == nil {
+			changes.add(prefix, false)
+			continue
+		}
+		if !reflect.DeepEqual(fromContainer.Hub, toContainer.Hub) {
+			changes.add(prefix+".hub", false)
+		}
+		diffStruct(prefix+".dev.", "", fromContainer.Dev, toContainer.Dev, live[name], changes)
+	}
+	return changes
+}
+
+func containerConfigMap(configs []*ContainerConfig) map[string]*ContainerConfig {
+	result := make(map[string]*ContainerConfig, len(configs))
+	for _, config := range configs {
+		if config != nil {
+			result[config.Name] = config
+		}
+	}
+	return result
+}
+
+// diffStruct compares fields of two struct pointers by json names, sync config
+// is compared field by field as patterns of it can be applied live if live is true
+func diffStruct(prefix, field string, from, to interface{}, live bool, changes *SvcConfigChanges) {
+	fromValue, toValue := reflect.ValueOf(from), reflect.ValueOf(to)
+	if fromValue.IsNil() || toValue.IsNil() {
+		if fromValue.IsNil() != toValue.IsNil() {
+			changes.add(strings.TrimSuffix(prefix+field, "."), false)
+		}
+		return
+	}
+
+	fromValue, toValue = fromValue.Elem(), toValue.Elem()
+	for i := 0; i < fromValue.NumField(); i++ {
+		name := field + strings.Split(fromValue.Type().Field(i).Tag.Get("json"), ",")[0]
+		f, t := fromValue.Field(i).Interface(), toValue.Field(i).Interface()
+		if _, ok := f.(*SyncConfig); ok {
+			diffStruct(prefix, name+".", f, t, live, changes)
+			continue
+		}
+		if !reflect.DeepEqual(f, t) {
+			changes.add(prefix+name, live && liveDevFields[name])
+		}
+	}
+}
@@ -0,0 +1,115 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package profile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSvcConfig(t *testing.T) {
+	from := &ServiceConfigV2{
+		Name: "reviews",
+		Type: "deployment",
+		ContainerConfigs: []*ContainerConfig{
+			{
+				Name: "reviews",
+				Dev: &ContainerDevConfig{
+					Image:       "golang:1.16",
+					PortForward: []string{"8080:8080"},
+					Sync:        &SyncConfig{Type: "send", FilePattern: []string{"."}},
+				},
+			},
+			{Name: "sidecar", Dev: &ContainerDevConfig{Image: "busybox"}},
+		},
+	}
+	to := &ServiceConfigV2{
+		Name: "reviews",
+		Type: "deployment",
+		ContainerConfigs: []*ContainerConfig{
+			{
+				Name: "reviews",
+				Dev: &ContainerDevConfig{
+					Image:       "golang:1.17",
+					PortForward: []string{"8080:8080", "9090:9090"},
+					Sync: &SyncConfig{
+						Type: "send", FilePattern: []string{"."}, IgnoreFilePattern: []string{".git"},
+					},
+					Env: []*Env{{Name: "DEBUG", Value: "true"}},
+				},
+			},
+		},
+	}
+
+	changes := DiffSvcConfig(from, to, "reviews")
+	if !reflect.DeepEqual(
+		changes.Live, []string{"containers[reviews].dev.sync.ignoreFilePattern", "containers[reviews].dev.portForward"},
+	) {
+		t.Errorf("unexpected live changes %v", changes.Live)
+	}
+	if !reflect.DeepEqual(
+		changes.NeedRestart,
+		[]string{"containers[reviews].dev.image", "containers[reviews].dev.env", "containers[sidecar]"},
+	) {
+		t.Errorf("unexpected changes need restart %v", changes.NeedRestart)
+	}
+
+	if changes = DiffSvcConfig(to, to, "reviews"); !changes.IsEmpty() {
+		t.Errorf("same configs should not have changes, got %s", changes)
+	}
+}
+
+func TestDiffSvcConfigLiveContainers(t *testing.T) {
+	config := func(portForward string, ignored string) *ServiceConfigV2 {
+		return &ServiceConfigV2{
+			Name: "reviews",
+			Type: "deployment",
+			ContainerConfigs: []*ContainerConfig{
+				{
+					Name: "reviews",
+					Dev: &ContainerDevConfig{
+						PortForward: []string{portForward},
+						Sync:        &SyncConfig{IgnoreFilePattern: []string{ignored}},
+					},
+				},
+				{
+					Name: "sidecar",
+					Dev: &ContainerDevConfig{
+						PortForward: []string{portForward},
+						Sync:        &SyncConfig{IgnoreFilePattern: []string{ignored}},
+					},
+				},
+			},
+		}
+	}
+	from, to := config("8080:8080", ".git"), config("9090:9090", ".idea")
+
+	changes := DiffSvcConfig(from, to, "sidecar")
+	if !reflect.DeepEqual(
+		changes.Live, []string{"containers[sidecar].dev.sync.ignoreFilePattern", "containers[sidecar].dev.portForward"},
+	) {
+		t.Errorf("unexpected live changes %v", changes.Live)
+	}
+	if !reflect.DeepEqual(
+		changes.NeedRestart,
+		[]string{"containers[reviews].dev.sync.ignoreFilePattern", "containers[reviews].dev.portForward"},
+	) {
+		t.Errorf("unexpected changes need restart %v", changes.NeedRestart)
+	}
+
+	// nothing can be applied live without DevMode
+	if changes = DiffSvcConfig(from, to); len(changes.Live) != 0 || len(changes.NeedRestart) != 4 {
+		t.Errorf("all changes should need restart without DevMode, got %s", changes)
+	}
+
+	// the first container is in DevMode if the container is not specified
+	if name := to.GetContainerConfigNameOrDefault(""); name != "reviews" {
+		t.Errorf("the first container expected, but got %s", name)
+	}
+	if name := to.GetContainerConfigNameOrDefault("sidecar"); name != "sidecar" {
+		t.Errorf("sidecar expected, but got %s", name)
+	}
+}
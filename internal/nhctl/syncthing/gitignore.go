@@ -0,0 +1,202 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package syncthing
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	gitIgnoreFile   = ".gitignore"
+	gitExcludeFile  = ".git/info/exclude"
+	globSpecialChar = "*?[\\"
+)
+
+// GitIgnoreFiles returns .gitignore files in dir and .git/info/exclude of it,
+// dirs ignored by them are not walked
+func GitIgnoreFiles(dir string) []string {
+	files := make([]string, 0)
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(gitExcludeFile))); err == nil {
+		files = append(files, filepath.Join(dir, filepath.FromSlash(gitExcludeFile)))
+	}
+
+	// names ignored in dirs, and paths ignored, such as node_modules
+	ignoredNames := map[string][]string{}
+	ignoredPaths := map[string]bool{}
+	for _, line := range readGitIgnore(filepath.Join(dir, filepath.FromSlash(gitExcludeFile))) {
+		addIgnoredDir(line, "", ignoredNames, ignoredPaths)
+	}
+	_ = filepath.Walk(
+		dir, func(file string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, file)
+			if err != nil {
+				return nil
+			}
+			rel = filepath.ToSlash(rel)
+			if rel == "." {
+				rel = ""
+			}
+			if info.Name() == ".git" || ignoredPaths[rel] || isNameIgnored(info.Name(), rel, ignoredNames) {
+				return filepath.SkipDir
+			}
+			ignoreFile := filepath.Join(file, gitIgnoreFile)
+			if _, err := os.Stat(ignoreFile); err != nil {
+				return nil
+			}
+			files = append(files, ignoreFile)
+			for _, line := range readGitIgnore(ignoreFile) {
+				addIgnoredDir(line, rel, ignoredNames, ignoredPaths)
+			}
+			return nil
+		},
+	)
+	return files
+}
+
+// GitIgnoreRules translates .gitignore files in dir and .git/info/exclude to ignores of syncthing.
+// The first matched ignore wins in syncthing while the last matched pattern wins in git, so
+// rules of deeper .gitignore files come first, and lines of a file are reversed
+func GitIgnoreRules(dir string) []string {
+	type ignoreFile struct {
+		base  string
+		depth int
+		lines []string
+	}
+	ignoreFiles := make([]*ignoreFile, 0)
+	for _, file := range GitIgnoreFiles(dir) {
+		rel, err := filepath.Rel(dir, filepath.Dir(file))
+		if err != nil {
+			continue
+		}
+		f := &ignoreFile{base: filepath.ToSlash(rel), lines: readGitIgnore(file)}
+		switch {
+		case strings.HasSuffix(filepath.ToSlash(file), gitExcludeFile):
+			// patterns of exclude are relative to the root, and with the lowest priority
+			f.base, f.depth = "", -1
+		case f.base == ".":
+			f.base = ""
+		default:
+			f.depth = strings.Count(f.base, "/") + 1
+		}
+		ignoreFiles = append(ignoreFiles, f)
+	}
+	sort.SliceStable(
+		ignoreFiles, func(i, j int) bool {
+			return ignoreFiles[i].depth > ignoreFiles[j].depth
+		},
+	)
+
+	rules := make([]string, 0)
+	for _, f := range ignoreFiles {
+		for i := len(f.lines) - 1; i >= 0; i-- {
+			rules = append(rules, translateGitIgnore(f.lines[i], f.base)...)
+		}
+	}
+	return rules
+}
+
+// translateGitIgnore translates a pattern of .gitignore in dir base to ignores of syncthing,
+// patterns with a slash at the beginning or in the middle are relative to base,
+// others match in any dir below base
+func translateGitIgnore(line, base string) []string {
+	negate := strings.HasPrefix(line, "!")
+	if negate {
+		line = line[1:]
+	}
+	if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+		line = line[1:]
+	}
+	// patterns only matching dirs match files of the name too, which are rarely seen
+	line = strings.TrimSuffix(line, "/")
+	if line == "" {
+		return nil
+	}
+
+	prefix := "/"
+	if base != "" {
+		prefix = "/" + base + "/"
+	}
+	var rules []string
+	switch {
+	case strings.Contains(line, "/"):
+		line = strings.TrimPrefix(line, "/")
+		rules = []string{prefix + line}
+		// ** of syncthing matches at least one dir
+		if strings.HasPrefix(line, "**/") {
+			rules = append(rules, prefix+strings.TrimPrefix(line, "**/"))
+		}
+	case base == "":
+		// patterns without a leading slash match in any dir in syncthing
+		rules = []string{line}
+	default:
+		rules = []string{prefix + line, prefix + "**/" + line}
+	}
+
+	if negate {
+		for i := range rules {
+			rules[i] = "!" + rules[i]
+		}
+	}
+	return rules
+}
+
+// readGitIgnore returns patterns of a .gitignore file, comments and blank lines are skipped
+func readGitIgnore(file string) []string {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		// trailing spaces are ignored unless they are escaped
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// addIgnoredDir records dirs ignored by a plain name or path, so that they are not walked
+func addIgnoredDir(line, base string, ignoredNames map[string][]string, ignoredPaths map[string]bool) {
+	if strings.HasPrefix(line, "!") || strings.ContainsAny(line, globSpecialChar) {
+		return
+	}
+	line = strings.TrimSuffix(line, "/")
+	if line == "" {
+		return
+	}
+	if !strings.Contains(line, "/") {
+		ignoredNames[line] = append(ignoredNames[line], base)
+		return
+	}
+	ignoredPaths[path.Join(base, strings.TrimPrefix(line, "/"))] = true
+}
+
+// isNameIgnored returns if the dir rel is ignored by its name in an ancestor dir
+func isNameIgnored(name, rel string, ignoredNames map[string][]string) bool {
+	for _, base := range ignoredNames[name] {
+		if base == "" || strings.HasPrefix(rel, base+"/") {
+			return true
+		}
+	}
+	return false
+}
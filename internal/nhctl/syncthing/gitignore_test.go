@@ -0,0 +1,76 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package syncthing
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTranslateGitIgnore(t *testing.T) {
+	for _, c := range []struct {
+		line  string
+		base  string
+		rules []string
+	}{
+		{"*.log", "", []string{"*.log"}},
+		{"target/", "", []string{"target"}},
+		{"/build", "", []string{"/build"}},
+		{"/build", "sub", []string{"/sub/build"}},
+		{"*.log", "sub", []string{"/sub/*.log", "/sub/**/*.log"}},
+		{"docs/*.html", "sub", []string{"/sub/docs/*.html"}},
+		{"**/tmp", "sub", []string{"/sub/**/tmp", "/sub/tmp"}},
+		{"!keep.log", "sub", []string{"!/sub/keep.log", "!/sub/**/keep.log"}},
+		{`\#hash`, "", []string{"#hash"}},
+		{"/", "", nil},
+	} {
+		if rules := translateGitIgnore(c.line, c.base); !reflect.DeepEqual(rules, c.rules) {
+			t.Errorf("%s in %q should be translated to %v, but got %v", c.line, c.base, c.rules, rules)
+		}
+	}
+}
+
+func TestGitIgnoreRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for file, content := range map[string]string{
+		".gitignore":                     "# comment\ntarget/\n*.log\nnode_modules\n",
+		"sub/.gitignore":                 "/build\n!keep.log\n",
+		".git/info/exclude":              ".idea\n",
+		"node_modules/pkg/.gitignore":    "*.js\n",
+		"other/node_modules/.gitignore":  "*.js\n",
+		"other/src/node_modules/a/a.txt": "",
+	} {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755)
+		if err = ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := GitIgnoreFiles(dir)
+	for i := range files {
+		files[i], _ = filepath.Rel(dir, files[i])
+		files[i] = filepath.ToSlash(files[i])
+	}
+	if expected := []string{".git/info/exclude", ".gitignore", "sub/.gitignore"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf(".gitignore files should be %v, but got %v", expected, files)
+	}
+
+	expected := []string{
+		"!/sub/keep.log", "!/sub/**/keep.log", "/sub/build",
+		"node_modules", "*.log", "target",
+		".idea",
+	}
+	if rules := GitIgnoreRules(dir); !reflect.DeepEqual(rules, expected) {
+		t.Errorf("rules should be %v, but got %v", expected, rules)
+	}
+}
@@ -8,7 +8,7 @@ package local
 // follow text is the default configuration template for syncthing local
 const LocalSyncConfigXML = `<configuration version="32">
 {{ range .Folders }}
-<folder id="nh-{{ .Name }}" label="{{ .Name }}" path="{{ .LocalPath }}" type="{{ $.Type }}" 
+<folder id="nh-{{ .Name }}" label="{{ .Name }}" path="{{ .LocalPath }}" type="{{ or .Type $.Type }}" 
 rescanIntervalS="{{ $.RescanInterval }}" fsWatcherEnabled="true" 
 fsWatcherDelayS="1" ignorePerms="false" autoNormalize="true">
 	<filesystemType>basic</filesystemType>
@@ -23,10 +23,10 @@ fsWatcherDelayS="1" ignorePerms="false" autoNormalize="true">
 	<ignoreDelete>{{ $.IgnoreDelete }}</ignoreDelete>
 	<scanProgressIntervalS>2</scanProgressIntervalS>
 	<pullerPauseS>0</pullerPauseS>
-	<maxConflicts>0</maxConflicts>
+	<maxConflicts>10</maxConflicts>
 	<disableSparseFiles>false</disableSparseFiles>
 	<disableTempIndexes>false</disableTempIndexes>
-	<paused>false</paused>
+	<paused>{{ $.Paused }}</paused>
 	<weakHashThresholdPct>25</weakHashThresholdPct>
 	<markerName>.</markerName>
 	<useLargeBlocks>false</useLargeBlocks>
@@ -116,7 +116,7 @@ fsWatcherDelayS="1" ignorePerms="false" autoNormalize="true">
 	<ignoreDelete>false</ignoreDelete>
 	<scanProgressIntervalS>2</scanProgressIntervalS>
 	<pullerPauseS>0</pullerPauseS>
-	<maxConflicts>0</maxConflicts>
+	<maxConflicts>10</maxConflicts>
 	<disableSparseFiles>false</disableSparseFiles>
 	<disableTempIndexes>false</disableTempIndexes>
 	<paused>false</paused>
@@ -213,6 +213,9 @@ const IgnoredFileTemplate = `
 // Ignored pattern block, the priority of ignored pattern is highest, default is ""
 {{.ignoredPattern}}
 
+// Ignored pattern translated from .gitignore files, default is ""
+{{.gitIgnoredPattern}}
+
 // Synced pattern block, default is "!**"
 {{.syncedPattern}}
 
@@ -0,0 +1,153 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package req
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	ConflictModifiedByLocal  = "local"
+	ConflictModifiedByRemote = "remote"
+)
+
+// syncthing names conflict copies as <name>.sync-conflict-<date>-<time>-<short device id>.<ext>
+var conflictCopyRegex = regexp.MustCompile(`^(.*)\.sync-conflict-(\d{8}-\d{6})-([A-Z2-7]{7})(\.[^.]*)?$`)
+
+// SyncConflict a conflict copy left by syncthing, which holds the losing version of File
+type SyncConflict struct {
+	File string `json:"file" yaml:"file"`
+	Copy string `json:"copy" yaml:"copy"`
+	// ModifiedBy local or remote, whose change is kept in the copy
+	ModifiedBy string `json:"modifiedBy" yaml:"modifiedBy"`
+	Time       string `json:"time" yaml:"time"`
+	// LocalDir the synced folder the copy is in, empty if the copy is only in the remote
+	LocalDir string `json:"localDir,omitempty" yaml:"localDir,omitempty"`
+}
+
+// ParseConflictCopy returns the file and the short id of the device which modified
+// the copy, ok is false if name is not a conflict copy
+func ParseConflictCopy(name string) (file, device, time string, ok bool) {
+	dir, base := path.Split(filepath.ToSlash(name))
+	match := conflictCopyRegex.FindStringSubmatch(base)
+	if match == nil {
+		return "", "", "", false
+	}
+	return dir + match[1] + match[4], match[3], match[2], true
+}
+
+// ShortDeviceID returns the short id of a device used in names of conflict copies
+func ShortDeviceID(deviceID string) string {
+	id := strings.ReplaceAll(deviceID, "-", "")
+	if len(id) > 7 {
+		return id[:7]
+	}
+	return id
+}
+
+// Conflicts lists conflict copies in localDir and copies of the remote which are not synced
+// to local yet, the copies found are returned even if failed to request syncthing. Paths
+// relative to localDir are skipped if ignored returns true, they are never synced by syncthing
+func (p *SyncthingHttpClient) Conflicts(localDir string, ignored func(rel string) bool) ([]*SyncConflict, error) {
+	conflicts := make([]*SyncConflict, 0)
+	found := map[string]bool{}
+	add := func(copyPath, localDir string) {
+		file, device, time, ok := ParseConflictCopy(copyPath)
+		if !ok || found[copyPath] {
+			return
+		}
+		found[copyPath] = true
+		modifiedBy := ConflictModifiedByLocal
+		if device == ShortDeviceID(p.remoteDevice) {
+			modifiedBy = ConflictModifiedByRemote
+		}
+		conflicts = append(
+			conflicts, &SyncConflict{
+				File: file, Copy: copyPath, ModifiedBy: modifiedBy, Time: time, LocalDir: localDir,
+			},
+		)
+	}
+
+	if localDir != "" {
+		_ = filepath.Walk(
+			localDir, func(file string, info os.FileInfo, err error) error {
+				if err != nil {
+					return nil
+				}
+				rel, err := filepath.Rel(localDir, file)
+				if err != nil || rel == "." {
+					return nil
+				}
+				if info.Name() == ".git" || (ignored != nil && ignored(rel)) {
+					if info.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if !info.IsDir() {
+					add(filepath.ToSlash(rel), localDir)
+				}
+				return nil
+			},
+		)
+	}
+
+	need, err := p.Need()
+	for _, name := range need {
+		add(name, "")
+	}
+
+	sort.Slice(
+		conflicts, func(i, j int) bool {
+			return conflicts[i].Copy < conflicts[j].Copy
+		},
+	)
+	return conflicts, err
+}
+
+// needPageSize files requested per page of rest/db/need
+const needPageSize = 1000
+
+// Need returns files of the remote which are different from local
+func (p *SyncthingHttpClient) Need() ([]string, error) {
+	names := make([]string, 0)
+	for page := 1; ; page++ {
+		resp, err := p.get(fmt.Sprintf("rest/db/need?page=%d&perpage=%d&folder=%s", page, needPageSize, p.folderName))
+		if err != nil {
+			return names, err
+		}
+
+		var res struct {
+			Progress []needFile `json:"progress"`
+			Queued   []needFile `json:"queued"`
+			Rest     []needFile `json:"rest"`
+		}
+		if err = json.Unmarshal(resp, &res); err != nil {
+			return names, err
+		}
+		count := 0
+		for _, files := range [][]needFile{res.Progress, res.Queued, res.Rest} {
+			for _, f := range files {
+				names = append(names, f.Name)
+			}
+			count += len(files)
+		}
+		if count < needPageSize {
+			return names, nil
+		}
+	}
+}
+
+type needFile struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,95 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package req
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRemoteDevice = "MDPJNTF-OSPJC65-LZNCQGD-3AWRUW6-BYJULSS-GOCA2TU-5DWWBNC-TKM4VQ5"
+
+func TestConflicts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, f := range []string{
+		"src/main.go",
+		"src/main.sync-conflict-20210801-101010-MDPJNTF.go",
+		"README.sync-conflict-20210801-111111-SJTYMUE",
+		"src/util.go",
+		".git/a.sync-conflict-20210801-101010-MDPJNTF.go",
+		"node_modules/b.sync-conflict-20210801-101010-MDPJNTF.js",
+	} {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755)
+		if err = ioutil.WriteFile(filepath.Join(dir, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasPrefix(r.URL.Path, "/rest/db/need") {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if r.URL.Query().Get("page") != "1" {
+					_, _ = w.Write([]byte(`{"progress":[],"queued":[],"rest":[]}`))
+					return
+				}
+				_, _ = w.Write(
+					[]byte(`{"progress":[],"queued":[{"name":"src/util.go"}],` +
+						`"rest":[{"name":"src/util.sync-conflict-20210801-121212-MDPJNTF.go"}],"page":1,"perpage":1000}`),
+				)
+			},
+		),
+	)
+	defer server.Close()
+
+	client := NewSyncthingHttpClient(strings.TrimPrefix(server.URL, "http://"), "", testRemoteDevice, "nh-1", 2)
+	conflicts, err := client.Conflicts(
+		dir, func(rel string) bool {
+			return filepath.ToSlash(rel) == "node_modules"
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SyncConflict{
+		{
+			File: "README", Copy: "README.sync-conflict-20210801-111111-SJTYMUE",
+			ModifiedBy: ConflictModifiedByLocal, Time: "20210801-111111", LocalDir: dir,
+		},
+		{
+			File: "src/main.go", Copy: "src/main.sync-conflict-20210801-101010-MDPJNTF.go",
+			ModifiedBy: ConflictModifiedByRemote, Time: "20210801-101010", LocalDir: dir,
+		},
+		{
+			File: "src/util.go", Copy: "src/util.sync-conflict-20210801-121212-MDPJNTF.go",
+			ModifiedBy: ConflictModifiedByRemote, Time: "20210801-121212",
+		},
+	}
+	if len(conflicts) != len(expected) {
+		t.Fatalf("unexpected conflicts %d", len(conflicts))
+	}
+	for i := range expected {
+		if *conflicts[i] != expected[i] {
+			t.Errorf("unexpected conflict %+v", conflicts[i])
+		}
+	}
+
+	if _, _, _, ok := ParseConflictCopy("src/main.go"); ok {
+		t.Error("main.go is not a conflict copy")
+	}
+}
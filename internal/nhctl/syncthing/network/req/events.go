@@ -32,7 +32,13 @@ type event struct {
 }
 
 type data struct {
-	Completion float64 `json:"completion"`
-	Device     string  `json:"device"`
-	Folder     string  `json:"folder"`
+	Completion float64       `json:"completion"`
+	Device     string        `json:"device"`
+	Folder     string        `json:"folder"`
+	Errors     []folderError `json:"errors"`
+}
+
+type folderError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
 }
@@ -0,0 +1,20 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package req
+
+import (
+	"encoding/json"
+)
+
+// SetIgnores replaces ignore patterns of the folder, files are rescanned by syncthing after it
+func (p *SyncthingHttpClient) SetIgnores(ignores []string) error {
+	body, err := json.Marshal(map[string][]string{"ignore": ignores})
+	if err != nil {
+		return err
+	}
+	_, err = p.Post("rest/db/ignores?folder="+p.folderName, string(body))
+	return err
+}
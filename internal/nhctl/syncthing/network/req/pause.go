@@ -0,0 +1,20 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package req
+
+import (
+	"encoding/json"
+)
+
+// PauseFolder pauses or resumes the folder, files are neither scanned nor synced while it is paused
+func (p *SyncthingHttpClient) PauseFolder(paused bool) error {
+	body, err := json.Marshal(map[string]bool{"paused": paused})
+	if err != nil {
+		return err
+	}
+	_, err = p.Patch("rest/config/folders/"+p.folderName, string(body))
+	return err
+}
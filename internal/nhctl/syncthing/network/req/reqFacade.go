@@ -7,6 +7,7 @@ package req
 
 import (
 	"fmt"
+	"strings"
 )
 
 func (p *SyncthingHttpClient) GetSyncthingStatus() *SyncthingStatus {
@@ -23,6 +24,56 @@ func (p *SyncthingHttpClient) GetSyncthingStatus() *SyncthingStatus {
 	return status
 }
 
+// GetFoldersSyncthingStatus aggregates status of folders, the worst of them is reported,
+// and status of each folder is listed if there are more than one folder
+func (p *SyncthingHttpClient) GetFoldersSyncthingStatus(folders []string) *SyncthingStatus {
+	if len(folders) <= 1 {
+		return p.GetSyncthingStatus()
+	}
+	statuses := make(map[string]*SyncthingStatus, len(folders))
+	for _, folder := range folders {
+		statuses[folder] = p.ForFolder(folder).GetSyncthingStatus()
+	}
+	return aggregateSyncthingStatus(folders, statuses)
+}
+
+// the larger, the worse
+var statusSeverity = map[StatusEnum]int{
+	Idle:         0,
+	End:          0,
+	Scanning:     1,
+	Syncing:      2,
+	OutOfSync:    3,
+	Error:        4,
+	Disconnected: 5,
+}
+
+func aggregateSyncthingStatus(folders []string, statuses map[string]*SyncthingStatus) *SyncthingStatus {
+	var worst *SyncthingStatus
+	outOfSync := make([]string, 0)
+	folderStatuses := make([]*FolderSyncStatus, 0, len(folders))
+	for _, folder := range folders {
+		status := statuses[folder]
+		if worst == nil || statusSeverity[status.Status] > statusSeverity[worst.Status] {
+			worst = status
+		}
+		if status.OutOfSync != "" {
+			outOfSync = append(outOfSync, strings.TrimPrefix(status.OutOfSync, Identifier))
+		}
+		folderStatuses = append(
+			folderStatuses, &FolderSyncStatus{Folder: folder, Status: status.Status, Msg: status.Msg},
+		)
+	}
+
+	aggregated := *worst
+	aggregated.OutOfSync = ""
+	if len(outOfSync) > 0 {
+		aggregated.OutOfSync = Identifier + strings.Join(outOfSync, "\n")
+	}
+	aggregated.Folders = folderStatuses
+	return &aggregated
+}
+
 func (p *SyncthingHttpClient) getSyncthingStatus() *SyncthingStatus {
 
 	// The SyncthingStatus is consist of three parts
@@ -114,6 +165,20 @@ type SyncthingStatus struct {
 	Tips      string     `json:"tips,omitempty"`
 	OutOfSync string     `json:"outOfSync,omitempty"`
 	Gui       string     `json:"gui,omitempty"`
+
+	// Conflicts copies left by syncthing, files modified on both sides are kept in them
+	Conflicts []*SyncConflict `yaml:"conflicts,omitempty" json:"conflicts,omitempty"`
+	// Folders status of each folder while more than one folder is synced
+	Folders []*FolderSyncStatus `yaml:"folders,omitempty" json:"folders,omitempty"`
+	// Stats statistics of the current session, listed with --stats
+	Stats *SyncStats `yaml:"stats,omitempty" json:"stats,omitempty"`
+}
+
+// FolderSyncStatus status of one of folders synced
+type FolderSyncStatus struct {
+	Folder string     `yaml:"folder" json:"folder"`
+	Status StatusEnum `yaml:"status" json:"status"`
+	Msg    string     `yaml:"msg" json:"msg"`
 }
 
 type StatusEnum string
@@ -128,6 +193,7 @@ const (
 	Error        StatusEnum = "error"
 	Idle         StatusEnum = "idle"
 	End          StatusEnum = "end"
+	Paused       StatusEnum = "paused"
 
 	Identifier = "(Nocalhost): "
 )
@@ -163,6 +229,12 @@ var NotProcessor = &SyncthingStatus{
 		"you should end the dev mode and re enter again.",
 }
 
+var SyncPaused = &SyncthingStatus{
+	Status: Paused,
+	Msg:    "File sync is paused",
+	Tips:   Identifier + "Files are not synced until file sync is resumed by `nhctl sync resume`.",
+}
+
 var NotSyncthingProcessFound = &SyncthingStatus{
 	Status: Disconnected,
 	Msg:    "No syncthing process found",
@@ -0,0 +1,29 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package req
+
+import (
+	"testing"
+)
+
+func TestAggregateSyncthingStatus(t *testing.T) {
+	status := aggregateSyncthingStatus(
+		[]string{"nh-1", "nh-2", "nh-3"}, map[string]*SyncthingStatus{
+			"nh-1": {Status: Idle, Msg: "idle", OutOfSync: Identifier + "a"},
+			"nh-2": {Status: Syncing, Msg: "50%", Tips: "syncing"},
+			"nh-3": {Status: Scanning, Msg: "Scanning local changed...", OutOfSync: Identifier + "b"},
+		},
+	)
+	if status.Status != Syncing || status.Msg != "50%" || status.Tips != "syncing" {
+		t.Errorf("the worst status should be reported, but %+v", status)
+	}
+	if status.OutOfSync != Identifier+"a\nb" {
+		t.Errorf("unexpected out of sync %s", status.OutOfSync)
+	}
+	if len(status.Folders) != 3 || status.Folders[2].Folder != "nh-3" || status.Folders[2].Status != Scanning {
+		t.Errorf("unexpected folders %+v", status.Folders)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package req
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyncStats statistics of a file sync session, a session starts while local syncthing starts
+type SyncStats struct {
+	SessionStart  string `yaml:"sessionStart" json:"sessionStart"`
+	BytesSent     int64  `yaml:"bytesSent" json:"bytesSent"`
+	BytesReceived int64  `yaml:"bytesReceived" json:"bytesReceived"`
+	FilesChanged  int64  `yaml:"filesChanged" json:"filesChanged"`
+	// LastSyncLatencyMs from the first local change detected to the remote completed syncing it
+	LastSyncLatencyMs int64  `yaml:"lastSyncLatencyMs" json:"lastSyncLatencyMs"`
+	LastSyncedAt      string `yaml:"lastSyncedAt,omitempty" json:"lastSyncedAt,omitempty"`
+	Errors            int64  `yaml:"errors" json:"errors"`
+	LastError         string `yaml:"lastError,omitempty" json:"lastError,omitempty"`
+
+	// LastEventID events before it are counted
+	LastEventID int64 `yaml:"lastEventId" json:"lastEventId"`
+	// PendingSince time of the first local change not synced yet
+	PendingSince time.Time `yaml:"pendingSince" json:"pendingSince"`
+}
+
+const (
+	EventLocalChangeDetected  EventType = "LocalChangeDetected"
+	EventRemoteChangeDetected EventType = "RemoteChangeDetected"
+	EventFolderErrors         EventType = "FolderErrors"
+)
+
+var statsEvents = []EventType{
+	EventLocalChangeDetected, EventRemoteChangeDetected, EventFolderCompletion, EventFolderErrors,
+}
+
+// CollectStats updates stats with transfer totals and events since the last collection,
+// stats are reset while syncthing is restarted
+func (p *SyncthingHttpClient) CollectStats(stats *SyncStats) error {
+	startTime, err := p.startTime()
+	if err != nil {
+		return err
+	}
+	if startTime != stats.SessionStart {
+		*stats = SyncStats{SessionStart: startTime}
+	}
+
+	resp, err := p.get("rest/system/connections")
+	if err != nil {
+		return err
+	}
+	var connections ConnectionStatsResponse
+	if err = json.Unmarshal(resp, &connections); err != nil {
+		return err
+	}
+	if c, ok := connections.Connections[p.remoteDevice]; ok {
+		stats.BytesSent = c.OutBytesTotal
+		stats.BytesReceived = c.InBytesTotal
+	}
+
+	mask := make([]string, 0, len(statsEvents))
+	for _, e := range statsEvents {
+		mask = append(mask, string(e))
+	}
+	// timeout=0 returns immediately while there is no new event
+	resp, err = p.get(
+		fmt.Sprintf(
+			"rest/events?since=%s&events=%s&timeout=0",
+			strconv.FormatInt(stats.LastEventID, 10), strings.Join(mask, ","),
+		),
+	)
+	if err != nil {
+		return err
+	}
+	var events []event
+	if err = json.Unmarshal(resp, &events); err != nil {
+		return err
+	}
+	stats.apply(events, p.remoteDevice)
+	return nil
+}
+
+func (p *SyncthingHttpClient) startTime() (string, error) {
+	resp, err := p.get("rest/system/status")
+	if err != nil {
+		return "", err
+	}
+	var status struct {
+		StartTime string `json:"startTime"`
+	}
+	if err = json.Unmarshal(resp, &status); err != nil {
+		return "", err
+	}
+	return status.StartTime, nil
+}
+
+func (s *SyncStats) apply(events []event, remoteDevice string) {
+	for _, e := range events {
+		s.LastEventID = e.Id
+		t, _ := time.Parse(time.RFC3339Nano, e.Time)
+		switch e.EventType {
+		case EventLocalChangeDetected:
+			s.FilesChanged++
+			if s.PendingSince.IsZero() {
+				s.PendingSince = t
+			}
+		case EventRemoteChangeDetected:
+			s.FilesChanged++
+		case EventFolderCompletion:
+			// completion of the remote is reported several times after each change
+			if e.Data.Device != remoteDevice || e.Data.Completion < 100 || s.PendingSince.IsZero() {
+				continue
+			}
+			s.LastSyncLatencyMs = t.Sub(s.PendingSince).Milliseconds()
+			s.LastSyncedAt = t.Format("2006-01-02 15:04:05")
+			s.PendingSince = time.Time{}
+		case EventFolderErrors:
+			s.Errors += int64(len(e.Data.Errors))
+			if len(e.Data.Errors) > 0 {
+				last := e.Data.Errors[len(e.Data.Errors)-1]
+				s.LastError = fmt.Sprintf("%s: %s", last.Path, last.Error)
+			}
+		}
+	}
+}
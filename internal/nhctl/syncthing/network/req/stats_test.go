@@ -0,0 +1,74 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package req
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCollectStats(t *testing.T) {
+	startTime := "2021-09-01T10:00:00Z"
+	events := `[
+		{"id": 1, "type": "LocalChangeDetected", "time": "2021-09-01T10:01:00Z", "data": {"path": "a.go"}},
+		{"id": 2, "type": "LocalChangeDetected", "time": "2021-09-01T10:01:01Z", "data": {"path": "b.go"}},
+		{"id": 3, "type": "FolderCompletion", "time": "2021-09-01T10:01:02.5Z",
+			"data": {"device": "remote", "completion": 100}},
+		{"id": 4, "type": "FolderErrors", "time": "2021-09-01T10:02:00Z",
+			"data": {"errors": [{"path": "c.go", "error": "permission denied"}]}}
+	]`
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/rest/system/status":
+					_, _ = fmt.Fprintf(w, `{"startTime": "%s"}`, startTime)
+				case "/rest/system/connections":
+					_, _ = fmt.Fprint(w, `{"connections": {"remote": {"inBytesTotal": 10, "outBytesTotal": 2048}}}`)
+				case "/rest/events":
+					if r.URL.Query().Get("since") != "0" {
+						_, _ = fmt.Fprint(w, `[]`)
+						return
+					}
+					_, _ = fmt.Fprint(w, events)
+				}
+			},
+		),
+	)
+	defer server.Close()
+
+	client := NewSyncthingHttpClient(strings.TrimPrefix(server.URL, "http://"), "", "remote", "nh-1", 2)
+	stats := &SyncStats{}
+	if err := client.CollectStats(stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.SessionStart != startTime || stats.BytesSent != 2048 || stats.BytesReceived != 10 ||
+		stats.FilesChanged != 2 || stats.LastSyncLatencyMs != 2500 || !stats.PendingSince.IsZero() ||
+		stats.Errors != 1 || stats.LastError != "c.go: permission denied" || stats.LastEventID != 4 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// events collected are not counted again
+	if err := client.CollectStats(stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.FilesChanged != 2 || stats.Errors != 1 {
+		t.Errorf("events should not be counted twice %+v", stats)
+	}
+
+	// a new session starts while syncthing is restarted
+	startTime = "2021-09-01T11:00:00Z"
+	events = `[]`
+	if err := client.CollectStats(stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.SessionStart != startTime || stats.FilesChanged != 0 || stats.Errors != 0 || stats.LastEventID != 0 {
+		t.Errorf("stats should be reset for a new session %+v", stats)
+	}
+}
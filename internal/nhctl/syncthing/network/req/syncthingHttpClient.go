@@ -37,6 +37,13 @@ func NewSyncthingHttpClient(
 	}
 }
 
+// ForFolder returns a client requesting folder instead
+func (s *SyncthingHttpClient) ForFolder(folder string) *SyncthingHttpClient {
+	client := *s
+	client.folderName = folder
+	return &client
+}
+
 // Get performs an HTTP GET and returns the bytes and/or an error. Any non-200
 // return code is returned as an error.
 func (s *SyncthingHttpClient) get(path string) ([]byte, error) {
@@ -55,6 +62,14 @@ func (s *SyncthingHttpClient) Post(path, body string) ([]byte, error) {
 	return s.do(req, s.reqTimeoutSecond)
 }
 
+func (s *SyncthingHttpClient) Patch(path, body string) ([]byte, error) {
+	req, err := http.NewRequest("PATCH", fmt.Sprintf("http://%s/%s", s.guiHost, path), bytes.NewBufferString(body))
+	if err != nil {
+		return nil, err
+	}
+	return s.do(req, s.reqTimeoutSecond)
+}
+
 func (s *SyncthingHttpClient) do(req *http.Request, reqTimeoutSecond int) ([]byte, error) {
 	req.Header.Add("X-API-Key", s.apiKey)
 
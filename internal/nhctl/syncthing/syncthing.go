@@ -76,6 +76,16 @@ type Folder struct {
 	RemotePath   string `yaml:"remotePath"`
 	Retries      int    `yaml:"-"`
 	SentStIgnore bool   `yaml:"-"`
+
+	// Type of the local folder, Type of Syncthing is used if it is empty
+	Type string `yaml:"-"`
+	// Ignores of the folder set by api, .nhignore is used if it is nil
+	Ignores []string `yaml:"-"`
+}
+
+// ID returns id of the folder in syncthing
+func (f *Folder) ID() string {
+	return "nh-" + f.Name
 }
 
 //Ignores represents the .stignore file
@@ -118,6 +128,12 @@ type Syncthing struct {
 
 	// resolve ignore/sync from gitignore
 	EnableParseFromGitIgnore bool `yaml:"-"`
+
+	// translate .gitignore files of the first folder to ignored patterns
+	TranslateGitIgnore bool `yaml:"-"`
+
+	// folders are paused while syncthing starts
+	Paused bool `yaml:"-"`
 }
 
 //IsSubPathFolder checks if a sync folder is a subpath of another sync folder
@@ -206,34 +222,12 @@ func (s *Syncthing) generateIgnoredFileConfig() (string, error) {
 	var enableParseFromGitIgnore = DisableParseFromGitIgnore
 
 	for i, synced := range s.SyncedPattern {
-		var afterAdapt = synced
-
-		// previews version support such this syntax
-		if synced == "." {
-			afterAdapt = "**"
-		}
-
-		if strings.Index(synced, "./") == 0 {
-			afterAdapt = synced[1:]
-		}
-
-		syncedPatternAdaption[i] = "!" + afterAdapt
+		syncedPatternAdaption[i] = "!" + adaptPattern(synced)
 	}
 
 	var ignoredPatternAdaption = make([]string, len(s.IgnoredPattern))
 	for i, ignored := range s.IgnoredPattern {
-		var afterAdapt = ignored
-
-		// previews version support such this syntax
-		if ignored == "." {
-			afterAdapt = "**"
-		}
-
-		if strings.Index(ignored, "./") == 0 {
-			afterAdapt = ignored[1:]
-		}
-
-		ignoredPatternAdaption[i] = afterAdapt
+		ignoredPatternAdaption[i] = adaptPattern(ignored)
 	}
 
 	if len(syncedPatternAdaption) == 0 {
@@ -242,6 +236,7 @@ func (s *Syncthing) generateIgnoredFileConfig() (string, error) {
 
 	ignoredPattern := ""
 	syncedPattern := ""
+	gitIgnoredPattern := ""
 
 	if s.EnableParseFromGitIgnore {
 		log.Infof("\n Enable parsing file's ignore/sync from git ignore\n")
@@ -252,12 +247,18 @@ func (s *Syncthing) generateIgnoredFileConfig() (string, error) {
 
 		log.Infof("IgnoredPattern: \n" + ignoredPattern)
 		log.Infof("SyncedPattern: \n" + syncedPattern)
+
+		if s.TranslateGitIgnore && len(s.Folders) > 0 {
+			gitIgnoredPattern = strings.Join(GitIgnoreRules(s.Folders[0].LocalPath), "\n")
+			log.Infof("GitIgnoredPattern: \n" + gitIgnoredPattern)
+		}
 	}
 
 	var values = map[string]string{
 		"enableParseFromGitIgnore": enableParseFromGitIgnore,
 		"ignoredPattern":           ignoredPattern,
 		"syncedPattern":            syncedPattern,
+		"gitIgnoredPattern":        gitIgnoredPattern,
 	}
 
 	buf := new(bytes.Buffer)
@@ -272,6 +273,41 @@ func (s *Syncthing) generateIgnoredFileConfig() (string, error) {
 	return ignoreFilePath, nil
 }
 
+// adaptPattern translates FilePattern and IgnoreFilePattern to patterns of syncthing
+func adaptPattern(pattern string) string {
+	// previews version support such this syntax
+	if pattern == "." {
+		return "**"
+	}
+	if strings.Index(pattern, "./") == 0 {
+		return pattern[1:]
+	}
+	return pattern
+}
+
+// FolderIgnores returns ignores of a folder in the same order of .nhignore,
+// ignored patterns win, and files not synced are ignored at last
+func FolderIgnores(syncedPattern, ignoredPattern []string) []string {
+	ignores := make([]string, 0, len(syncedPattern)+len(ignoredPattern)+2)
+	for _, ignored := range ignoredPattern {
+		ignores = append(ignores, adaptPattern(ignored))
+	}
+	if len(syncedPattern) == 0 {
+		ignores = append(ignores, "!**")
+	}
+	for _, synced := range syncedPattern {
+		ignores = append(ignores, "!"+adaptPattern(synced))
+	}
+	return append(ignores, "**")
+}
+
+// ReloadIgnoredFileConfig regenerates .nhignore for the running syncthing,
+// it takes effect at the next scan
+func (s *Syncthing) ReloadIgnoredFileConfig() error {
+	_, err := s.generateIgnoredFileConfig()
+	return err
+}
+
 // Run local syncthing server
 func (s *Syncthing) Run(ctx context.Context) error {
 	if err := s.initConfig(); err != nil {
@@ -0,0 +1,47 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package syncthing
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFolderIgnores(t *testing.T) {
+	ignores := FolderIgnores([]string{".", "./src"}, []string{"./build", "*.log"})
+	expected := []string{"/build", "*.log", "!**", "!/src", "**"}
+	if !reflect.DeepEqual(ignores, expected) {
+		t.Errorf("unexpected ignores %v", ignores)
+	}
+
+	if ignores = FolderIgnores(nil, nil); !reflect.DeepEqual(ignores, []string{"!**", "**"}) {
+		t.Errorf("unexpected ignores %v", ignores)
+	}
+}
+
+func TestLocalConfigXMLFolderType(t *testing.T) {
+	s := &Syncthing{
+		Type: "sendonly",
+		Folders: []*Folder{
+			{Name: "1", LocalPath: "/src/api", RemotePath: "/app"},
+			{Name: "2", LocalPath: "/src/libs", RemotePath: "/opt/libs", Type: "sendReceive"},
+		},
+	}
+	bys, err := s.GetLocalConfigXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := string(bys)
+	for _, expected := range []string{
+		`<folder id="nh-1" label="1" path="/src/api" type="sendonly"`,
+		`<folder id="nh-2" label="2" path="/src/libs" type="sendReceive"`,
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("%s is not found in config", expected)
+		}
+	}
+}
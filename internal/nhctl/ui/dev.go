@@ -255,7 +255,7 @@ func (t *TviewApplication) buildWorkloadList(appMeta *appmeta.ApplicationMeta, n
 					writer := t.switchBodyToScrollingView(" End DevMode", workloadListTable)
 					RedirectionOut(writer)
 					go func() {
-						dev.EndDevMode(nocalhostSvc)
+						utils.Should(dev.EndDevMode(nocalhostSvc))
 						RecoverOut()
 					}()
 				case portForwardOpt:
@@ -0,0 +1,157 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContextLines = 3
+
+type diffLine struct {
+	tag  byte // ' ', '-' or '+'
+	text string
+}
+
+// UnifiedDiff returns the line based difference between from and to in unified format,
+// empty string is returned if there is no difference
+func UnifiedDiff(fromName, toName, from, to string) string {
+	lines := diffLines(splitLines(from), splitLines(to))
+
+	changed := make([]int, 0)
+	for i, l := range lines {
+		if l.tag != ' ' {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	for i := 0; i < len(changed); {
+		start := changed[i] - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := changed[i] + diffContextLines + 1
+		// merge changes whose context overlaps into one hunk
+		for i++; i < len(changed) && changed[i]-diffContextLines <= end; i++ {
+			end = changed[i] + diffContextLines + 1
+		}
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		// line numbers of the hunk in from and to
+		fromStart, toStart := 1, 1
+		for _, l := range lines[:start] {
+			if l.tag != '+' {
+				fromStart++
+			}
+			if l.tag != '-' {
+				toStart++
+			}
+		}
+		fromCount, toCount := 0, 0
+		for _, l := range lines[start:end] {
+			if l.tag != '+' {
+				fromCount++
+			}
+			if l.tag != '-' {
+				toCount++
+			}
+		}
+		// an empty range starts at the line before it
+		if fromCount == 0 {
+			fromStart--
+		}
+		if toCount == 0 {
+			toStart--
+		}
+
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", fromStart, fromCount, toStart, toCount))
+		for _, l := range lines[start:end] {
+			sb.WriteByte(l.tag)
+			sb.WriteString(l.text)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes the edit script from a to b by longest common subsequence,
+// common prefix and suffix are trimmed first as manifests usually differ in a small part
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]diffLine, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		result = append(result, diffLine{tag: ' ', text: l})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	// lcs[i][j] is the length of longest common subsequence of ma[i:] and mb[j:]
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(ma) && j < len(mb) {
+		switch {
+		case ma[i] == mb[j]:
+			result = append(result, diffLine{tag: ' ', text: ma[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, diffLine{tag: '-', text: ma[i]})
+			i++
+		default:
+			result = append(result, diffLine{tag: '+', text: mb[j]})
+			j++
+		}
+	}
+	for ; i < len(ma); i++ {
+		result = append(result, diffLine{tag: '-', text: ma[i]})
+	}
+	for ; j < len(mb); j++ {
+		result = append(result, diffLine{tag: '+', text: mb[j]})
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		result = append(result, diffLine{tag: ' ', text: l})
+	}
+	return result
+}
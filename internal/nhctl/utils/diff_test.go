@@ -0,0 +1,49 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	if d := UnifiedDiff("a", "b", "x\ny\n", "x\ny\n"); d != "" {
+		t.Fatalf("expect no difference, but got:\n%s", d)
+	}
+
+	from := strings.Join([]string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"}, "\n")
+	to := strings.Join([]string{"1", "2", "three", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13"}, "\n")
+	expect := `--- live
++++ dev
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if d := UnifiedDiff("live", "dev", from, to); d != expect {
+		t.Fatalf("expect:\n%s\nbut got:\n%s", expect, d)
+	}
+
+	expect = `--- /dev/null
++++ pvc
+@@ -0,0 +1,2 @@
++kind: PersistentVolumeClaim
++name: data
+`
+	if d := UnifiedDiff("/dev/null", "pvc", "", "kind: PersistentVolumeClaim\nname: data\n"); d != expect {
+		t.Fatalf("expect:\n%s\nbut got:\n%s", expect, d)
+	}
+}
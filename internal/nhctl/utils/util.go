@@ -281,6 +281,50 @@ func GetPortForwardForString(portStr string) (int, int, error) {
 	}
 }
 
+// IsServiceTarget returns if portStr forwards to a service, such as 8080:svc/reviews:http
+func IsServiceTarget(portStr string) bool {
+	s := strings.SplitN(portStr, ":", 2)
+	return len(s) == 2 && (strings.HasPrefix(s[1], "svc/") || strings.HasPrefix(s[1], "service/"))
+}
+
+// GetServicePortForwardForString parses port-forward to a service, such as 8080:svc/reviews:http,
+// the local port is random if it is empty
+func GetServicePortForwardForString(portStr string) (int, string, error) {
+	if !IsServiceTarget(portStr) {
+		return 0, "", errors.New(fmt.Sprintf("Wrong format of service port-forward: %s.", portStr))
+	}
+	s := strings.SplitN(portStr, ":", 2)
+	if _, _, err := ParseServiceTarget(s[1]); err != nil {
+		return 0, "", err
+	}
+	if s[0] == "" {
+		localPort, err := ports.GetAvailablePort()
+		return localPort, s[1], err
+	}
+	localPort, err := strconv.Atoi(s[0])
+	if err != nil {
+		return 0, "", errors.Wrap(err, fmt.Sprintf("Wrong format of local port: %s.", s[0]))
+	}
+	if localPort > 65535 || localPort < 0 {
+		return 0, "", errors.New(
+			fmt.Sprintf("The range of TCP port number is [0, 65535], wrong defined of local port: %s.", portStr),
+		)
+	}
+	return localPort, s[1], nil
+}
+
+// ParseServiceTarget returns name and port of target svc/<name>:<port>, port is a number or name of the service port
+func ParseServiceTarget(target string) (string, string, error) {
+	if !strings.HasPrefix(target, "svc/") && !strings.HasPrefix(target, "service/") {
+		return "", "", errors.New(fmt.Sprintf("Wrong format of service target: %s, it should be svc/<name>:<port>", target))
+	}
+	s := strings.SplitN(target[strings.Index(target, "/")+1:], ":", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return "", "", errors.New(fmt.Sprintf("Wrong format of service target: %s, it should be svc/<name>:<port>", target))
+	}
+	return s[0], s[1], nil
+}
+
 func RecoverFromPanic() {
 	if r := recover(); r != nil {
 		log.Errorf("DAEMON-RECOVER: %s", string(debug.Stack()))
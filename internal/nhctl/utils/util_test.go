@@ -0,0 +1,28 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package utils
+
+import "testing"
+
+func TestGetServicePortForwardForString(t *testing.T) {
+	localPort, target, err := GetServicePortForwardForString("9080:svc/reviews:http")
+	if err != nil || localPort != 9080 || target != "svc/reviews:http" {
+		t.Errorf("unexpected %d %s %v", localPort, target, err)
+	}
+	if localPort, _, err = GetServicePortForwardForString(":service/reviews:80"); err != nil || localPort == 0 {
+		t.Errorf("a random local port should be used, but got %d %v", localPort, err)
+	}
+	for _, s := range []string{"9080:8080", "9080:svc/reviews", "9080:svc/:80", "x:svc/reviews:80"} {
+		if _, _, err = GetServicePortForwardForString(s); err == nil {
+			t.Errorf("%s should be invalid", s)
+		}
+	}
+
+	name, port, err := ParseServiceTarget("svc/reviews:http")
+	if err != nil || name != "reviews" || port != "http" {
+		t.Errorf("unexpected %s %s %v", name, port, err)
+	}
+}
@@ -5,7 +5,10 @@
 
 package util
 
-import "net"
+import (
+	"net"
+	"nocalhost/internal/nhctl/daemon_common"
+)
 
 const (
 	TrafficManager string = "kubevpn.traffic.manager"
@@ -15,8 +18,8 @@ const (
 	MacToIP        string = "MAC_TO_IP"
 	DHCP           string = "DHCP"
 	Splitter       string = "#"
-	EndSignOK      string = "EndSignOk"
-	EndSignFailed  string = "EndSignFailed"
+	EndSignOK      string = daemon_common.EndSignOK
+	EndSignFailed  string = daemon_common.EndSignFailed
 )
 
 var IpRange net.IP
@@ -0,0 +1,151 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package watcher
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"nocalhost/pkg/nhctl/log"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileWatcher notifies changes of files, dirs of the files are watched instead of
+// the files, because editors usually save files by renaming a temp file to them.
+// Changes in delay are merged into one notification
+type FileWatcher struct {
+	watcher  *fsnotify.Watcher
+	lock     sync.Mutex
+	files    map[string]bool
+	dirs     map[string]int
+	timers   map[string]*time.Timer
+	delay    time.Duration
+	onChange func(file string)
+}
+
+func NewFileWatcher(delay time.Duration, onChange func(file string)) (*FileWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	return &FileWatcher{
+		watcher:  w,
+		files:    map[string]bool{},
+		dirs:     map[string]int{},
+		timers:   map[string]*time.Timer{},
+		delay:    delay,
+		onChange: onChange,
+	}, nil
+}
+
+// Add watches file, the file may not exist yet
+func (f *FileWatcher) Add(file string) error {
+	file = filepath.Clean(file)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.files[file] {
+		return nil
+	}
+	dir := filepath.Dir(file)
+	if f.dirs[dir] == 0 {
+		if err := f.watcher.Add(dir); err != nil {
+			return errors.Wrap(err, "")
+		}
+	}
+	f.dirs[dir]++
+	f.files[file] = true
+	return nil
+}
+
+func (f *FileWatcher) Remove(file string) {
+	file = filepath.Clean(file)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.files[file] {
+		return
+	}
+	delete(f.files, file)
+	if timer, ok := f.timers[file]; ok {
+		timer.Stop()
+		delete(f.timers, file)
+	}
+	dir := filepath.Dir(file)
+	if f.dirs[dir]--; f.dirs[dir] <= 0 {
+		delete(f.dirs, dir)
+		_ = f.watcher.Remove(dir)
+	}
+}
+
+// Files returns files watched in order
+func (f *FileWatcher) Files() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	result := make([]string, 0, len(f.files))
+	for file := range f.files {
+		result = append(result, file)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Run blocks until stopCh is closed
+func (f *FileWatcher) Run(stopCh <-chan struct{}) {
+	defer f.watcher.Close()
+	for {
+		select {
+		case <-stopCh:
+			f.lock.Lock()
+			for _, timer := range f.timers {
+				timer.Stop()
+			}
+			f.lock.Unlock()
+			return
+		case event, ok := <-f.watcher.Events:
+			if !ok {
+				return
+			}
+			// chmod only is ignored
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			f.notify(filepath.Clean(event.Name))
+		case err, ok := <-f.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.WarnE(errors.Wrap(err, ""), "Error occurs while watching files")
+		}
+	}
+}
+
+func (f *FileWatcher) notify(file string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.files[file] {
+		return
+	}
+	if timer, ok := f.timers[file]; ok {
+		timer.Reset(f.delay)
+		return
+	}
+	f.timers[file] = time.AfterFunc(
+		f.delay, func() {
+			f.lock.Lock()
+			delete(f.timers, file)
+			watched := f.files[file]
+			f.lock.Unlock()
+			if watched {
+				f.onChange(file)
+			}
+		},
+	)
+}
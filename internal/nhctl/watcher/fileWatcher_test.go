@@ -0,0 +1,65 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package watcher
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	changed := make(chan string, 10)
+	w, err := NewFileWatcher(100*time.Millisecond, func(file string) { changed <- file })
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "config.yaml")
+	if err = w.Add(config); err != nil {
+		t.Fatal(err)
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go w.Run(stopCh)
+
+	// other files in the dir are ignored and writes in a short time are merged
+	_ = ioutil.WriteFile(filepath.Join(dir, "other.yaml"), []byte("a"), 0644)
+	for i := 0; i < 3; i++ {
+		_ = ioutil.WriteFile(config, []byte("name: reviews"), 0644)
+	}
+	select {
+	case file := <-changed:
+		if file != config {
+			t.Errorf("unexpected file changed %s", file)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("change of config is not notified")
+	}
+	select {
+	case file := <-changed:
+		t.Errorf("changes should be merged, got %s", file)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	w.Remove(config)
+	if len(w.Files()) != 0 {
+		t.Errorf("unexpected files %v", w.Files())
+	}
+	_ = ioutil.WriteFile(config, []byte("name: details"), 0644)
+	select {
+	case file := <-changed:
+		t.Errorf("removed file should not be notified, got %s", file)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
@@ -37,6 +37,7 @@ type ClusterUserV2 struct {
 	BaseDevSpaceId     uint64    `gorm:"column:base_dev_space_id;default:0" json:"base_dev_space_id"`
 	TraceHeader        Header    `gorm:"cloumn:trace_header;type:VARCHAR(256);" json:"trace_header"`
 	SpaceResourceLimit string    `gorm:"column:space_resource_limit;type:VARCHAR(1024);" json:"space_resource_limit"`
+	DevModeTTL         string    `gorm:"column:dev_mode_ttl;type:VARCHAR(32);" json:"dev_mode_ttl"`
 	CreatedAt          time.Time `gorm:"column:created_at" json:"created_at"`
 
 	// ext field
@@ -84,6 +85,7 @@ type ClusterUserModel struct {
 	IsBaseSpace        bool       `gorm:"column:is_base_space;default:false" json:"is_base_space"`
 	BaseDevSpaceId     uint64     `gorm:"column:base_dev_space_id;default:0" json:"base_dev_space_id"`
 	TraceHeader        Header     `gorm:"cloumn:trace_header;type:VARCHAR(256);" json:"trace_header"`
+	DevModeTTL         string     `gorm:"column:dev_mode_ttl;type:VARCHAR(32);" json:"dev_mode_ttl"`
 	CreatedAt          time.Time  `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt          time.Time  `gorm:"column:updated_at" json:"-"`
 	DeletedAt          *time.Time `gorm:"column:deleted_at" json:"-"`
@@ -132,6 +132,7 @@ func (srv *ClusterUser) ListV2(models model.ClusterUserModel) (
 		item.BaseDevSpaceId = userModel.BaseDevSpaceId
 		item.IsBaseSpace = userModel.IsBaseSpace
 		item.TraceHeader = userModel.TraceHeader
+		item.DevModeTTL = userModel.DevModeTTL
 		result = append(result, item)
 	}
 	return result, nil
@@ -0,0 +1,199 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package devmode
+
+import (
+	"context"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"nocalhost/internal/nhctl/appmeta"
+	"nocalhost/internal/nhctl/common/base"
+	_const "nocalhost/internal/nhctl/const"
+	"nocalhost/internal/nhctl/controller"
+	"nocalhost/internal/nhctl/nocalhost"
+	"nocalhost/internal/nhctl/profile"
+	"nocalhost/pkg/nhctl/clientgoutils"
+	"time"
+)
+
+// built-in workloads which can enter replace DevMode, custom resources are listed by nocalhost.CrdSvcTypes
+var devModeWorkloads = map[base.SvcType]schema.GroupVersionResource{
+	base.Deployment:  {Group: "apps", Version: "v1", Resource: "deployments"},
+	base.StatefulSet: {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	base.DaemonSet:   {Group: "apps", Version: "v1", Resource: "daemonsets"},
+	base.Job:         {Group: "batch", Version: "v1", Resource: "jobs"},
+	base.CronJob:     {Group: "batch", Version: "v1beta1", Resource: "cronjobs"},
+	base.Pod:         {Version: "v1", Resource: "pods"},
+}
+
+// ExpiryReaper ends replace DevMode whose heartbeat is not received within the ttl,
+// so that workloads developers forget to end won't stay in DevMode forever
+type ExpiryReaper struct {
+	clientset      *kubernetes.Clientset
+	metadataClient metadata.Interface
+}
+
+func NewExpiryReaper(clientset *kubernetes.Clientset, metadataClient metadata.Interface) *ExpiryReaper {
+	return &ExpiryReaper{
+		clientset:      clientset,
+		metadataClient: metadataClient,
+	}
+}
+
+// Run checks DevMode every period, this method will block until stop is closed
+func (r *ExpiryReaper) Run(period time.Duration, stop <-chan struct{}) {
+	wait.Until(r.reap, period, stop)
+}
+
+func (r *ExpiryReaper) reap() {
+	// ttl of dev space is read once per round
+	defaultTTLs := map[string]time.Duration{}
+
+	workloads := nocalhost.CrdSvcTypes()
+	for svcType, gvr := range devModeWorkloads {
+		workloads[svcType] = gvr
+	}
+
+	for svcType, gvr := range workloads {
+		list, err := r.metadataClient.Resource(gvr).Namespace(metav1.NamespaceAll).List(
+			context.TODO(), metav1.ListOptions{},
+		)
+		if err != nil {
+			// custom resources may be not installed in the cluster
+			if !k8serrors.IsNotFound(err) {
+				glog.Errorf("Failed to list %s: %v", gvr.String(), err)
+			}
+			continue
+		}
+
+		for _, item := range list.Items {
+			annotations := item.GetAnnotations()
+			if _, ok := annotations[_const.DevModeTTLAnnotation]; !ok {
+				continue
+			}
+
+			defaultTTL, ok := defaultTTLs[item.Namespace]
+			if !ok {
+				defaultTTL = r.defaultTTL(item.Namespace)
+				defaultTTLs[item.Namespace] = defaultTTL
+			}
+
+			expiry, ok, err := controller.DevModeExpiry(annotations, defaultTTL)
+			if err != nil {
+				glog.Errorf("Invalid DevMode of %s %s/%s: %v", svcType, item.Namespace, item.Name, err)
+				continue
+			}
+			if !ok || time.Now().Before(expiry) {
+				continue
+			}
+
+			meta, identifier, err := r.devModeOf(item.Namespace, item.Name, svcType, annotations)
+			if err != nil {
+				glog.Errorf("Failed to get DevMode of %s %s/%s: %v", svcType, item.Namespace, item.Name, err)
+				continue
+			}
+			if identifier == "" {
+				// DevMode has been ended, but ttl is left in the workload by earlier nhctl
+				glog.Infof("%s %s/%s is not in replace DevMode, removing its ttl", svcType, item.Namespace, item.Name)
+				if _, err = r.metadataClient.Resource(gvr).Namespace(item.Namespace).Patch(
+					context.TODO(), item.Name, types.MergePatchType, []byte(controller.ClearDevModeTTLPatch()),
+					metav1.PatchOptions{},
+				); err != nil {
+					glog.Errorf("Failed to remove ttl of %s %s/%s: %v", svcType, item.Namespace, item.Name, err)
+				}
+				continue
+			}
+
+			glog.Infof("DevMode of %s %s/%s expired at %s, ending it", svcType, item.Namespace, item.Name, expiry)
+			if err = r.endDevMode(meta, item.Namespace, item.Name, identifier, svcType); err != nil {
+				glog.Errorf("Failed to end DevMode of %s %s/%s: %v", svcType, item.Namespace, item.Name, err)
+			}
+		}
+	}
+}
+
+// defaultTTL returns ttl of the dev space, which is recorded in namespace by nocalhost-api
+func (r *ExpiryReaper) defaultTTL(namespace string) time.Duration {
+	ns, err := r.clientset.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+	if err != nil {
+		glog.Errorf("Failed to get namespace %s: %v", namespace, err)
+		return 0
+	}
+	value, ok := ns.Annotations[_const.DevModeTTLAnnotation]
+	if !ok || value == "" {
+		return 0
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		glog.Errorf("Invalid annotation %s of namespace %s: %v", _const.DevModeTTLAnnotation, namespace, err)
+		return 0
+	}
+	return ttl
+}
+
+// devModeOf returns application meta of the workload and identifier of the developer
+// whose replace DevMode it is in, identifier is empty if the workload is not in replace DevMode
+func (r *ExpiryReaper) devModeOf(namespace, name string, svcType base.SvcType, annotations map[string]string) (
+	*appmeta.ApplicationMeta, string, error) {
+	appName := annotations[_const.NocalhostApplicationName]
+	if appName == "" {
+		appName = _const.DefaultNocalhostApplication
+	}
+
+	secret, err := r.clientset.CoreV1().Secrets(namespace).Get(
+		context.TODO(), appmeta.SecretNamePrefix+appName, metav1.GetOptions{},
+	)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	meta, err := appmeta.Decode(secret)
+	if err != nil {
+		return nil, "", err
+	}
+	return meta, meta.GetApplicationDevMeta()[svcType.Alias()][name], nil
+}
+
+// endDevMode does what `nhctl dev end` does in cluster, syncthing and port-forward of the developer
+// are stopped by its daemon when the DevMode is ended in application meta. The secret of application meta
+// is updated directly, there is no daemon in cluster
+func (r *ExpiryReaper) endDevMode(meta *appmeta.ApplicationMeta, namespace, name, identifier string,
+	svcType base.SvcType) error {
+	if err := meta.InitGoClientInCluster(); err != nil {
+		return err
+	}
+
+	client, err := clientgoutils.NewClientGoUtilsInCluster(namespace)
+	if err != nil {
+		return err
+	}
+	c, err := controller.NewController(namespace, name, meta.Application, identifier, svcType, client, meta)
+	if err != nil {
+		return err
+	}
+	c.DevModeType = profile.ReplaceDevMode
+
+	// Read before rolling back, the record is removed with DevMode manifest
+	suspendedHPA, err := c.GetSuspendedHPA()
+	if err != nil {
+		glog.Errorf("Failed to get suspended hpa of %s %s/%s: %v", svcType, namespace, name, err)
+	}
+	if err = c.BuildPodController().RollBack(true); err != nil {
+		return err
+	}
+	c.RestoreHPA(suspendedHPA)
+	if err = c.ClearDevModeTTL(); err != nil {
+		glog.Errorf("Failed to remove ttl of %s %s/%s: %v", svcType, namespace, name, err)
+	}
+
+	return meta.SvcDevEndInCluster(name, identifier, svcType, profile.ReplaceDevMode)
+}
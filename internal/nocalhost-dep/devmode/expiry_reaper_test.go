@@ -0,0 +1,100 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package devmode
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+	"net/http"
+	"net/http/httptest"
+	"nocalhost/internal/nhctl/appmeta"
+	_const "nocalhost/internal/nhctl/const"
+	"testing"
+	"time"
+)
+
+func TestReapWorkloadNotInDevMode(t *testing.T) {
+	// DevMode of reviews has been ended, but its ttl expired long ago is left in annotations
+	reviews := metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{Kind: "PartialObjectMetadata", APIVersion: "meta.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "reviews",
+			Namespace: "nocalhost-test",
+			Annotations: map[string]string{
+				_const.DevModeTTLAnnotation:       "1h",
+				_const.DevModeHeartbeatAnnotation: time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+				_const.NocalhostApplicationName:   "bookinfo",
+			},
+		},
+	}
+	secret := corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: appmeta.SecretNamePrefix + "bookinfo", Namespace: "nocalhost-test"},
+		Data:       map[string][]byte{appmeta.SecretStateKey: []byte(string(appmeta.INSTALLED))},
+	}
+
+	patches := map[string]string{}
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				var body interface{}
+				switch {
+				case req.Method == http.MethodPatch:
+					bys, _ := ioutil.ReadAll(req.Body)
+					patches[req.URL.Path] = string(bys)
+					body = reviews
+				case req.URL.Path == "/api/v1/namespaces/nocalhost-test":
+					body = corev1.Namespace{TypeMeta: metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"}}
+				case req.URL.Path == "/api/v1/namespaces/nocalhost-test/secrets/"+secret.Name:
+					body = secret
+				case req.URL.Path == "/apis/apps/v1/deployments":
+					body = metav1.PartialObjectMetadataList{
+						TypeMeta: metav1.TypeMeta{Kind: "PartialObjectMetadataList", APIVersion: "meta.k8s.io/v1"},
+						Items:    []metav1.PartialObjectMetadata{reviews},
+					}
+				default:
+					body = metav1.PartialObjectMetadataList{
+						TypeMeta: metav1.TypeMeta{Kind: "PartialObjectMetadataList", APIVersion: "meta.k8s.io/v1"},
+					}
+				}
+				_ = json.NewEncoder(w).Encode(body)
+			},
+		),
+	)
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataClient, err := metadata.NewForConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	NewExpiryReaper(clientset, metadataClient).reap()
+
+	patch, ok := patches["/apis/apps/v1/namespaces/nocalhost-test/deployments/reviews"]
+	if !ok {
+		t.Fatalf("ttl of the workload should be removed, patches: %v", patches)
+	}
+	annotations := map[string]map[string]interface{}{}
+	if err = json.Unmarshal([]byte(patch), &annotations); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{_const.DevModeTTLAnnotation, _const.DevModeHeartbeatAnnotation} {
+		if value, ok := annotations["metadata"]["annotations"].(map[string]interface{})[key]; !ok || value != nil {
+			t.Fatalf("annotation %s should be removed by %s", key, patch)
+		}
+	}
+}
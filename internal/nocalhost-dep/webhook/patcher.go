@@ -9,9 +9,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"nocalhost/internal/nhctl/profile"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/golang/glog"
@@ -189,40 +191,9 @@ func nocalhostDepConfigmapCustom(
 		}
 	}
 
-	var waitCmd string
-
-	if svcConfig.DependLabelSelector != nil {
-		if svcConfig.DependLabelSelector.Pods != nil && len(svcConfig.DependLabelSelector.Pods) > 0 {
-			args := waitForPodArgs(svcConfig.DependLabelSelector.Pods)
-			if waitCmd != "" {
-				waitCmd += " && "
-			}
-			waitCmd += strings.Join(args, " ")
-		}
-
-		if svcConfig.DependLabelSelector.Jobs != nil && len(svcConfig.DependLabelSelector.Jobs) > 0 {
-			args := waitForJobArgs(svcConfig.DependLabelSelector.Jobs)
-			if waitCmd != "" {
-				waitCmd += " && "
-			}
-			waitCmd += strings.Join(args, " ")
-		}
-
-		if svcConfig.DependLabelSelector.TCP != nil && len(svcConfig.DependLabelSelector.TCP) > 0 {
-			args := waitForTCPArgs(svcConfig.DependLabelSelector.TCP)
-			if waitCmd != "" {
-				waitCmd += " && "
-			}
-			waitCmd += strings.Join(args, " ")
-		}
-
-		if svcConfig.DependLabelSelector.HTTP != nil && len(svcConfig.DependLabelSelector.HTTP) > 0 {
-			args := waitForHTTPArgs(svcConfig.DependLabelSelector.HTTP)
-			if waitCmd != "" {
-				waitCmd += " && "
-			}
-			waitCmd += strings.Join(args, " ")
-		}
+	waitCmd, err := dependencyWaitCmd(svcConfig.DependLabelSelector)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if waitCmd != "" {
@@ -338,7 +309,10 @@ func nocalhostDepConfigmap(
 							dep.ReleaseName+"-"+dependency.Name == resourceName) {
 						// initContainer
 						if dependency.Pods != nil {
-							args := waitForPodArgs(dependency.Pods)
+							args, err := waitForPodArgs(dependency.Pods, "")
+							if err != nil {
+								return nil, nil, err
+							}
 
 							if waitCmd != "" {
 								waitCmd += " && "
@@ -346,7 +320,10 @@ func nocalhostDepConfigmap(
 							waitCmd += strings.Join(args, " ")
 						}
 						if dependency.Jobs != nil {
-							args := waitForJobArgs(dependency.Jobs)
+							args, err := waitForJobArgs(dependency.Jobs, "")
+							if err != nil {
+								return nil, nil, err
+							}
 
 							if waitCmd != "" {
 								waitCmd += " && "
@@ -374,62 +351,104 @@ func nocalhostDepConfigmap(
 	return initContainers, envVarArray, err
 }
 
-func waitForPodArgs(pods []string) []string {
-	var args []string
-	for key, pod := range pods {
-		if key != 0 {
-			args = append(args, "&&")
+// dependencyTimeoutError a timeout of dependency can not be parsed, the admission is rejected for it
+type dependencyTimeoutError struct {
+	timeout string
+	err     error
+}
+
+func (e *dependencyTimeoutError) Error() string {
+	return fmt.Sprintf("Invalid timeout %s of dependency: %v", e.timeout, e.err)
+}
+
+// dependencyWaitCmd joins commands waiting for each dependency, a dependency fails
+// after its timeout and the init container logs the reason
+func dependencyWaitCmd(selector *profile.DependLabelSelector) (string, error) {
+	if selector == nil {
+		return "", nil
+	}
+	cmds := make([]string, 0)
+	for _, dependency := range []struct {
+		kind      string
+		targets   []string
+		targetArg func(string) string
+	}{
+		{"pod", selector.Pods, labelSelectorArg},
+		{"job", selector.Jobs, labelSelectorArg},
+		{"tcp", selector.TCP, nil},
+		{"http", selector.HTTP, nil},
+		{"grpc", selector.GRPC, nil},
+		{"dns", selector.DNS, nil},
+		{"kafka", selector.Kafka, nil},
+		{"endpoints", selector.Endpoints, nil},
+	} {
+		args, err := waitForArgs(dependency.kind, dependency.targets, selector.Timeout, dependency.targetArg)
+		if err != nil {
+			return "", err
 		}
-		args = append(args, "wait_for.sh", "pod")
-		if strings.ContainsAny(
-			pod, "=",
-		) { // means define label, such as app.kubernetes.io/name=nginx
-			args = append(args, fmt.Sprintf("-l%s", pod))
-		} else { // has not define label, default app label
-			args = append(args, fmt.Sprintf("-lapp=%s", pod))
+		if len(args) > 0 {
+			cmds = append(cmds, strings.Join(args, " "))
 		}
 	}
-	return args
+	return strings.Join(cmds, " && "), nil
+}
+
+func waitForPodArgs(pods []string, timeout string) ([]string, error) {
+	return waitForArgs("pod", pods, timeout, labelSelectorArg)
+}
+
+func waitForJobArgs(jobs []string, timeout string) ([]string, error) {
+	return waitForArgs("job", jobs, timeout, labelSelectorArg)
 }
 
-func waitForJobArgs(jobs []string) []string {
+// waitForArgs builds wait_for.sh commands for targets of kind, the timeout is passed by env TIMEOUT in seconds
+func waitForArgs(kind string, targets []string, timeout string, targetArg func(string) string) ([]string, error) {
 	var args []string
-	for key, job := range jobs {
+	for key, target := range targets {
 		if key != 0 {
 			args = append(args, "&&")
 		}
-		args = append(args, "wait_for.sh", "job")
-		if strings.ContainsAny(
-			job, "=",
-		) { // means define label, such as app.kubernetes.io/name=nginx
-			args = append(args, fmt.Sprintf("-l%s", job))
-		} else { // has not define label, default app label
-			args = append(args, fmt.Sprintf("-lapp=%s", job))
+		targetTimeout := timeout
+		if i := strings.LastIndex(target, profile.DependencyTimeoutSuffix); i >= 0 {
+			target, targetTimeout = strings.TrimSpace(target[:i]), target[i+len(profile.DependencyTimeoutSuffix):]
+		}
+		seconds, err := timeoutSeconds(targetTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if seconds > 0 {
+			args = append(args, fmt.Sprintf("TIMEOUT=%d", seconds))
+		}
+		if targetArg != nil {
+			target = targetArg(target)
 		}
+		args = append(args, "wait_for.sh", kind, target)
 	}
-	return args
+	return args, nil
 }
 
-func waitForTCPArgs(tcp []string) []string {
-	var args []string
-	for key, t := range tcp {
-		if key != 0 {
-			args = append(args, "&&")
-		}
-		args = append(args, "wait_for.sh", "tcp", t)
+func labelSelectorArg(selector string) string {
+	// means define label, such as app.kubernetes.io/name=nginx
+	if strings.ContainsAny(selector, "=") {
+		return fmt.Sprintf("-l%s", selector)
 	}
-	return args
+	// has not define label, default app label
+	return fmt.Sprintf("-lapp=%s", selector)
 }
 
-func waitForHTTPArgs(http []string) []string {
-	var args []string
-	for key, h := range http {
-		if key != 0 {
-			args = append(args, "&&")
-		}
-		args = append(args, "wait_for.sh", "http", h)
+// timeoutSeconds parses timeout such as 30s or 2m, zero means the default timeout of wait_for.sh
+func timeoutSeconds(timeout string) (int, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, &dependencyTimeoutError{timeout: timeout, err: err}
+	}
+	if d < 0 {
+		return 0, &dependencyTimeoutError{timeout: timeout, err: fmt.Errorf("timeout can not be negative")}
 	}
-	return args
+	return int(math.Ceil(d.Seconds())), nil
 }
 
 // add initContainers
@@ -0,0 +1,132 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package webhook
+
+import (
+	"nocalhost/internal/nhctl/profile"
+	"strings"
+	"testing"
+)
+
+func TestDependencyWaitCmd(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		selector *profile.DependLabelSelector
+		expected string
+	}{
+		{name: "nil", selector: nil, expected: ""},
+		{name: "empty", selector: &profile.DependLabelSelector{}, expected: ""},
+		{
+			name:     "pods",
+			selector: &profile.DependLabelSelector{Pods: []string{"ratings", "app.kubernetes.io/name=details"}},
+			expected: "wait_for.sh pod -lapp=ratings && wait_for.sh pod -lapp.kubernetes.io/name=details",
+		},
+		{
+			name:     "jobs with timeout",
+			selector: &profile.DependLabelSelector{Jobs: []string{"migrate"}, Timeout: "90s"},
+			expected: "TIMEOUT=90 wait_for.sh job -lapp=migrate",
+		},
+		{
+			name: "timeout of items",
+			selector: &profile.DependLabelSelector{
+				TCP:     []string{"mysql:3306 timeout=2m", "redis:6379"},
+				Timeout: "1500ms",
+			},
+			expected: "TIMEOUT=120 wait_for.sh tcp mysql:3306 && TIMEOUT=2 wait_for.sh tcp redis:6379",
+		},
+		{
+			name: "zero timeout",
+			selector: &profile.DependLabelSelector{
+				HTTP: []string{"http://reviews:9080/health timeout=0s"}, Timeout: "30s",
+			},
+			expected: "wait_for.sh http http://reviews:9080/health",
+		},
+		{
+			name: "all kinds",
+			selector: &profile.DependLabelSelector{
+				Pods:      []string{"ratings"},
+				Jobs:      []string{"migrate"},
+				TCP:       []string{"mysql:3306"},
+				HTTP:      []string{"http://reviews:9080/health"},
+				GRPC:      []string{"ratings:9080/ratings"},
+				DNS:       []string{"details"},
+				Kafka:     []string{"kafka:9092/orders"},
+				Endpoints: []string{"default/productpage"},
+			},
+			expected: "wait_for.sh pod -lapp=ratings && wait_for.sh job -lapp=migrate && " +
+				"wait_for.sh tcp mysql:3306 && wait_for.sh http http://reviews:9080/health && " +
+				"wait_for.sh grpc ratings:9080/ratings && wait_for.sh dns details && " +
+				"wait_for.sh kafka kafka:9092/orders && wait_for.sh endpoints default/productpage",
+		},
+	} {
+		t.Run(
+			c.name, func(t *testing.T) {
+				cmd, err := dependencyWaitCmd(c.selector)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if cmd != c.expected {
+					t.Fatalf("expected %q, but got %q", c.expected, cmd)
+				}
+			},
+		)
+	}
+}
+
+func TestDependencyWaitCmdInvalidTimeout(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		selector *profile.DependLabelSelector
+		timeout  string
+	}{
+		{
+			name:     "unit missing",
+			selector: &profile.DependLabelSelector{Pods: []string{"ratings"}, Timeout: "30"},
+			timeout:  "30",
+		},
+		{
+			name:     "negative",
+			selector: &profile.DependLabelSelector{Jobs: []string{"migrate"}, Timeout: "-1m"},
+			timeout:  "-1m",
+		},
+		{
+			name:     "item",
+			selector: &profile.DependLabelSelector{DNS: []string{"details timeout=soon"}, Timeout: "30s"},
+			timeout:  "soon",
+		},
+	} {
+		t.Run(
+			c.name, func(t *testing.T) {
+				_, err := dependencyWaitCmd(c.selector)
+				if _, ok := err.(*dependencyTimeoutError); !ok {
+					t.Fatalf("dependencyTimeoutError expected, but got %v", err)
+				}
+				if !strings.Contains(err.Error(), "Invalid timeout "+c.timeout+" ") {
+					t.Fatalf("timeout %s should be reported, but got %s", c.timeout, err.Error())
+				}
+			},
+		)
+	}
+}
+
+func TestInvalidDependencyTimeoutRejected(t *testing.T) {
+	_, _, err := nocalhostDepConfigmapCustom(
+		func() (*profile.NocalHostAppConfigV2, *profile.ServiceConfigV2, error) {
+			return nil, &profile.ServiceConfigV2{
+				Name:                "productpage",
+				DependLabelSelector: &profile.DependLabelSelector{TCP: []string{"reviews:9080 timeout=5"}},
+			}, nil
+		}, nil,
+	)
+	if _, ok := err.(*dependencyTimeoutError); !ok {
+		t.Fatalf("dependencyTimeoutError expected, but got %v", err)
+	}
+
+	resp := rejectedAdmission(err)
+	if resp.Allowed || resp.Result == nil || !strings.Contains(resp.Result.Message, "Invalid timeout 5 of dependency") {
+		t.Fatalf("admission should be rejected with the invalid timeout, but got %v", resp)
+	}
+}
@@ -52,7 +52,9 @@ var ignoredNamespaces = []string{
 }
 
 var nocalhostNamespace = "nocalhost-reserved"
-var waitImages = "nocalhost-docker.pkg.coding.net/nocalhost/public/nocalhost-wait:latest"
+
+// waitImages wait_for.sh of v2 supports grpc, dns, kafka and endpoints, and TIMEOUT of each dependency
+var waitImages = "nocalhost-docker.pkg.coding.net/nocalhost/public/nocalhost-wait:v2"
 
 const (
 	admissionWebhookAnnotationInjectKey = "sidecar-injector-webhook.nocalhost/inject"
@@ -147,6 +149,10 @@ func init() {
 	// defaulting with webhooks:
 	// https://github.com/kubernetes/kubernetes/issues/57982
 	_ = corev1.AddToScheme(runtimeScheme)
+}
+
+// InitClients creates clients of the cluster nocalhost-dep runs in, it must be called before serving
+func InitClients() {
 	clientset = nocalhost.InitClientSet()
 	cachedRestMapper = nocalhost.InitCachedRestMapper()
 }
@@ -518,6 +524,9 @@ func (whsvr *WebhookServer) mutate(ar *v1.AdmissionReview) *v1.AdmissionResponse
 			}, containers,
 		)
 
+		if _, ok := err.(*dependencyTimeoutError); ok {
+			return rejectedAdmission(err)
+		}
 		if err != nil {
 			glog.Infof(
 				"Admission Config Resolve Err from annotation for Kind=%v, Namespace=%v, Name=%v, Error: %v",
@@ -551,6 +560,9 @@ func (whsvr *WebhookServer) mutate(ar *v1.AdmissionReview) *v1.AdmissionResponse
 					return appCfg, svcCfg, nil
 				}, containers,
 			); err != nil {
+				if _, ok := err.(*dependencyTimeoutError); ok {
+					return rejectedAdmission(err)
+				}
 				glog.Infof(
 					"Admission Config Resolve Err from configmap while load svc for Kind=%v, Namespace=%v, Name=%v, Error: %v",
 					req.Kind, req.Namespace, resourceName, err,
@@ -571,6 +583,9 @@ func (whsvr *WebhookServer) mutate(ar *v1.AdmissionReview) *v1.AdmissionResponse
 		if injectInitContainers, EnvVar, err = nocalhostDepConfigmap(
 			nocalhostNamespace, resourceName, resourceType, objectMeta, containers,
 		); err != nil {
+			if _, ok := err.(*dependencyTimeoutError); ok {
+				return rejectedAdmission(err)
+			}
 			glog.Infof(
 				"Admission Config Resolve Err for Kind=%v, Namespace=%v, Name=%v, Error: %v",
 				req.Kind, req.Namespace, resourceName, err,
@@ -613,6 +628,15 @@ func (whsvr *WebhookServer) mutate(ar *v1.AdmissionReview) *v1.AdmissionResponse
 	}
 }
 
+// rejectedAdmission rejects resources with invalid nocalhost config, such as a dependency with invalid timeout
+func rejectedAdmission(err error) *v1.AdmissionResponse {
+	return &v1.AdmissionResponse{
+		Result: &metav1.Status{
+			Message: err.Error(),
+		},
+	}
+}
+
 // Serve method for webhook server
 func (whsvr *WebhookServer) Serve(w http.ResponseWriter, r *http.Request) {
 	var body []byte
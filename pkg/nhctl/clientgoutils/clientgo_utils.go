@@ -115,31 +115,65 @@ func NewClientGoUtils(kubeConfigPath string, namespace string) (*ClientGoUtils,
 		return nil, errors.Wrap(err, "")
 	}
 
-	// set default rateLimiter to 100, in case of throttling request
-	client.restConfig.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(1000, 2000)
+	if err = client.initClients(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
 
-	if client.ClientSet, err = kubernetes.NewForConfig(client.restConfig); err != nil {
-		return nil, errors.Wrap(err, "")
+// NewClientGoUtilsInCluster uses the service account of the pod, it's used by components running in cluster
+func NewClientGoUtilsInCluster(namespace string) (*ClientGoUtils, error) {
+	var (
+		err error
+	)
+
+	client := &ClientGoUtils{
+		namespace: namespace,
 	}
 
-	if client.dynamicClient, err = dynamic.NewForConfig(client.restConfig); err != nil {
+	// kubeconfig is not found in cluster, so in cluster config will be loaded
+	client.ClientConfig = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{},
+	)
+
+	if client.restConfig, err = restclient.InClusterConfig(); err != nil {
 		return nil, errors.Wrap(err, "")
 	}
 
-	if client.restMapper, err = client.NewFactory().ToRESTMapper(); err != nil {
-		return nil, errors.Wrap(err, "")
+	if err = client.initClients(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (c *ClientGoUtils) initClients() error {
+	var err error
+
+	// set default rateLimiter to 100, in case of throttling request
+	c.restConfig.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(1000, 2000)
+
+	if c.ClientSet, err = kubernetes.NewForConfig(c.restConfig); err != nil {
+		return errors.Wrap(err, "")
 	}
 
-	if client.namespace == "" {
-		client.namespace, err = client.GetDefaultNamespace()
+	if c.dynamicClient, err = dynamic.NewForConfig(c.restConfig); err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	if c.restMapper, err = c.NewFactory().ToRESTMapper(); err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	if c.namespace == "" {
+		c.namespace, err = c.GetDefaultNamespace()
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	client.ctx = context.TODO()
+	c.ctx = context.TODO()
 
-	return client, nil
+	return nil
 }
 
 func GetKubeContentFromPath(kubePath string) ([]byte, error) {
@@ -510,6 +544,11 @@ func (c *ClientGoUtils) GetService(name string) (*corev1.Service, error) {
 	return service, nil
 }
 
+func (c *ClientGoUtils) GetEndpoints(name string) (*corev1.Endpoints, error) {
+	endpoints, err := c.ClientSet.CoreV1().Endpoints(c.namespace).Get(c.ctx, name, metav1.GetOptions{})
+	return endpoints, errors.Wrap(err, "")
+}
+
 func (c *ClientGoUtils) GetContext() context.Context {
 	return c.ctx
 }
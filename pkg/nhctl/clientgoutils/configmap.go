@@ -6,6 +6,7 @@
 package clientgoutils
 
 import (
+	"fmt"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,3 +34,21 @@ func (c *ClientGoUtils) GetConfigMaps(name string) (*v1.ConfigMap, error) {
 	result, err := c.ClientSet.CoreV1().ConfigMaps(c.namespace).Get(c.ctx, name, metav1.GetOptions{})
 	return result, errors.Wrap(err, "")
 }
+
+// GetConfigMapData returns data of key in the configmap, namespace of the client is used if namespace is empty
+func (c *ClientGoUtils) GetConfigMapData(namespace, name, key string) (string, error) {
+	if namespace == "" {
+		namespace = c.namespace
+	}
+	cm, err := c.ClientSet.CoreV1().ConfigMaps(namespace).Get(c.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "")
+	}
+	if v, ok := cm.Data[key]; ok {
+		return v, nil
+	}
+	if v, ok := cm.BinaryData[key]; ok {
+		return string(v), nil
+	}
+	return "", errors.New(fmt.Sprintf("Key %s not found in configmap %s/%s", key, namespace, name))
+}
@@ -160,3 +160,29 @@ func EnhanceListError(err error, opts metav1.ListOptions, subj string) error {
 	}
 	return err
 }
+
+// CreateEvent records an event to the object referred by ref
+func (c *ClientGoUtils) CreateEvent(ref *corev1.ObjectReference, eventType, reason, message string) error {
+	now := metav1.Now()
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = c.namespace
+	}
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// the same as events recorded by kubernetes
+			Name:      fmt.Sprintf("%v.%x", ref.Name, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: *ref,
+		Reason:         reason,
+		Message:        message,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: "nocalhost"},
+	}
+	_, err := c.ClientSet.CoreV1().Events(namespace).Create(c.ctx, event, metav1.CreateOptions{})
+	return errors.Wrap(err, "")
+}
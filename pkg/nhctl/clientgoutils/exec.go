@@ -150,6 +150,36 @@ func (c *ClientGoUtils) Exec(podName string, containerName string, command []str
 	return nil
 }
 
+// ExecWithIO executes command in container without a tty, stdin, stdout and stderr can be nil
+func (c *ClientGoUtils) ExecWithIO(podName, containerName string, command []string,
+	stdin io.Reader, stdout, stderr io.Writer) error {
+	rc, err := c.NewFactory().ToRESTConfig()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	restClient, err := restclient.RESTClientFor(rc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	req := restClient.Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(c.namespace).
+		SubResource("exec")
+	req.VersionedParams(
+		&corev1.PodExecOptions{
+			Container: containerName,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    stdout != nil,
+			Stderr:    stderr != nil,
+			TTY:       false,
+		}, scheme.ParameterCodec,
+	)
+	return Execute("POST", req.URL(), c.restConfig, stdin, stdout, stderr, false, nil)
+}
+
 func Execute(
 	method string, url *url.URL, config *restclient.Config,
 	stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue k8sremotecommand.TerminalSizeQueue,
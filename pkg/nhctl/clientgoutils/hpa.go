@@ -18,6 +18,11 @@ func (c *ClientGoUtils) UpdateHPA(hpa *autoscalingv1.HorizontalPodAutoscaler) (*
 	return hpa2, errors.Wrap(err, "")
 }
 
+func (c *ClientGoUtils) GetHPA(name string) (*autoscalingv1.HorizontalPodAutoscaler, error) {
+	hpa, err := c.ClientSet.AutoscalingV1().HorizontalPodAutoscalers(c.namespace).Get(c.ctx, name, metav1.GetOptions{})
+	return hpa, errors.Wrap(err, "")
+}
+
 func (c *ClientGoUtils) ListHPA() ([]autoscalingv1.HorizontalPodAutoscaler, error) {
 	ops := metav1.ListOptions{}
 	if len(c.labels) > 0 {
@@ -98,3 +98,14 @@ func (c *ClientGoUtils) UpdatePod(pod *corev1.Pod) (*corev1.Pod, error) {
 	pod2, err := c.GetPodClient().Update(c.ctx, pod, metav1.UpdateOptions{})
 	return pod2, errors.Wrap(err, "")
 }
+
+// AddEphemeralContainers attach containers to a running pod without restarting it
+func (c *ClientGoUtils) AddEphemeralContainers(podName string, containers ...corev1.EphemeralContainer) error {
+	ecs, err := c.GetPodClient().GetEphemeralContainers(c.ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	ecs.EphemeralContainers = append(ecs.EphemeralContainers, containers...)
+	_, err = c.GetPodClient().UpdateEphemeralContainers(c.ctx, podName, ecs, metav1.UpdateOptions{})
+	return errors.Wrap(err, "")
+}
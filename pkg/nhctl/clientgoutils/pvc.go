@@ -20,6 +20,29 @@ import (
 // storageClassName: nil to use default storageClassName
 func (c *ClientGoUtils) CreatePVC(
 	name string, labels map[string]string, annotations map[string]string, quantityStr string, storageClassName *string,
+) (*v1.PersistentVolumeClaim, error) {
+	return c.CreatePVCFromSnapshot(name, labels, annotations, quantityStr, storageClassName, "")
+}
+
+// CreatePVCFromSnapshot creates a pvc populated from the VolumeSnapshot, snapshot is ignored if it is empty
+func (c *ClientGoUtils) CreatePVCFromSnapshot(
+	name string, labels map[string]string, annotations map[string]string, quantityStr string, storageClassName *string,
+	snapshot string,
+) (*v1.PersistentVolumeClaim, error) {
+	persistentVolumeClaim, err := NewPVC(name, labels, annotations, quantityStr, storageClassName)
+	if err != nil {
+		return nil, err
+	}
+	SetPVCSnapshotDataSource(persistentVolumeClaim, snapshot)
+	pvc, err := c.ClientSet.CoreV1().PersistentVolumeClaims(c.namespace).Create(
+		c.ctx, persistentVolumeClaim, metav1.CreateOptions{},
+	)
+	return pvc, errors.WithStack(err)
+}
+
+// NewPVC generates a pvc definition without creating it
+func NewPVC(
+	name string, labels map[string]string, annotations map[string]string, quantityStr string, storageClassName *string,
 ) (*v1.PersistentVolumeClaim, error) {
 	q, err := resource.ParseQuantity(quantityStr)
 	if err != nil {
@@ -41,10 +64,20 @@ func (c *ClientGoUtils) CreatePVC(
 	persistentVolumeClaim.Name = name
 	persistentVolumeClaim.Labels = labels
 	persistentVolumeClaim.Annotations = annotations
+	return persistentVolumeClaim, nil
+}
 
-	return c.ClientSet.CoreV1().PersistentVolumeClaims(c.namespace).Create(
-		c.ctx, persistentVolumeClaim, metav1.CreateOptions{},
-	)
+// SetPVCSnapshotDataSource makes the pvc be populated from the VolumeSnapshot
+func SetPVCSnapshotDataSource(pvc *v1.PersistentVolumeClaim, snapshot string) {
+	if snapshot == "" {
+		return
+	}
+	apiGroup := VolumeSnapshotGVR.Group
+	pvc.Spec.DataSource = &v1.TypedLocalObjectReference{
+		APIGroup: &apiGroup,
+		Kind:     "VolumeSnapshot",
+		Name:     snapshot,
+	}
 }
 
 func (c *ClientGoUtils) DeletePVC(name string) error {
@@ -6,6 +6,8 @@
 package clientgoutils
 
 import (
+	"fmt"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -24,4 +26,21 @@ func (c *ClientGoUtils) GetSecret(name string) (*corev1.Secret, error) {
 
 func (c *ClientGoUtils) DeleteSecret(name string) error {
 	return c.ClientSet.CoreV1().Secrets(c.namespace).Delete(c.ctx, name, metav1.DeleteOptions{})
-}
\ No newline at end of file
+}
+// GetSecretData returns decoded data of key in the secret, namespace of the client is used if namespace is empty
+func (c *ClientGoUtils) GetSecretData(namespace, name, key string) (string, error) {
+	if namespace == "" {
+		namespace = c.namespace
+	}
+	secret, err := c.ClientSet.CoreV1().Secrets(namespace).Get(c.ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "")
+	}
+	if v, ok := secret.Data[key]; ok {
+		return string(v), nil
+	}
+	if v, ok := secret.StringData[key]; ok {
+		return v, nil
+	}
+	return "", errors.New(fmt.Sprintf("Key %s not found in secret %s/%s", key, namespace, name))
+}
@@ -0,0 +1,92 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package clientgoutils
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"time"
+)
+
+// VolumeSnapshotGVR VolumeSnapshot is provided by CSI external-snapshotter, no typed client is available
+var VolumeSnapshotGVR = schema.GroupVersionResource{
+	Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots",
+}
+
+// CreateVolumeSnapshot creates a VolumeSnapshot of the pvc
+// snapshotClass: empty to use default VolumeSnapshotClass
+func (c *ClientGoUtils) CreateVolumeSnapshot(
+	name, pvcName, snapshotClass string, labels map[string]string, annotations map[string]string,
+) (*unstructured.Unstructured, error) {
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"persistentVolumeClaimName": pvcName},
+	}
+	if snapshotClass != "" {
+		spec["volumeSnapshotClassName"] = snapshotClass
+	}
+	snapshot := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": VolumeSnapshotGVR.GroupVersion().String(),
+			"kind":       "VolumeSnapshot",
+			"spec":       spec,
+		},
+	}
+	snapshot.SetName(name)
+	snapshot.SetLabels(labels)
+	snapshot.SetAnnotations(annotations)
+
+	result, err := c.GetDynamicClient().Resource(VolumeSnapshotGVR).Namespace(c.namespace).Create(
+		c.ctx, snapshot, metav1.CreateOptions{},
+	)
+	return result, errors.WithStack(err)
+}
+
+func (c *ClientGoUtils) GetVolumeSnapshot(name string) (*unstructured.Unstructured, error) {
+	snapshot, err := c.GetDynamicClient().Resource(VolumeSnapshotGVR).Namespace(c.namespace).Get(
+		c.ctx, name, metav1.GetOptions{},
+	)
+	return snapshot, errors.WithStack(err)
+}
+
+func (c *ClientGoUtils) ListVolumeSnapshotsByLabels(lbs map[string]string) ([]unstructured.Unstructured, error) {
+	list, err := c.GetDynamicClient().Resource(VolumeSnapshotGVR).Namespace(c.namespace).List(
+		c.ctx, metav1.ListOptions{LabelSelector: labels.Set(lbs).String()},
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return list.Items, nil
+}
+
+func (c *ClientGoUtils) DeleteVolumeSnapshot(name string) error {
+	return errors.WithStack(
+		c.GetDynamicClient().Resource(VolumeSnapshotGVR).Namespace(c.namespace).Delete(
+			c.ctx, name, metav1.DeleteOptions{},
+		),
+	)
+}
+
+// WaitVolumeSnapshotReady waits until the VolumeSnapshot is ready to be used to populate pvcs
+func (c *ClientGoUtils) WaitVolumeSnapshotReady(name string, timeout time.Duration) error {
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(2 * time.Second) {
+		snapshot, err := c.GetVolumeSnapshot(name)
+		if err != nil {
+			return err
+		}
+		ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+		if ready {
+			return nil
+		}
+		if message, ok, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); ok {
+			return errors.New(fmt.Sprintf("VolumeSnapshot %s failed: %s", name, message))
+		}
+	}
+	return errors.New(fmt.Sprintf("Timeout waiting for VolumeSnapshot %s to be ready", name))
+}
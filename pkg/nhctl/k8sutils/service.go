@@ -0,0 +1,73 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package k8sutils
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"strconv"
+)
+
+// FindServicePort returns the port of svc whose number or name is port
+func FindServicePort(svc *corev1.Service, port string) (*corev1.ServicePort, error) {
+	for i, p := range svc.Spec.Ports {
+		if p.Name == port || strconv.Itoa(int(p.Port)) == port {
+			return &svc.Spec.Ports[i], nil
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("Port %s not found in service %s", port, svc.Name))
+}
+
+// SelectServiceEndpoint returns a ready pod behind port of svc and the container port mapped, current is kept
+// if it is still ready. Named target ports are resolved in endpoints, whose ports are named as service ports
+func SelectServiceEndpoint(svc *corev1.Service, ep *corev1.Endpoints, port, current string) (string, int, error) {
+	svcPort, err := FindServicePort(svc, port)
+	if err != nil {
+		return "", 0, err
+	}
+	podName, podPort := "", 0
+	for _, subset := range ep.Subsets {
+		subsetPort := 0
+		for _, p := range subset.Ports {
+			if p.Name == svcPort.Name {
+				subsetPort = int(p.Port)
+				break
+			}
+		}
+		if subsetPort == 0 {
+			continue
+		}
+		// addresses not ready are listed in NotReadyAddresses
+		for _, address := range subset.Addresses {
+			if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+				continue
+			}
+			if address.TargetRef.Name == current {
+				return current, subsetPort, nil
+			}
+			if podName == "" {
+				podName, podPort = address.TargetRef.Name, subsetPort
+			}
+		}
+	}
+	if podName == "" {
+		return "", 0, errors.New(fmt.Sprintf("No ready pod found behind port %s of service %s", port, svc.Name))
+	}
+	return podName, podPort, nil
+}
+
+// IsReadyEndpoint returns true if pod is listed in ready addresses of ep
+func IsReadyEndpoint(ep *corev1.Endpoints, pod string) bool {
+	for _, subset := range ep.Subsets {
+		for _, address := range subset.Addresses {
+			if address.TargetRef != nil && address.TargetRef.Kind == "Pod" && address.TargetRef.Name == pod {
+				return true
+			}
+		}
+	}
+	return false
+}
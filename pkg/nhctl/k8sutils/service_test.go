@@ -0,0 +1,71 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package k8sutils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"testing"
+)
+
+func TestSelectServiceEndpoint(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviews"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromString("web")},
+				{Name: "grpc", Port: 9090, TargetPort: intstr.FromInt(19090)},
+			},
+		},
+	}
+	podRef := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{Kind: "Pod", Name: name}
+	}
+	ep := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses:         []corev1.EndpointAddress{{TargetRef: podRef("reviews-a")}, {TargetRef: podRef("reviews-b")}},
+				NotReadyAddresses: []corev1.EndpointAddress{{TargetRef: podRef("reviews-c")}},
+				Ports:             []corev1.EndpointPort{{Name: "http", Port: 8080}, {Name: "grpc", Port: 19090}},
+			},
+		},
+	}
+
+	for _, c := range []struct {
+		port    string
+		current string
+		pod     string
+		podPort int
+	}{
+		{"http", "", "reviews-a", 8080},
+		{"80", "reviews-b", "reviews-b", 8080},
+		{"9090", "reviews-c", "reviews-a", 19090},
+		{"grpc", "reviews-gone", "reviews-a", 19090},
+	} {
+		pod, podPort, err := SelectServiceEndpoint(svc, ep, c.port, c.current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pod != c.pod || podPort != c.podPort {
+			t.Errorf("port %s with current %s should select %s:%d, but got %s:%d",
+				c.port, c.current, c.pod, c.podPort, pod, podPort)
+		}
+	}
+
+	for pod, ready := range map[string]bool{"reviews-a": true, "reviews-b": true, "reviews-c": false, "reviews-d": false} {
+		if IsReadyEndpoint(ep, pod) != ready {
+			t.Errorf("ready of %s should be %v", pod, ready)
+		}
+	}
+
+	if _, _, err := SelectServiceEndpoint(svc, ep, "metrics", ""); err == nil {
+		t.Error("port not in service should fail")
+	}
+	if _, _, err := SelectServiceEndpoint(svc, &corev1.Endpoints{}, "http", ""); err == nil {
+		t.Error("service without ready pods should fail")
+	}
+}
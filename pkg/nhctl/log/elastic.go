@@ -185,7 +185,7 @@ func writeStackToEsWithField(level string, msg string, stack string, field map[s
 
 	write := func() {
 		data := esLog{
-			Msg:       msg,
+			Msg:       Mask(msg),
 			PID:       fields["PID"],
 			PPID:      fields["PPID"],
 			App:       fields["APP"],
@@ -52,7 +52,9 @@ func getDefaultOutLogger(w zapcore.WriteSyncer) *zap.SugaredLogger {
 	encoderConfig0.EncodeTime = nil
 	encoderConfig0.EncodeLevel = nil
 	encoder2 := zapcore.NewConsoleEncoder(encoderConfig0)
-	return zap.New(zapcore.NewCore(encoder2, zapcore.AddSync(w), zap.InfoLevel), zap.ErrorOutput(w)).Sugar()
+	return zap.New(
+		zapcore.NewCore(encoder2, newMaskWriter(zapcore.AddSync(w)), zap.InfoLevel), zap.ErrorOutput(w),
+	).Sugar()
 }
 
 func Init(level zapcore.Level, dir, fileName string) error {
@@ -68,8 +70,8 @@ func Init(level zapcore.Level, dir, fileName string) error {
 	}
 
 	unFormatEncoder := zapcore.NewConsoleEncoder(cfg)
-	unFormatStdoutConfig := zapcore.NewCore(unFormatEncoder, zapcore.AddSync(os.Stdout), level)
-	unFormatStderrConfig := zapcore.NewCore(unFormatEncoder, zapcore.AddSync(os.Stderr), level)
+	unFormatStdoutConfig := zapcore.NewCore(unFormatEncoder, newMaskWriter(zapcore.AddSync(os.Stdout)), level)
+	unFormatStderrConfig := zapcore.NewCore(unFormatEncoder, newMaskWriter(zapcore.AddSync(os.Stderr)), level)
 
 	// file logger cfg
 	logPath := filepath.Join(dir, fileName)
@@ -81,7 +83,7 @@ func Init(level zapcore.Level, dir, fileName string) error {
 		Compress:   true,
 	}
 	rollingLog := &logWriter{rollingLog: rolling}
-	writeSyncer := zapcore.AddSync(rollingLog)
+	writeSyncer := newMaskWriter(zapcore.AddSync(rollingLog))
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = CustomTimeEncoder
 	encoderConfig.EncodeLevel = CustomLevelEncoder
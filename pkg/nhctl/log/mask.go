@@ -0,0 +1,85 @@
+/*
+* Copyright (C) 2021 THL A29 Limited, a Tencent company.  All rights reserved.
+* This source code is licensed under the Apache License Version 2.0.
+ */
+
+package log
+
+import (
+	"go.uber.org/zap/zapcore"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	maskedValue = "******"
+	// values shorter than this are not masked, or logs will be unreadable
+	minSensitiveLength = 4
+)
+
+var (
+	sensitiveLock   sync.RWMutex
+	sensitiveValues []string
+)
+
+// AddSensitive registers values such as secrets resolved while rendering configs,
+// they are masked in logs and outputs passed to Mask
+func AddSensitive(values ...string) {
+	sensitiveLock.Lock()
+	defer sensitiveLock.Unlock()
+	for _, v := range values {
+		if len(v) < minSensitiveLength || containsString(sensitiveValues, v) {
+			continue
+		}
+		sensitiveValues = append(sensitiveValues, v)
+	}
+	// longer values first, in case a value contains another one
+	sort.Slice(
+		sensitiveValues, func(i, j int) bool {
+			return len(sensitiveValues[i]) > len(sensitiveValues[j])
+		},
+	)
+}
+
+// Mask replaces sensitive values in s
+func Mask(s string) string {
+	sensitiveLock.RLock()
+	defer sensitiveLock.RUnlock()
+	for _, v := range sensitiveValues {
+		s = strings.ReplaceAll(s, v, maskedValue)
+	}
+	return s
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// maskWriter masks sensitive values before writing logs
+type maskWriter struct {
+	zapcore.WriteSyncer
+}
+
+func newMaskWriter(w zapcore.WriteSyncer) zapcore.WriteSyncer {
+	return &maskWriter{WriteSyncer: w}
+}
+
+func (m *maskWriter) Write(p []byte) (int, error) {
+	sensitiveLock.RLock()
+	empty := len(sensitiveValues) == 0
+	sensitiveLock.RUnlock()
+	if empty {
+		return m.WriteSyncer.Write(p)
+	}
+	if _, err := m.WriteSyncer.Write([]byte(Mask(string(p)))); err != nil {
+		return 0, err
+	}
+	// callers expect the length of p to be written
+	return len(p), nil
+}
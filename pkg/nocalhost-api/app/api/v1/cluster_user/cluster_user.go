@@ -7,9 +7,11 @@ package cluster_user
 
 import (
 	"context"
+	"time"
 
 	"github.com/spf13/cast"
 
+	_const "nocalhost/internal/nhctl/const"
 	"nocalhost/internal/nocalhost-api/service"
 	"nocalhost/pkg/nocalhost-api/pkg/clientgo"
 	"nocalhost/pkg/nocalhost-api/pkg/errno"
@@ -32,6 +34,7 @@ type ClusterUserCreateRequest struct {
 	MeshDevInfo        *setupcluster.MeshDevInfo `json:"mesh_dev_info"`
 	IsBaseSpace        bool                      `json:"is_base_space"`
 	Protected          bool                      `json:"protected"`
+	DevModeTTL         string                    `json:"dev_mode_ttl"`
 }
 
 func (cu *ClusterUserCreateRequest) Validate() (bool, error) {
@@ -48,6 +51,11 @@ func (cu *ClusterUserCreateRequest) Validate() (bool, error) {
 		}
 	}
 
+	// Validate DevMode ttl parameter format.
+	if _, ok := ParseDevModeTTL(cu.DevModeTTL); !ok {
+		return false, errno.ErrFormatDevModeTTLParam
+	}
+
 	// Validate MeshInfo parameter format.
 	if cu.BaseDevSpaceId > 0 {
 		if cu.IsBaseSpace {
@@ -151,3 +159,27 @@ func (srl *SpaceResourceLimit) Validate() bool {
 	}
 	return true
 }
+
+type DevModeTTLRequest struct {
+	DevModeTTL string `json:"dev_mode_ttl"`
+}
+
+// ParseDevModeTTL empty ttl means DevMode never expires
+func ParseDevModeTTL(ttl string) (time.Duration, bool) {
+	if ttl == "" {
+		return 0, true
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// devModeTTLAnnotations the default ttl of DevMode is recorded in namespace, which is read by nocalhost-dep
+func devModeTTLAnnotations(ttl time.Duration) map[string]interface{} {
+	if ttl == 0 {
+		return map[string]interface{}{_const.DevModeTTLAnnotation: nil}
+	}
+	return map[string]interface{}{_const.DevModeTTLAnnotation: ttl.String()}
+}
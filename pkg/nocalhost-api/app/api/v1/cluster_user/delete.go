@@ -159,6 +159,7 @@ func ReCreate(c *gin.Context) {
 		BaseDevSpaceId:     clusterUser.BaseDevSpaceId,
 		MeshDevInfo:        meshDevInfo,
 		IsBaseSpace:        clusterUser.IsBaseSpace,
+		DevModeTTL:         clusterUser.DevModeTTL,
 	}
 
 	cluster, err := service.Svc.ClusterSvc.GetCache(clusterUser.ClusterId)
@@ -313,6 +313,15 @@ func (d *DevSpace) createDevSpace(
 		res.ContainerEphemeralStorage,
 	)
 
+	// (5) default ttl of DevMode, which is validated already
+	ttl, _ := ParseDevModeTTL(d.DevSpaceParams.DevModeTTL)
+	if ttl > 0 {
+		if err = goClient.PatchNamespaceAnnotations(devNamespace, devModeTTLAnnotations(ttl)); err != nil {
+			log.Error(err)
+			return nil, errno.ErrUpdateDevModeTTL
+		}
+	}
+
 	var result model.ClusterUserModel
 
 	if any, _ := service.Svc.ClusterUserSvc.GetFirst(
@@ -333,6 +342,13 @@ func (d *DevSpace) createDevSpace(
 		}
 	}
 
+	if ttl > 0 && result.DevModeTTL != ttl.String() {
+		result.DevModeTTL = ttl.String()
+		if _, err = service.Svc.ClusterUserSvc.Update(d.c, &result); err != nil {
+			log.Error(err)
+		}
+	}
+
 	// auth application to user
 	_ = service.Svc.ApplicationUserSvc.BatchInsert(d.c, applicationId, []uint64{usersRecord.ID})
 
@@ -166,6 +166,85 @@ func UpdateResourceLimit(c *gin.Context) {
 	api.SendResponse(c, nil, result)
 }
 
+// UpdateDevModeTTL
+// @Summary UpdateDevModeTTL
+// @Description update default ttl of DevMode in dev space, DevMode is ended if neither file sync nor terminal is active for the ttl
+// @Tags DevSpace
+// @Accept  json
+// @Produce  json
+// @param Authorization header string true "Authorization"
+// @Param id path string true "devspace id"
+// @Param DevModeTTLRequest body cluster_user.DevModeTTLRequest true "ttl, such as 8h, empty means never expires"
+// @Success 200 {object} model.ClusterUserModel
+// @Router /v1/dev_space/{id}/update_dev_mode_ttl [put]
+func UpdateDevModeTTL(c *gin.Context) {
+	var req DevModeTTLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warnf("bind dev mode ttl params err: %v", err)
+		api.SendResponse(c, errno.ErrBind, nil)
+		return
+	}
+
+	devSpaceId := cast.ToUint64(c.Param("id"))
+
+	_, errn := HasPrivilegeToSomeDevSpace(c, devSpaceId)
+	if errn != nil {
+		api.SendResponse(c, errn, nil)
+		return
+	}
+
+	ttl, ok := ParseDevModeTTL(req.DevModeTTL)
+	if !ok {
+		log.Errorf("update devspace dev mode ttl fail. Incorrect ttl [ %s ] format.", req.DevModeTTL)
+		api.SendResponse(c, errno.ErrFormatDevModeTTLParam, nil)
+		return
+	}
+
+	condition := model.ClusterUserModel{
+		ID: devSpaceId,
+	}
+	devspace, err := service.Svc.ClusterUserSvc.GetFirst(c, condition)
+	if err != nil || devspace == nil {
+		log.Errorf("Dev space has not found")
+		api.SendResponse(c, errno.ErrClusterUserNotFound, nil)
+		return
+	}
+
+	// Build goclient with administrator kubeconfig
+	clusterData, err := service.Svc.ClusterSvc.Get(c, devspace.ClusterId)
+	if err != nil {
+		log.Errorf("Getting cluster information failed, cluster id = [ %v ] ", devspace.ClusterId)
+		api.SendResponse(c, errno.ErrPermissionCluster, nil)
+		return
+	}
+	goClient, err := clientgo.NewAdminGoClient([]byte(clusterData.KubeConfig))
+	if err != nil {
+		switch err.(type) {
+		case *errno.Errno:
+			api.SendResponse(c, err, nil)
+		default:
+			api.SendResponse(c, errno.ErrClusterKubeErr, nil)
+		}
+		return
+	}
+
+	if err = goClient.PatchNamespaceAnnotations(devspace.Namespace, devModeTTLAnnotations(ttl)); err != nil {
+		log.Error(err)
+		api.SendResponse(c, errno.ErrUpdateDevModeTTL, nil)
+		return
+	}
+
+	// zero value is ignored while updating, so 0s is recorded if DevMode never expires
+	devspace.DevModeTTL = ttl.String()
+	result, err := service.Svc.ClusterUserSvc.Update(c, devspace)
+	if err != nil {
+		log.Error(err)
+		api.SendResponse(c, errno.ErrUpdateDevModeTTL, nil)
+		return
+	}
+	api.SendResponse(c, nil, result)
+}
+
 // UpdateMeshDevSpaceInfo update mesh dev space info
 // @Summary Update mesh dev space info
 // @Description Update mesh dev space info
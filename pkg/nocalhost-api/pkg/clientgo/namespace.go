@@ -2,9 +2,11 @@ package clientgo
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -13,6 +15,15 @@ func (c *GoClient) GetNamespace(namespace string) (*corev1.Namespace, error) {
 	return resources, errors.WithStack(err)
 }
 
+// PatchNamespaceAnnotations annotations with nil value will be removed
+func (c *GoClient) PatchNamespaceAnnotations(namespace string, annotations map[string]interface{}) error {
+	patch, _ := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": annotations}})
+	_, err := c.client.CoreV1().Namespaces().Patch(
+		context.TODO(), namespace, types.MergePatchType, patch, metav1.PatchOptions{},
+	)
+	return errors.WithStack(err)
+}
+
 func (c *GoClient) GetClientSet() *kubernetes.Clientset {
 	return c.client
 }
@@ -166,8 +166,10 @@ var (
 		Code:    50126,
 		Message: "The space name already exists, please change the space name",
 	}
-	ErrDeleteServiceAccount = &Errno{Code: 50127, Message: "Delete sa failed."}
-	ErrNsImportFail         = &Errno{Code: 50128, Message: "Namespace import failed"}
+	ErrDeleteServiceAccount  = &Errno{Code: 50127, Message: "Delete sa failed."}
+	ErrNsImportFail          = &Errno{Code: 50128, Message: "Namespace import failed"}
+	ErrFormatDevModeTTLParam = &Errno{Code: 50129, Message: "Incorrect DevMode ttl parameter."}
+	ErrUpdateDevModeTTL      = &Errno{Code: 50130, Message: "Failed to update DevMode ttl."}
 
 	// cluster-user errors for mesh space
 	ErrMeshClusterUserNotFound          = &Errno{Code: 50200, Message: "Base dev space has not found"}
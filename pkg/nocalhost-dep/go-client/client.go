@@ -9,6 +9,7 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 )
@@ -26,6 +27,18 @@ func InitClientSet() *kubernetes.Clientset {
 	return clientset
 }
 
+func InitMetadataClient() metadata.Interface {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		panic(err.Error())
+	}
+	client, err := metadata.NewForConfig(config)
+	if err != nil {
+		panic(err.Error())
+	}
+	return client
+}
+
 func InitCachedRestMapper() *restmapper.DeferredDiscoveryRESTMapper {
 	config, err := rest.InClusterConfig()
 	if err != nil {